@@ -0,0 +1,366 @@
+package schemagen
+
+import "math"
+
+// autoTuneMaxValues is the document size, in values, that auto-tuning keeps
+// explosive schemas under
+const autoTuneMaxValues = 10000
+
+// Analysis describes the size and shape of the documents a schema produces
+type Analysis struct {
+	Depth     int     `json:"depth"`     // nesting levels of the deepest value; a scalar root is 1
+	Schemas   int     `json:"schemas"`   // distinct subschemas
+	Arrays    int     `json:"arrays"`    // subschemas that generate arrays
+	MaxValues float64 `json:"maxValues"` // upper bound on the values in one document, +Inf if recursive
+	Recursive bool    `json:"recursive"` // a subschema contains itself
+}
+
+// Analyze parses a schema and reports how deep and how large the generated
+// documents can get. It is what SetAutoTune uses to pick safe limits.
+func Analyze(schemaJSON []byte) (*Analysis, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+	return analyze(plan), nil
+}
+
+// SetAutoTune controls whether the schema is analyzed before generation to
+// raise the depth limit for deeply nested schemas and cap array sizes for
+// schemas whose documents would explode in size. Adjustments apply to the
+// call generating the schema, not to MaxDepth, and every adjustment is
+// reported as a warning instead of failing.
+func (g *Generator) SetAutoTune(auto bool) *Generator {
+	g.AutoTune = auto
+	return g
+}
+
+// analyzer walks a compiled plan, memoizing shared subschemas
+type analyzer struct {
+	depth    map[*node]int
+	values   map[*node]float64
+	visiting map[*node]bool
+	result   Analysis
+}
+
+func analyze(plan *node) *Analysis {
+	a := &analyzer{
+		depth:    make(map[*node]int),
+		values:   make(map[*node]float64),
+		visiting: make(map[*node]bool),
+	}
+	a.result.Depth, a.result.MaxValues = a.walk(plan)
+	return &a.result
+}
+
+// edge is a subschema of a node, generated times times one level down, or
+// at the same level as the node
+type edge struct {
+	node      *node
+	times     float64
+	sameLevel bool
+	merged    bool // a dependent schema whose properties join the node's
+}
+
+// edges returns the subschemas a node generates values from
+func edges(n *node) []edge {
+	var out []edge
+	for _, prop := range n.properties {
+		out = append(out, edge{node: prop.node, times: 1})
+	}
+	if n.additional != nil {
+		out = append(out, edge{node: n.additional, times: float64(max(2, n.minProperties))}) // 2 additional properties, or enough for minProperties
+	}
+	if n.items != nil {
+		out = append(out, edge{node: n.items, times: float64(n.maxItems)})
+	}
+	for _, item := range n.tuple {
+		out = append(out, edge{node: item, times: 1})
+	}
+	if n.rest != nil {
+		out = append(out, edge{node: n.rest, times: float64(max(0, n.maxItems-len(n.tuple)))})
+	}
+	for _, group := range [][]*node{n.oneOf, n.anyOf, {n.merged}} {
+		for _, c := range group {
+			if c != nil {
+				out = append(out, edge{node: c, sameLevel: true})
+			}
+		}
+	}
+	for _, dep := range n.dependencies {
+		if dep.schema != nil {
+			out = append(out, edge{node: dep.schema, sameLevel: true, merged: true})
+		}
+	}
+	return out
+}
+
+// walk returns the nesting depth and maximum number of values for a node
+func (a *analyzer) walk(n *node) (int, float64) {
+	if a.visiting[n] {
+		a.result.Recursive = true
+		return 0, math.Inf(1)
+	}
+	if depth, ok := a.depth[n]; ok {
+		return depth, a.values[n]
+	}
+	a.visiting[n] = true
+	defer delete(a.visiting, n)
+
+	a.result.Schemas++
+	if n.items != nil || n.tuple != nil {
+		a.result.Arrays++
+	}
+
+	// Properties and items are one level down; composition and dependent
+	// schemas stay at the same level
+	depth := 1
+	values := 1.0
+	alternatives := 0.0
+	for _, e := range edges(n) {
+		d, v := a.walk(e.node)
+		switch {
+		case e.merged:
+			depth = max(depth, d)
+			values += v - 1 // merged into this object
+		case e.sameLevel:
+			depth = max(depth, d)
+			alternatives = max(alternatives, v)
+		default:
+			depth = max(depth, 1+d)
+			if e.times > 0 {
+				values += e.times * v
+			}
+		}
+	}
+	values += alternatives
+
+	a.depth[n] = depth
+	a.values[n] = values
+	return depth, values
+}
+
+// boundedValues returns the maximum number of values in a document whose
+// nesting stops at depth levels, which is finite for recursive schemas
+func boundedValues(plan *node, depth int) float64 {
+	type level struct {
+		n     *node
+		depth int
+	}
+	memo := make(map[level]float64)
+	var walk func(n *node, depth int) float64
+	walk = func(n *node, depth int) float64 {
+		if depth <= 0 {
+			return 0
+		}
+		key := level{n, depth}
+		if v, ok := memo[key]; ok {
+			return v
+		}
+		memo[key] = 0 // composition that refers back to itself adds nothing
+
+		values := 1.0
+		alternatives := 0.0
+		for _, e := range edges(n) {
+			switch {
+			case e.merged:
+				values += max(0, walk(e.node, depth)-1)
+			case e.sameLevel:
+				alternatives = max(alternatives, walk(e.node, depth))
+			case e.times > 0:
+				values += e.times * walk(e.node, depth-1)
+			}
+		}
+		values += alternatives
+		memo[key] = values
+		return values
+	}
+	return walk(plan, depth)
+}
+
+// autoTune adjusts the limits of a plan so the schema generates instead of
+// failing. The depth limit it picks applies to calls generating the plan;
+// the Generator's MaxDepth is left as it is.
+func (g *Generator) autoTune(plan *node) {
+	analysis := analyze(plan)
+	if analysis.Recursive {
+		g.autoTuneRecursive(plan, analysis)
+		return
+	}
+
+	if analysis.Depth > g.MaxDepth {
+		g.warnf("schema nests %d levels deep, raised MaxDepth from %d for this schema", analysis.Depth, g.MaxDepth)
+		plan.maxDepth = analysis.Depth
+	}
+
+	if analysis.MaxValues <= autoTuneMaxValues {
+		return
+	}
+	capArrays(arrayNodes(plan), func() bool {
+		analysis = analyze(plan)
+		return analysis.MaxValues <= autoTuneMaxValues
+	})
+	g.warnf("schema can produce very large documents, capped arrays to at most %.0f values", analysis.MaxValues)
+}
+
+// autoTuneRecursive bounds the documents of a recursive schema, whose size
+// depends on how deep the recursion goes. Arrays on the recursion cycle are
+// capped first, then the depth limit is lowered, but never below the depth
+// the schema needs without recursing. Near the limit the recursion ends:
+// arrays get their fewest items and optional properties that do not fit
+// are left out, see setMinDepths.
+func (g *Generator) autoTuneRecursive(plan *node, analysis *Analysis) {
+	setMinDepths(plan)
+
+	depth := g.MaxDepth
+	values := boundedValues(plan, depth)
+	if values <= autoTuneMaxValues {
+		return
+	}
+
+	arrays := cycleArrays(plan)
+	capArrays(arrays, func() bool {
+		values = boundedValues(plan, depth)
+		return values <= autoTuneMaxValues
+	})
+	for values > autoTuneMaxValues && depth > max(1, analysis.Depth) {
+		depth--
+		values = boundedValues(plan, depth)
+	}
+	if depth < g.MaxDepth {
+		plan.maxDepth = depth
+	}
+	g.warnf("recursive schema can produce very large documents, capped %d arrays on the recursion and depth to %d, at most %.0f values", len(arrays), depth, values)
+}
+
+// unreachableDepth is the minimum depth of nodes that recurse without end
+const unreachableDepth = math.MaxInt32
+
+// setMinDepths records on every node of a plan the fewest levels its values
+// need: required properties, the items of minItems and the shallowest
+// oneOf or anyOf branch. Recursion that is always required never ends and
+// gets unreachableDepth.
+func setMinDepths(plan *node) {
+	nodes := planNodes(plan)
+	for _, n := range nodes {
+		n.minDepth = unreachableDepth
+	}
+	// The depths only go down, so repeating until stable finds the least
+	for changed := true; changed; {
+		changed = false
+		for _, n := range nodes {
+			if depth := minDepthOf(n); depth < n.minDepth {
+				n.minDepth = depth
+				changed = true
+			}
+		}
+	}
+}
+
+// minDepthOf computes the minimum depth of a node from its subschemas
+func minDepthOf(n *node) int {
+	below := 0
+	child := func(c *node) {
+		below = max(below, c.minDepth)
+	}
+	for _, prop := range n.properties {
+		if prop.required {
+			child(prop.node)
+		}
+	}
+	if n.minItems > 0 {
+		if n.items != nil {
+			child(n.items)
+		}
+		for i, item := range n.tuple {
+			if i < n.minItems {
+				child(item)
+			}
+		}
+		if n.rest != nil && n.minItems > len(n.tuple) {
+			child(n.rest)
+		}
+	}
+	depth := min(unreachableDepth, 1+below)
+
+	// Composition stays at the same level
+	if n.merged != nil {
+		depth = max(depth, n.merged.minDepth)
+	}
+	for _, group := range [][]*node{n.oneOf, n.anyOf} {
+		if len(group) == 0 {
+			continue
+		}
+		shallowest := unreachableDepth
+		for _, c := range group {
+			shallowest = min(shallowest, c.minDepth)
+		}
+		depth = max(depth, shallowest)
+	}
+	return depth
+}
+
+// capArrays halves the maximum length of the arrays, keeping their minimum,
+// until done reports the documents are small enough or no array shrinks
+func capArrays(arrays []*node, done func() bool) {
+	for !done() {
+		shrunk := false
+		for _, n := range arrays {
+			if capped := max(n.minItems, n.maxItems/2); capped < n.maxItems {
+				n.maxItems = capped
+				shrunk = true
+			}
+		}
+		if !shrunk {
+			return
+		}
+	}
+}
+
+// arrayNodes returns every node in a plan that generates arrays of a single item schema
+func arrayNodes(plan *node) []*node {
+	var arrays []*node
+	for _, n := range planNodes(plan) {
+		if n.items != nil {
+			arrays = append(arrays, n)
+		}
+	}
+	return arrays
+}
+
+// cycleArrays returns the array nodes whose items lead back to the array
+func cycleArrays(plan *node) []*node {
+	var arrays []*node
+	for _, n := range arrayNodes(plan) {
+		if reaches(n.items, n) {
+			arrays = append(arrays, n)
+		}
+	}
+	return arrays
+}
+
+// reaches reports whether target is reachable from n
+func reaches(n, target *node) bool {
+	seen := make(map[*node]bool)
+	var walk func(n *node) bool
+	walk = func(n *node) bool {
+		if n == target {
+			return true
+		}
+		if seen[n] {
+			return false
+		}
+		seen[n] = true
+		for _, e := range edges(n) {
+			if walk(e.node) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(n)
+}
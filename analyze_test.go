@@ -0,0 +1,202 @@
+package schemagen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		depth     int
+		maxValues float64
+	}{
+		{
+			name:      "scalar",
+			schema:    `{"type": "string"}`,
+			depth:     1,
+			maxValues: 1,
+		},
+		{
+			name: "nested object",
+			schema: `{
+				"type": "object",
+				"properties": {
+					"a": {"type": "object", "properties": {"b": {"type": "integer"}}},
+					"c": {"type": "string"}
+				}
+			}`,
+			depth:     3,
+			maxValues: 4,
+		},
+		{
+			name:      "array of arrays",
+			schema:    `{"type": "array", "maxItems": 10, "items": {"type": "array", "maxItems": 10, "items": {"type": "integer"}}}`,
+			depth:     3,
+			maxValues: 1 + 10*(1+10),
+		},
+		{
+			name:      "composition stays at the same level",
+			schema:    `{"oneOf": [{"type": "string"}, {"type": "object", "properties": {"x": {"type": "string"}}}]}`,
+			depth:     2,
+			maxValues: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := Analyze([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if analysis.Depth != tt.depth {
+				t.Errorf("Expected depth %d, got %d", tt.depth, analysis.Depth)
+			}
+			if analysis.MaxValues != tt.maxValues {
+				t.Errorf("Expected max values %v, got %v", tt.maxValues, analysis.MaxValues)
+			}
+			if analysis.Recursive {
+				t.Error("Expected non-recursive schema")
+			}
+		})
+	}
+}
+
+// Test auto-tuning raises MaxDepth instead of failing
+func TestAutoTuneDepth(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"a": {"type": "object", "properties": {
+				"b": {"type": "object", "properties": {
+					"c": {"type": "string"}
+				}, "required": ["c"]}
+			}, "required": ["b"]}
+		},
+		"required": ["a"]
+	}`
+
+	if _, err := NewGenerator().SetMaxDepth(2).Generate([]byte(schema)); err == nil {
+		t.Fatal("Expected depth error without auto-tuning")
+	}
+
+	gen := NewGenerator().SetSeed(42).SetMaxDepth(2).SetAutoTune(true)
+	res, err := gen.GenerateResult([]byte(schema))
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+	if len(res.Warnings()) != 1 || !strings.Contains(res.Warnings()[0], "MaxDepth") {
+		t.Errorf("Expected a MaxDepth warning, got %v", res.Warnings())
+	}
+
+	// The raised limit does not outlive the call
+	if gen.MaxDepth != 2 {
+		t.Errorf("Expected MaxDepth to stay 2, got %d", gen.MaxDepth)
+	}
+	if _, err := gen.SetAutoTune(false).Generate([]byte(schema)); err == nil {
+		t.Error("Expected depth error after turning auto-tuning off")
+	}
+}
+
+// Test auto-tuning bounds recursive schemas by capping the arrays on the
+// recursion and leaves other arrays alone
+func TestAutoTuneRecursive(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"$ref": "#/$defs/node",
+		"$defs": {"node": {
+			"type": "object",
+			"properties": {
+				"tags": {"type": "array", "maxItems": 20, "items": {"type": "string"}},
+				"children": {"type": "array", "maxItems": 50, "items": {"$ref": "#/$defs/node"}}
+			}
+		}}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSchema() error = %v", err)
+	}
+
+	gen := NewGenerator().SetAutoTune(true)
+	plan, err := gen.prepareSchema(schema, "")
+	if err != nil {
+		t.Fatalf("prepareSchema() error = %v", err)
+	}
+	tags, children := plan.property("tags"), plan.property("children")
+	if tags.maxItems != 20 {
+		t.Errorf("Expected tags off the recursion to keep 20 items, got %d", tags.maxItems)
+	}
+	if children.maxItems >= 50 || children.maxItems == 0 {
+		t.Errorf("Expected children capped below 50 items, got %d", children.maxItems)
+	}
+	bound := boundedValues(plan, gen.MaxDepth)
+	if bound > autoTuneMaxValues {
+		t.Errorf("Expected at most %d values, got %.0f", autoTuneMaxValues, bound)
+	}
+	want := fmt.Sprintf("capped 1 arrays on the recursion and depth to 10, at most %.0f values", bound)
+	if len(gen.warnings) != 1 || !strings.Contains(gen.warnings[0], want) {
+		t.Errorf("Expected warning %q, got %v", want, gen.warnings)
+	}
+	if gen.MaxDepth != 10 || plan.maxDepth != 0 {
+		t.Errorf("Expected the depth limit to stay 10, got MaxDepth %d and plan limit %d", gen.MaxDepth, plan.maxDepth)
+	}
+}
+
+// Test auto-tuning ends recursion at the depth limit instead of failing
+func TestAutoTuneRecursionEnds(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"tree", `{"$defs": {"n": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/n"}}}}}, "$ref": "#/$defs/n"}`},
+		{"linked list", `{"type": "object", "properties": {"next": {"$ref": "#"}}}`},
+		{"oneOf", `{"oneOf": [{"type": "null"}, {"type": "object", "properties": {"next": {"$ref": "#"}}, "required": ["next"]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetGenerateAllFields(true).SetAutoTune(true)
+			for seed := int64(0); seed < 20; seed++ {
+				data, err := gen.SetSeed(seed).GenerateBytes([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("seed %d: GenerateBytes() error = %v", seed, err)
+				}
+				if errs, err := ValidateInstance([]byte(tt.schema), data); err != nil || len(errs) > 0 {
+					t.Fatalf("seed %d: ValidateInstance(%s) = %v, %v", seed, data, errs, err)
+				}
+			}
+		})
+	}
+}
+
+// Test auto-tuning caps arrays in explosive schemas
+func TestAutoTuneArrays(t *testing.T) {
+	schema := `{
+		"type": "array", "minItems": 2, "maxItems": 100,
+		"items": {"type": "array", "maxItems": 100,
+			"items": {"type": "array", "maxItems": 100, "items": {"type": "integer"}}}
+	}`
+
+	gen := NewGenerator().SetSeed(42).SetAutoTune(true)
+	res, err := gen.GenerateResult([]byte(schema))
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+	if len(res.Warnings()) != 1 || !strings.Contains(res.Warnings()[0], "capped arrays") {
+		t.Errorf("Expected an array cap warning, got %v", res.Warnings())
+	}
+
+	outer, _ := res.AsArray()
+	if len(outer) < 2 {
+		t.Errorf("Expected minItems to be kept, got %d items", len(outer))
+	}
+	total := 0
+	for _, mid := range outer {
+		for _, inner := range mid.([]interface{}) {
+			total += len(inner.([]interface{}))
+		}
+	}
+	if total > autoTuneMaxValues {
+		t.Errorf("Expected at most %d integers, got %d", autoTuneMaxValues, total)
+	}
+}
@@ -0,0 +1,107 @@
+package schemagen
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Anonymize replaces the personal data in a real document with generated
+// values and keeps everything else, so production-shaped documents can be
+// shared as test data. Values are replaced where the schema is marked
+// x-pii and at the given paths, JSON Pointers with array indices written as
+// "*", e.g. "/customers/*/email". Replacements come from the schema, or
+// follow the JSON type of the original value where the document goes
+// beyond the schema.
+//
+// Equal values at the same path get the same replacement, so values that
+// link documents, such as a customer id repeated in every order, still
+// link them afterwards. Keys of the result are sorted.
+func (g *Generator) Anonymize(document, schemaJSON []byte, paths ...string) ([]byte, error) {
+	plan, err := g.prepare(schemaJSON, "")
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := decodeDocument(document, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	a := &anonymizer{
+		g:     g,
+		st:    newGenState(context.Background()),
+		paths: make(map[string]bool, len(paths)),
+		fakes: make(map[string]interface{}),
+	}
+	for _, path := range paths {
+		a.paths[path] = true
+	}
+	result, err := a.walk(plan, doc, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocument(result)
+}
+
+// anonymizer walks a document and the plan describing it
+type anonymizer struct {
+	g     *Generator
+	st    *genState
+	paths map[string]bool        // paths to replace, with "*" for array items
+	fakes map[string]interface{} // replacements by schema path and original value
+}
+
+// walk returns v with its personal data replaced. n is the schema
+// describing v, nil when unknown.
+func (a *anonymizer) walk(n *node, v interface{}, path, schemaPath string) (interface{}, error) {
+	n = describing(n, v)
+	if (n != nil && n.schema.PII) || a.paths[schemaPath] {
+		return a.replace(n, v, path, schemaPath)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// In key order, so the same seed gives the same replacements
+		for _, key := range sortedKeys(v) {
+			value, err := a.walk(propertyNode(n, key), v[key], childPath(path, key), childPath(schemaPath, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = value
+		}
+	case []interface{}:
+		for i, item := range v {
+			value, err := a.walk(itemNode(n, i), item, childPath(path, strconv.Itoa(i)), schemaPath+"/*")
+			if err != nil {
+				return nil, err
+			}
+			v[i] = value
+		}
+	}
+	return v, nil
+}
+
+// replace generates the replacement of a value, reusing the one of an
+// equal value at the same schema path
+func (a *anonymizer) replace(n *node, v interface{}, path, schemaPath string) (interface{}, error) {
+	original, err := uniqueKey(v)
+	if err != nil {
+		return nil, err
+	}
+	key := schemaPath + "\x00" + original
+	if fake, ok := a.fakes[key]; ok {
+		return fake, nil
+	}
+
+	if n == nil {
+		if n, err = compile(&Schema{Type: StringOrArray{Single: jsonTypeOf(v)}}); err != nil {
+			return nil, err
+		}
+	}
+	fake, err := a.g.generate(a.st, n, path, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize %s: %w", path, err)
+	}
+	a.fakes[key] = fake
+	return fake, nil
+}
@@ -0,0 +1,96 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAnonymize(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"customer": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"email": {"type": "string", "format": "email", "x-pii": true},
+					"name": {"type": "string", "x-pii": true}
+				}
+			},
+			"orders": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {"customerEmail": {"type": "string", "format": "email", "x-pii": true}, "total": {"type": "number"}}
+				}
+			}
+		}
+	}`)
+	doc := []byte(`{
+		"customer": {"id": 7, "email": "ada@example.com", "name": "Ada Lovelace", "phone": "+44 20 7946 0000"},
+		"orders": [
+			{"customerEmail": "ada@example.com", "total": 12.5},
+			{"customerEmail": "ada@example.com", "total": 3}
+		]
+	}`)
+
+	out, err := NewGenerator().SetSeed(42).Anonymize(doc, schema, "/customer/phone")
+	if err != nil {
+		t.Fatalf("Anonymize() error = %v", err)
+	}
+	if strings.Contains(string(out), "ada@example.com") || strings.Contains(string(out), "Ada Lovelace") || strings.Contains(string(out), "7946") {
+		t.Errorf("Expected personal data to be replaced, got %s", out)
+	}
+
+	var result struct {
+		Customer struct {
+			ID    json.Number `json:"id"`
+			Email string      `json:"email"`
+			Phone string      `json:"phone"`
+		} `json:"customer"`
+		Orders []struct {
+			CustomerEmail string      `json:"customerEmail"`
+			Total         json.Number `json:"total"`
+		} `json:"orders"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("Anonymize() returned invalid JSON: %v", err)
+	}
+	if result.Customer.ID != "7" || result.Orders[0].Total != "12.5" || result.Orders[1].Total != "3" {
+		t.Errorf("Expected other values to be kept, got %s", out)
+	}
+	if !strings.Contains(result.Customer.Email, "@") || result.Customer.Phone == "" {
+		t.Errorf("Expected replacements from the schema and the original type, got %s", out)
+	}
+	if result.Orders[0].CustomerEmail != result.Orders[1].CustomerEmail {
+		t.Errorf("Expected equal values at one path to get one replacement, got %s", out)
+	}
+
+	// The same seed gives the same replacements
+	again, _ := NewGenerator().SetSeed(42).Anonymize(doc, schema, "/customer/phone")
+	if string(again) != string(out) {
+		t.Errorf("Expected %s, got %s", out, again)
+	}
+
+	if _, err := NewGenerator().Anonymize([]byte(`{`), schema); err == nil || !strings.Contains(err.Error(), "failed to parse document") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
+
+// Test x-pii next to a $ref marks the referenced values as personal data
+func TestAnonymizeRef(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {"id": {"$ref": "#/$defs/id", "x-pii": true}, "order": {"$ref": "#/$defs/id"}},
+		"$defs": {"id": {"type": "string"}}
+	}`)
+
+	out, err := NewGenerator().SetSeed(42).Anonymize([]byte(`{"id": "abc", "order": "xyz"}`), schema)
+	if err != nil {
+		t.Fatalf("Anonymize() error = %v", err)
+	}
+	if strings.Contains(string(out), "abc") || !strings.Contains(string(out), `"order":"xyz"`) {
+		t.Errorf("Expected only the referenced PII field to be replaced, got %s", out)
+	}
+}
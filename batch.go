@@ -0,0 +1,94 @@
+package schemagen
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+)
+
+// GenerateN generates n independent documents, the same as n calls to
+// Generate, compiling the schema once. Use GenerateRelatedN for documents
+// that share sequences and pools.
+func (g *Generator) GenerateN(schemaJSON []byte, n int) ([]interface{}, error) {
+	compiled, err := g.Compile(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]interface{}, max(n, 0))
+	for i := range docs {
+		if docs[i], err = compiled.Generate(); err != nil {
+			return nil, fmt.Errorf("failed to generate document %d: %w", i, err)
+		}
+	}
+	return docs, nil
+}
+
+// StreamItem is a document sent by GenerateStream, or the error that
+// stopped it from being generated
+type StreamItem struct {
+	Index int
+	Value interface{}
+	Err   error
+}
+
+// GenerateStream generates n independent documents like GenerateN and sends
+// them on the returned channel as they are made, so large datasets need not
+// be held in memory. A document that fails is sent with its error and the
+// stream goes on with the next one. The channel is closed after the last
+// document or when ctx is done; the generator must not be used until then.
+// Schema errors are returned before anything is generated.
+func (g *Generator) GenerateStream(ctx context.Context, schemaJSON []byte, n int) (<-chan StreamItem, error) {
+	compiled, err := g.Compile(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(chan StreamItem)
+	go func() {
+		defer close(items)
+		for i := 0; i < n; i++ {
+			value, err := compiled.GenerateWithContext(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case items <- StreamItem{Index: i, Value: value, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return items, nil
+}
+
+// GenerateNDJSON generates n independent documents like GenerateN and
+// writes them to w as newline-delimited JSON, one document per line, as
+// they are made. Only one document is held in memory at a time, so millions
+// can be written for load tests. Generation stops at the first error or
+// when ctx is done; the documents before it have been written.
+func (g *Generator) GenerateNDJSON(ctx context.Context, w io.Writer, schemaJSON []byte, n int) error {
+	compiled, err := g.Compile(schemaJSON)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	for i := 0; i < n; i++ {
+		doc, err := compiled.GenerateWithContext(ctx)
+		if err != nil {
+			out.Flush()
+			return fmt.Errorf("failed to generate document %d: %w", i, err)
+		}
+		line, err := g.marshal(doc)
+		if err != nil {
+			out.Flush()
+			return fmt.Errorf("failed to encode document %d: %w", i, err)
+		}
+		out.Write(line)
+		if err := out.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,103 @@
+package schemagen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Test GenerateN gives the documents of repeated Generate calls
+func TestGenerateN(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}, "required": ["id", "name"]}`)
+
+	docs, err := NewGenerator().SetSeed(42).GenerateN(schema, 5)
+	if err != nil {
+		t.Fatalf("GenerateN() error = %v", err)
+	}
+	if len(docs) != 5 {
+		t.Fatalf("expected 5 documents, got %d", len(docs))
+	}
+	gen := NewGenerator().SetSeed(42)
+	for i, doc := range docs {
+		want, _ := gen.Generate(schema)
+		if fmt.Sprint(doc) != fmt.Sprint(want) {
+			t.Errorf("document %d = %v, want %v", i, doc, want)
+		}
+	}
+
+	if _, err := NewGenerator().GenerateN([]byte(`{"type": "string", "minLength": 3, "maxLength": 1}`), 5); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+// Test streams report errors per document and stop on cancellation
+func TestGenerateStream(t *testing.T) {
+	// Every other document fails its multipleOf
+	schema := []byte(`{"oneOf": [{"type": "integer", "minimum": 1, "maximum": 4, "multipleOf": 5}, {"type": "string"}]}`)
+
+	items, err := NewGenerator().SetSeed(42).GenerateStream(context.Background(), schema, 20)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	var values, failures int
+	for item := range items {
+		if item.Index != values+failures {
+			t.Errorf("expected index %d, got %d", values+failures, item.Index)
+		}
+		if item.Err != nil {
+			failures++
+		} else {
+			values++
+		}
+	}
+	if values+failures != 20 || values == 0 || failures == 0 {
+		t.Errorf("expected 20 documents with values and errors, got %d values and %d errors", values, failures)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	items, err = NewGenerator().SetSeed(42).GenerateStream(ctx, []byte(`{"type": "string"}`), 1000)
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	<-items
+	cancel()
+	count := 1
+	for range items {
+		count++
+	}
+	if count >= 1000 {
+		t.Errorf("expected the stream to stop after cancellation, got %d documents", count)
+	}
+}
+
+// Test NDJSON output has one document per line and stops on errors
+func TestGenerateNDJSON(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`)
+
+	var buf bytes.Buffer
+	if err := NewGenerator().SetSeed(42).GenerateNDJSON(context.Background(), &buf, schema, 100); err != nil {
+		t.Fatalf("GenerateNDJSON() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 100 {
+		t.Fatalf("expected 100 lines, got %d", len(lines))
+	}
+	gen := NewGenerator().SetSeed(42)
+	for i, line := range lines {
+		want, _ := gen.GenerateBytes(schema)
+		if line != string(want) {
+			t.Errorf("line %d = %s, want %s", i, line, want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf.Reset()
+	err := NewGenerator().GenerateNDJSON(ctx, &buf, schema, 100)
+	if !errors.Is(err, context.Canceled) || buf.Len() != 0 {
+		t.Errorf("expected cancellation before any output, got %v and %q", err, buf.String())
+	}
+}
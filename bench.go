@@ -0,0 +1,189 @@
+package schemagen
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// BenchSchema is a named schema for measuring generation
+type BenchSchema struct {
+	Name   string
+	Schema []byte
+}
+
+// BenchSchemas returns a corpus of representative schemas: flat and nested
+// objects, large arrays, composition, patterns and formats, recursion and
+// dictionaries. Projects can measure it next to their own schemas to tell
+// schemagen regressions from changes in their schemas.
+func BenchSchemas() []BenchSchema {
+	return []BenchSchema{
+		{"flat", []byte(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "minimum": 1},
+				"name": {"type": "string", "minLength": 3, "maxLength": 40},
+				"price": {"type": "number", "minimum": 0, "maximum": 1000, "multipleOf": 0.01},
+				"active": {"type": "boolean"},
+				"status": {"enum": ["draft", "published", "archived"]}
+			},
+			"required": ["id", "name", "price", "active", "status"]
+		}`)},
+		{"nested", []byte(`{
+			"type": "object",
+			"properties": {
+				"customer": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"address": {
+							"type": "object",
+							"properties": {
+								"street": {"type": "string"},
+								"city": {"type": "string"},
+								"geo": {
+									"type": "object",
+									"properties": {"lat": {"type": "number", "minimum": -90, "maximum": 90}, "lng": {"type": "number", "minimum": -180, "maximum": 180}},
+									"required": ["lat", "lng"]
+								}
+							},
+							"required": ["street", "city", "geo"]
+						}
+					},
+					"required": ["name", "address"]
+				}
+			},
+			"required": ["customer"]
+		}`)},
+		{"array-of-objects", []byte(`{
+			"type": "array",
+			"minItems": 100,
+			"maxItems": 100,
+			"items": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "integer"},
+					"name": {"type": "string"},
+					"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3}
+				},
+				"required": ["id", "name", "tags"]
+			}
+		}`)},
+		{"composition", []byte(`{
+			"type": "object",
+			"properties": {
+				"payment": {
+					"oneOf": [
+						{"type": "object", "properties": {"card": {"type": "string", "pattern": "^[0-9]{16}$"}}, "required": ["card"], "additionalProperties": false},
+						{"type": "object", "properties": {"iban": {"type": "string", "minLength": 15}}, "required": ["iban"], "additionalProperties": false}
+					]
+				},
+				"amount": {"allOf": [{"type": "integer", "minimum": 1}, {"maximum": 500}, {"multipleOf": 5}]},
+				"note": {"anyOf": [{"type": "string", "maxLength": 20}, {"type": "null"}]}
+			},
+			"required": ["payment", "amount", "note"]
+		}`)},
+		{"formats", []byte(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "format": "uuid"},
+				"email": {"type": "string", "format": "email"},
+				"createdAt": {"type": "string", "format": "date-time"},
+				"website": {"type": "string", "format": "uri"},
+				"sku": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]{4}$"},
+				"zip": {"type": "string", "pattern": "^[0-9]{5}(-[0-9]{4})?$"}
+			},
+			"required": ["id", "email", "createdAt", "website", "sku", "zip"]
+		}`)},
+		{"recursive", []byte(`{
+			"$ref": "#/$defs/node",
+			"$defs": {
+				"node": {
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"children": {"type": "array", "items": {"$ref": "#/$defs/node"}, "maxItems": 3}
+					},
+					"required": ["name"]
+				}
+			}
+		}`)},
+		{"dictionary", []byte(`{
+			"type": "object",
+			"additionalProperties": {"type": "integer", "minimum": 0},
+			"propertyNames": {"pattern": "^[a-z]{4,8}$"},
+			"minProperties": 20,
+			"maxProperties": 20
+		}`)},
+	}
+}
+
+// Measurement is the cost of generating a number of documents
+type Measurement struct {
+	Name         string        `json:"name,omitempty"`
+	Documents    int           `json:"documents"`
+	Duration     time.Duration `json:"duration"`
+	NsPerDoc     float64       `json:"nsPerDoc"`
+	AllocsPerDoc float64       `json:"allocsPerDoc"`
+	BytesPerDoc  float64       `json:"bytesPerDoc"` // bytes allocated per document
+}
+
+// MeasureGeneration generates n documents from a schema with Generate and
+// measures the time and allocations per document. The schema is parsed for
+// every document, as in plain Generate calls. A document generated before
+// measuring warms up caches and checks the schema.
+func (g *Generator) MeasureGeneration(schemaJSON []byte, n int) (*Measurement, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("document count must be positive, got %d", n)
+	}
+	if _, err := g.Generate(schemaJSON); err != nil {
+		return nil, err
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if _, err := g.Generate(schemaJSON); err != nil {
+			return nil, fmt.Errorf("failed to generate document %d: %w", i, err)
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return &Measurement{
+		Documents:    n,
+		Duration:     elapsed,
+		NsPerDoc:     float64(elapsed.Nanoseconds()) / float64(n),
+		AllocsPerDoc: float64(after.Mallocs-before.Mallocs) / float64(n),
+		BytesPerDoc:  float64(after.TotalAlloc-before.TotalAlloc) / float64(n),
+	}, nil
+}
+
+// CheckRegression compares a measurement with a baseline and returns an
+// error naming every metric that grew by more than tolerance, e.g. 0.2 for
+// 20%. Allocation counts are stable across runs and make a reliable CI
+// gate; time per document varies with the machine and needs a wider
+// tolerance.
+func (m *Measurement) CheckRegression(baseline *Measurement, tolerance float64) error {
+	var regressions []string
+	check := func(metric string, value, base float64) {
+		if base > 0 && value > base*(1+tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s %.0f exceeds baseline %.0f by %.0f%%", metric, value, base, (value/base-1)*100))
+		}
+	}
+	check("ns/doc", m.NsPerDoc, baseline.NsPerDoc)
+	check("allocs/doc", m.AllocsPerDoc, baseline.AllocsPerDoc)
+	check("bytes/doc", m.BytesPerDoc, baseline.BytesPerDoc)
+
+	if len(regressions) > 0 {
+		name := m.Name
+		if name == "" {
+			name = "generation"
+		}
+		return fmt.Errorf("%s regressed: %s", name, strings.Join(regressions, ", "))
+	}
+	return nil
+}
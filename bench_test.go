@@ -0,0 +1,91 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test every corpus schema generates valid documents
+func TestBenchSchemas(t *testing.T) {
+	gen := NewGenerator().SetSeed(42)
+	for _, bs := range BenchSchemas() {
+		schema, err := ParseSchema(bs.Schema)
+		if err != nil {
+			t.Fatalf("%s: ParseSchema() error = %v", bs.Name, err)
+		}
+		plan, err := compile(schema)
+		if err != nil {
+			t.Fatalf("%s: compile() error = %v", bs.Name, err)
+		}
+		for i := 0; i < 10; i++ {
+			doc, err := gen.Generate(bs.Schema)
+			if err != nil {
+				t.Fatalf("%s: Generate() error = %v", bs.Name, err)
+			}
+			if errs := validateInstance(plan, doc, ""); len(errs) > 0 {
+				t.Fatalf("%s: invalid document %v: %v", bs.Name, doc, errs[0])
+			}
+		}
+	}
+}
+
+func TestMeasureGeneration(t *testing.T) {
+	gen := NewGenerator().SetSeed(42)
+	m, err := gen.MeasureGeneration(BenchSchemas()[0].Schema, 20)
+	if err != nil {
+		t.Fatalf("MeasureGeneration() error = %v", err)
+	}
+	if m.Documents != 20 || m.NsPerDoc <= 0 || m.AllocsPerDoc <= 0 || m.BytesPerDoc <= 0 {
+		t.Errorf("Unexpected measurement %+v", m)
+	}
+
+	if _, err := gen.MeasureGeneration([]byte(`{"type": "string"}`), 0); err == nil {
+		t.Error("Expected error for zero documents")
+	}
+	if _, err := gen.MeasureGeneration([]byte(`{"type": "string", "minLength": 5, "maxLength": 1}`), 1); err == nil {
+		t.Error("Expected error for invalid schema")
+	}
+}
+
+func TestCheckRegression(t *testing.T) {
+	baseline := &Measurement{Name: "flat", NsPerDoc: 1000, AllocsPerDoc: 100, BytesPerDoc: 4000}
+
+	tests := []struct {
+		name    string
+		current Measurement
+		wantErr string
+	}{
+		{"same", Measurement{Name: "flat", NsPerDoc: 1000, AllocsPerDoc: 100, BytesPerDoc: 4000}, ""},
+		{"within tolerance", Measurement{Name: "flat", NsPerDoc: 1090, AllocsPerDoc: 105, BytesPerDoc: 3000}, ""},
+		{"more allocations", Measurement{Name: "flat", NsPerDoc: 900, AllocsPerDoc: 150, BytesPerDoc: 4000}, "flat regressed: allocs/doc 150 exceeds baseline 100 by 50%"},
+		{"slower", Measurement{NsPerDoc: 2000, AllocsPerDoc: 100, BytesPerDoc: 4000}, "generation regressed: ns/doc 2000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.current.CheckRegression(baseline, 0.1)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckRegression() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckRegression() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func BenchmarkBenchSchemas(b *testing.B) {
+	for _, bs := range BenchSchemas() {
+		b.Run(bs.Name, func(b *testing.B) {
+			gen := NewGenerator().SetSeed(1)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := gen.Generate(bs.Schema); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package schemagen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// bqField is a column of a BigQuery table schema
+type bqField struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Mode      string    `json:"mode"`
+	Fields    []bqField `json:"fields"`
+	MaxLength string    `json:"maxLength"` // int64 values are strings in the BigQuery API
+}
+
+// BigQuery types that are written as strings of a fixed shape in row JSON
+var bqPatterns = map[string]string{
+	"NUMERIC":    `^-?[0-9]{1,9}\.[0-9]{1,9}$`,
+	"BIGNUMERIC": `^-?[0-9]{1,9}\.[0-9]{1,9}$`,
+	"DATETIME":   `^20[0-9]{2}-(0[1-9]|1[0-2])-(0[1-9]|1[0-9]|2[0-8])T([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$`,
+	"GEOGRAPHY":  `^POINT\([0-9]{1,2}\.[0-9]{1,4} [0-8]?[0-9]\.[0-9]{1,4}\)$`,
+	"INTERVAL":   `^[0-9]{1,2}-([0-9]|1[01]) [0-9]{1,2} ([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$`,
+}
+
+// BigQuerySchema converts a BigQuery table schema into a JSON Schema for its
+// rows. It accepts the field list printed by `bq show --schema` or an object
+// with a "fields" list, as in the tables API. REQUIRED fields are required,
+// NULLABLE fields are optional and REPEATED fields become arrays.
+func BigQuerySchema(tableSchemaJSON []byte) ([]byte, error) {
+	var fields []bqField
+	if err := json.Unmarshal(tableSchemaJSON, &fields); err != nil {
+		var table struct {
+			Fields []bqField `json:"fields"`
+		}
+		if err := json.Unmarshal(tableSchemaJSON, &table); err != nil {
+			return nil, fmt.Errorf("failed to parse table schema: %w", err)
+		}
+		fields = table.Fields
+	}
+
+	schema, err := bqRecordSchema(fields)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(schema)
+}
+
+// GenerateBigQueryRows generates n rows for a BigQuery table schema as
+// newline-delimited JSON, the format accepted by `bq load`
+func (g *Generator) GenerateBigQueryRows(tableSchemaJSON []byte, n int) ([]byte, error) {
+	schema, err := BigQuerySchema(tableSchemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := g.prepare(schema, "object")
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	st := newGenState(context.Background())
+	for i := 0; i < n; i++ {
+		row, err := g.generateDocument(st, plan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate row %d: %w", i, err)
+		}
+		if err := enc.Encode(row); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// bqRecordSchema converts a list of fields into an object schema
+func bqRecordSchema(fields []bqField) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for _, field := range fields {
+		schema, err := bqFieldSchema(field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		switch strings.ToUpper(field.Mode) {
+		case "REPEATED":
+			schema = map[string]interface{}{"type": "array", "items": schema}
+		case "REQUIRED":
+			required = append(required, field.Name)
+		}
+		properties[field.Name] = schema
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// bqFieldSchema converts the type of one field
+func bqFieldSchema(field bqField) (map[string]interface{}, error) {
+	typeName := strings.ToUpper(field.Type)
+	if pattern, ok := bqPatterns[typeName]; ok {
+		return map[string]interface{}{"type": "string", "pattern": pattern}, nil
+	}
+
+	switch typeName {
+	case "STRING":
+		schema := map[string]interface{}{"type": "string"}
+		if field.MaxLength != "" {
+			var maxLength int
+			if _, err := fmt.Sscan(field.MaxLength, &maxLength); err != nil {
+				return nil, fmt.Errorf("invalid maxLength %q", field.MaxLength)
+			}
+			schema["maxLength"] = maxLength
+		}
+		return schema, nil
+	case "BYTES":
+		return map[string]interface{}{"type": "string", "format": "byte"}, nil
+	case "INTEGER", "INT64":
+		return map[string]interface{}{"type": "integer"}, nil
+	case "FLOAT", "FLOAT64":
+		return map[string]interface{}{"type": "number"}, nil
+	case "BOOLEAN", "BOOL":
+		return map[string]interface{}{"type": "boolean"}, nil
+	case "TIMESTAMP":
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case "DATE":
+		return map[string]interface{}{"type": "string", "format": "date"}, nil
+	case "TIME":
+		return map[string]interface{}{"type": "string", "format": "time"}, nil
+	case "JSON":
+		return map[string]interface{}{"type": "object"}, nil
+	case "RECORD", "STRUCT":
+		return bqRecordSchema(field.Fields)
+	}
+	return nil, fmt.Errorf("unsupported type %q", field.Type)
+}
@@ -0,0 +1,85 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+var bigQueryTableSchema = []byte(`[
+	{"name": "id", "type": "INTEGER", "mode": "REQUIRED"},
+	{"name": "name", "type": "STRING", "maxLength": "8"},
+	{"name": "price", "type": "NUMERIC", "mode": "REQUIRED"},
+	{"name": "created", "type": "TIMESTAMP", "mode": "REQUIRED"},
+	{"name": "local", "type": "DATETIME", "mode": "REQUIRED"},
+	{"name": "tags", "type": "STRING", "mode": "REPEATED"},
+	{"name": "address", "type": "RECORD", "mode": "REQUIRED", "fields": [
+		{"name": "city", "type": "STRING", "mode": "REQUIRED"},
+		{"name": "location", "type": "GEOGRAPHY", "mode": "REQUIRED"}
+	]}
+]`)
+
+func TestBigQuerySchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"field list", bigQueryTableSchema},
+		{"table resource", []byte(`{"fields": ` + string(bigQueryTableSchema) + `}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := BigQuerySchema(tt.input)
+			if err != nil {
+				t.Fatalf("BigQuerySchema() error = %v", err)
+			}
+			schema, err := ParseSchema(data)
+			if err != nil {
+				t.Fatalf("Converted schema does not parse: %v", err)
+			}
+			if len(schema.Required) != 5 {
+				t.Errorf("Expected 5 required fields, got %v", schema.Required)
+			}
+			if tags := schema.Properties["tags"]; !tags.Type.Contains("array") {
+				t.Errorf("Expected REPEATED field to be an array, got %+v", tags)
+			}
+			if name := schema.Properties["name"]; name.MaxLength == nil || *name.MaxLength != 8 {
+				t.Errorf("Expected maxLength 8, got %+v", name)
+			}
+		})
+	}
+
+	if _, err := BigQuerySchema([]byte(`[{"name": "x", "type": "BLOB"}]`)); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+}
+
+func TestGenerateBigQueryRows(t *testing.T) {
+	data, err := NewGenerator().SetSeed(42).SetGenerateAllFields(true).GenerateBigQueryRows(bigQueryTableSchema, 5)
+	if err != nil {
+		t.Fatalf("GenerateBigQueryRows() error = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	if len(lines) != 5 {
+		t.Fatalf("Expected 5 rows, got %d", len(lines))
+	}
+
+	numeric := regexp.MustCompile(bqPatterns["NUMERIC"])
+	geography := regexp.MustCompile(bqPatterns["GEOGRAPHY"])
+	for _, line := range lines {
+		var row map[string]interface{}
+		if err := json.Unmarshal(line, &row); err != nil {
+			t.Fatalf("Invalid row %s: %v", line, err)
+		}
+		if !numeric.MatchString(row["price"].(string)) {
+			t.Errorf("Invalid NUMERIC %v", row["price"])
+		}
+		address := row["address"].(map[string]interface{})
+		if !geography.MatchString(address["location"].(string)) {
+			t.Errorf("Invalid GEOGRAPHY %v", address["location"])
+		}
+	}
+}
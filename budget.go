@@ -0,0 +1,68 @@
+package schemagen
+
+import (
+	"context"
+	"time"
+)
+
+// BudgetReport describes how a time-budgeted generation call went
+type BudgetReport struct {
+	Budget    time.Duration `json:"budget"`
+	Elapsed   time.Duration `json:"elapsed"`
+	Exhausted bool          `json:"exhausted"`          // the budget ran out before generation finished
+	Degraded  []string      `json:"degraded,omitempty"` // JSON Pointers of values generated in minimal mode
+}
+
+// GenerateWithBudget generates random JSON data within a time budget.
+//
+// Once the budget is spent the generator switches to a lenient, minimal mode
+// instead of failing: optional properties and additional properties are no
+// longer generated and arrays stop at minItems. The document returned is still
+// valid against the schema; the report lists the locations that were cut
+// short. Cancelling ctx still aborts generation with an error.
+func (g *Generator) GenerateWithBudget(ctx context.Context, schemaJSON []byte, budget time.Duration) (interface{}, *BudgetReport, error) {
+	start := time.Now()
+	report := &BudgetReport{Budget: budget}
+
+	st := newGenState(ctx)
+	st.deadline = start.Add(budget)
+	st.report = report
+
+	result, err := g.generateRoot(st, schemaJSON, "")
+	report.Elapsed = time.Since(start)
+	if err != nil {
+		return nil, report, err
+	}
+	return result, report, nil
+}
+
+// overBudget reports whether the time budget of the call is spent
+func (st *genState) overBudget() bool {
+	if st.exhausted {
+		return true
+	}
+	if st.deadline.IsZero() || time.Now().Before(st.deadline) {
+		return false
+	}
+
+	st.exhausted = true
+	st.report.Exhausted = true
+	return true
+}
+
+// degrade records that the value at path was generated in minimal mode
+func (st *genState) degrade(path string) {
+	if st.report != nil {
+		st.report.Degraded = append(st.report.Degraded, path)
+	}
+}
+
+// truncateArray reports whether an array being generated should stop at
+// index i because the budget is spent and minItems is already satisfied
+func (st *genState) truncateArray(path string, i, minItems int) bool {
+	if i < minItems || !st.overBudget() {
+		return false
+	}
+	st.degrade(path)
+	return true
+}
@@ -0,0 +1,78 @@
+package schemagen
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+const budgetTestSchema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer"},
+		"note": {"type": "string"},
+		"tags": {
+			"type": "array",
+			"items": {"type": "string"},
+			"minItems": 2,
+			"maxItems": 50
+		}
+	},
+	"required": ["id", "tags"]
+}`
+
+func TestGenerateWithBudgetExhausted(t *testing.T) {
+	gen := NewGenerator().SetSeed(42).SetGenerateAllFields(true)
+	result, report, err := gen.GenerateWithBudget(context.Background(), []byte(budgetTestSchema), 0)
+	if err != nil {
+		t.Fatalf("GenerateWithBudget() error = %v", err)
+	}
+
+	if !report.Exhausted {
+		t.Error("Expected budget to be reported as exhausted")
+	}
+	if len(report.Degraded) == 0 {
+		t.Error("Expected degraded locations to be reported")
+	}
+
+	obj := result.(map[string]interface{})
+	if _, exists := obj["id"]; !exists {
+		t.Error("Required field 'id' is missing")
+	}
+	if _, exists := obj["note"]; exists {
+		t.Error("Optional field 'note' should be skipped once the budget is spent")
+	}
+	if tags := obj["tags"].([]interface{}); len(tags) != 2 {
+		t.Errorf("Expected tags to stop at minItems 2, got %d items", len(tags))
+	}
+}
+
+func TestGenerateWithBudgetNotExhausted(t *testing.T) {
+	gen := NewGenerator().SetSeed(42).SetGenerateAllFields(true)
+	result, report, err := gen.GenerateWithBudget(context.Background(), []byte(budgetTestSchema), time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateWithBudget() error = %v", err)
+	}
+
+	if report.Exhausted || len(report.Degraded) != 0 {
+		t.Errorf("Expected untouched budget, got %+v", report)
+	}
+	if report.Budget != time.Minute {
+		t.Errorf("Expected budget to be reported, got %v", report.Budget)
+	}
+
+	obj := result.(map[string]interface{})
+	if _, exists := obj["note"]; !exists {
+		t.Error("Optional field 'note' is missing")
+	}
+}
+
+func TestGenerateWithBudgetCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	gen := NewGenerator().SetSeed(42)
+	if _, _, err := gen.GenerateWithBudget(ctx, []byte(budgetTestSchema), time.Minute); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+}
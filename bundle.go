@@ -0,0 +1,64 @@
+package schemagen
+
+import (
+	"maps"
+	"slices"
+)
+
+// Bundle is a named set of generator settings, such as a team's house
+// conventions, that can be published as a Go package and applied to many
+// generators with Use
+type Bundle struct {
+	Name      string                    // identifies the bundle, see Bundles
+	Includes  []Bundle                  // applied before the bundle's own settings
+	Overrides []GlobOverride            // applied in order, see SetOverrideGlob
+	Hooks     []Hook                    // added in order, see AddHook
+	Formats   map[string]FormatProvider // see SetFormatProvider
+	Corpora   map[string]string         // corpus text by name, see SetTextCorpus
+	Configure func(g *Generator)        // sets any other option, e.g. g.SetTextMode
+}
+
+// GlobOverride is an override of the values at the paths matching a glob
+type GlobOverride struct {
+	Glob string
+	Fn   OverrideFunc
+}
+
+// Use applies bundles in order. Settings of later bundles replace those of
+// earlier ones, and overrides set by a later bundle win over earlier ones
+// for paths both match. A named bundle already applied to g, directly or
+// through Includes, is skipped, so bundles can share a common base.
+func (g *Generator) Use(bundles ...Bundle) *Generator {
+	for _, b := range bundles {
+		if b.Name != "" && slices.Contains(g.bundles, b.Name) {
+			continue
+		}
+		g.Use(b.Includes...)
+		if b.Name != "" {
+			g.bundles = append(g.bundles, b.Name)
+		}
+
+		for _, format := range slices.Sorted(maps.Keys(b.Formats)) {
+			g.SetFormatProvider(format, b.Formats[format])
+		}
+		for _, name := range slices.Sorted(maps.Keys(b.Corpora)) {
+			g.SetTextCorpus(name, b.Corpora[name])
+		}
+		for _, o := range b.Overrides {
+			g.SetOverrideGlob(o.Glob, o.Fn)
+		}
+		for _, h := range b.Hooks {
+			g.AddHook(h)
+		}
+		if b.Configure != nil {
+			b.Configure(g)
+		}
+	}
+	return g
+}
+
+// Bundles returns the names of the bundles applied with Use, in the order
+// they were applied
+func (g *Generator) Bundles() []string {
+	return slices.Clone(g.bundles)
+}
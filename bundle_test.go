@@ -0,0 +1,67 @@
+package schemagen
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Test bundles apply their settings in order and share included bundles
+func TestUse(t *testing.T) {
+	fixed := func(v interface{}) OverrideFunc {
+		return func(*Schema) (interface{}, error) { return v, nil }
+	}
+	base := Bundle{
+		Name:      "base",
+		Overrides: []GlobOverride{{Glob: "**/id", Fn: fixed("base-id")}, {Glob: "**/tenant", Fn: fixed("acme")}},
+		Formats: map[string]FormatProvider{"sku": FormatProviderFunc(func(FormatRequest) (string, error) {
+			return "SKU-0001", nil
+		})},
+	}
+	orders := Bundle{
+		Name:      "orders",
+		Includes:  []Bundle{base},
+		Overrides: []GlobOverride{{Glob: "/orders/*/id", Fn: fixed("order-id")}},
+		Configure: func(g *Generator) { g.SetGenerateAllFields(true) },
+	}
+	users := Bundle{Name: "users", Includes: []Bundle{base}}
+
+	gen := NewGenerator().SetSeed(42).Use(orders, users)
+	if got := gen.Bundles(); !slices.Equal(got, []string{"base", "orders", "users"}) {
+		t.Errorf("Bundles() = %v, want [base orders users]", got)
+	}
+
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string"},
+			"tenant": {"type": "string"},
+			"orders": {"type": "array", "minItems": 1, "items": {
+				"type": "object",
+				"properties": {"id": {"type": "string"}, "sku": {"type": "string", "format": "sku"}}
+			}}
+		}
+	}`)
+	result, err := gen.GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if result["id"] != "base-id" || result["tenant"] != "acme" {
+		t.Errorf("expected the base overrides, got %v", result)
+	}
+	order := result["orders"].([]interface{})[0].(map[string]interface{})
+	if order["id"] != "order-id" {
+		t.Errorf("expected the later override to win, got %v", order["id"])
+	}
+	if order["sku"] != "SKU-0001" {
+		t.Errorf("expected the bundle's format provider, got %v", order["sku"])
+	}
+
+	// Applying users again does not apply base a second time, so a later
+	// override of the same glob stays in force
+	gen.SetOverrideGlob("**/tenant", fixed("other")).Use(users)
+	result, _ = gen.GenerateMap(schema)
+	if result["tenant"] != "other" || strings.Count(strings.Join(gen.Bundles(), ","), "base") != 1 {
+		t.Errorf("expected applied bundles to be skipped, got tenant %v and bundles %v", result["tenant"], gen.Bundles())
+	}
+}
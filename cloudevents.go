@@ -0,0 +1,82 @@
+package schemagen
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CloudEventsContentType is the Content-Type of a structured-mode CloudEvent
+const CloudEventsContentType = "application/cloudevents+json"
+
+// CloudEvent is a CloudEvents 1.0 envelope in JSON structured mode
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Source          string      `json:"source"`
+	Type            string      `json:"type"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            time.Time   `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	DataSchema      string      `json:"dataschema,omitempty"`
+	Data            interface{} `json:"data"`
+}
+
+// CloudEventTemplate sets the context attributes of generated CloudEvents.
+// ID, Source, Type and Subject may contain {seq}, replaced by the number of
+// the event starting at 1, and {uuid}, replaced by a generated UUID.
+type CloudEventTemplate struct {
+	ID         string    // "{uuid}" if empty
+	Source     string    // "/schemagen" if empty
+	Type       string    // "com.example.generated" if empty
+	Subject    string    // omitted if empty
+	DataSchema string    // omitted if empty
+	Time       time.Time // time of the first event, generated if zero
+}
+
+// GenerateCloudEvents generates n documents like GenerateRelatedN and wraps
+// each in a CloudEvents 1.0 envelope. Event times start at the template time
+// and move forward by up to a minute per event.
+func (g *Generator) GenerateCloudEvents(schemaJSON []byte, tmpl CloudEventTemplate, n int) ([]CloudEvent, error) {
+	docs, err := g.GenerateRelatedN(schemaJSON, n)
+	if err != nil {
+		return nil, err
+	}
+
+	at := tmpl.Time
+	if at.IsZero() {
+		at = g.faker.Date().UTC().Truncate(time.Second)
+	}
+
+	events := make([]CloudEvent, len(docs))
+	for i, doc := range docs {
+		if i > 0 {
+			at = at.Add(time.Duration(1+g.rand.Intn(60)) * time.Second)
+		}
+		events[i] = CloudEvent{
+			SpecVersion:     "1.0",
+			ID:              g.expandTemplate(tmpl.ID, "{uuid}", i),
+			Source:          g.expandTemplate(tmpl.Source, "/schemagen", i),
+			Type:            g.expandTemplate(tmpl.Type, "com.example.generated", i),
+			Subject:         g.expandTemplate(tmpl.Subject, "", i),
+			Time:            at,
+			DataContentType: "application/json",
+			DataSchema:      tmpl.DataSchema,
+			Data:            doc,
+		}
+	}
+	return events, nil
+}
+
+// expandTemplate fills in the {seq} and {uuid} placeholders of an attribute
+// template, using fallback when the template is empty
+func (g *Generator) expandTemplate(template, fallback string, i int) string {
+	if template == "" {
+		template = fallback
+	}
+	template = strings.ReplaceAll(template, "{seq}", strconv.Itoa(i+1))
+	for strings.Contains(template, "{uuid}") {
+		template = strings.Replace(template, "{uuid}", g.faker.UUID(), 1)
+	}
+	return template
+}
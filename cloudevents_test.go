@@ -0,0 +1,76 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestGenerateCloudEvents(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"orderId": {"type": "integer", "x-sequence": true}}, "required": ["orderId"]}`)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	events, err := NewGenerator().SetSeed(42).GenerateCloudEvents(schema, CloudEventTemplate{
+		ID:      "evt-{seq}",
+		Source:  "/orders",
+		Type:    "com.example.order.created",
+		Subject: "order/{seq}",
+		Time:    start,
+	}, 3)
+	if err != nil {
+		t.Fatalf("GenerateCloudEvents() error = %v", err)
+	}
+
+	for i, event := range events {
+		seq := string(rune('1' + i))
+		if event.ID != "evt-"+seq || event.Subject != "order/"+seq {
+			t.Errorf("Event %d: unexpected id %q or subject %q", i, event.ID, event.Subject)
+		}
+		if event.SpecVersion != "1.0" || event.Source != "/orders" || event.Type != "com.example.order.created" {
+			t.Errorf("Event %d: unexpected attributes %+v", i, event)
+		}
+		if i == 0 && !event.Time.Equal(start) {
+			t.Errorf("Expected first event at %v, got %v", start, event.Time)
+		}
+		if i > 0 && !event.Time.After(events[i-1].Time) {
+			t.Errorf("Event %d: time %v does not move forward", i, event.Time)
+		}
+	}
+
+	data, _ := json.Marshal(events[0])
+	var envelope map[string]interface{}
+	json.Unmarshal(data, &envelope)
+	for _, attr := range []string{"specversion", "id", "source", "type", "time", "datacontenttype", "data"} {
+		if _, ok := envelope[attr]; !ok {
+			t.Errorf("Missing attribute %s in %s", attr, data)
+		}
+	}
+	if _, ok := envelope["dataschema"]; ok {
+		t.Errorf("Expected empty dataschema to be omitted: %s", data)
+	}
+}
+
+// Test default attributes are generated
+func TestGenerateCloudEventsDefaults(t *testing.T) {
+	events, err := NewGenerator().SetSeed(42).GenerateCloudEvents([]byte(`{"type": "string"}`), CloudEventTemplate{}, 2)
+	if err != nil {
+		t.Fatalf("GenerateCloudEvents() error = %v", err)
+	}
+	if events[0].ID == "" || events[0].ID == events[1].ID {
+		t.Errorf("Expected unique generated ids, got %q and %q", events[0].ID, events[1].ID)
+	}
+	if events[0].Source == "" || events[0].Type == "" || events[0].Time.IsZero() {
+		t.Errorf("Expected default attributes, got %+v", events[0])
+	}
+}
+
+// Test a negative count gives no events
+func TestGenerateCloudEventsNegative(t *testing.T) {
+	events, err := NewGenerator().SetSeed(42).GenerateCloudEvents([]byte(`{"type": "string"}`), CloudEventTemplate{}, -1)
+	if err != nil {
+		t.Fatalf("GenerateCloudEvents() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no events, got %d", len(events))
+	}
+}
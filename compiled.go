@@ -0,0 +1,44 @@
+package schemagen
+
+import (
+	"context"
+)
+
+// CompiledSchema is a schema parsed, validated and compiled once, so that
+// generating many documents from it skips that work. It generates with the
+// Generator that compiled it, whose settings at that time shape the plan,
+// e.g. the base URI and auto-tuning.
+type CompiledSchema struct {
+	gen      *Generator
+	plan     *node
+	warnings []string // from compiling, e.g. auto-tuning, repeated for every document
+}
+
+// Compile parses, validates and compiles a schema for repeated generation
+func (g *Generator) Compile(schemaJSON []byte) (*CompiledSchema, error) {
+	plan, err := g.prepare(schemaJSON, "")
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledSchema{gen: g, plan: plan, warnings: g.warnings}, nil
+}
+
+// Generate generates a document like Generator.Generate
+func (c *CompiledSchema) Generate() (interface{}, error) {
+	return c.GenerateWithContext(context.Background())
+}
+
+// GenerateWithContext generates a document with context support for
+// cancellation
+func (c *CompiledSchema) GenerateWithContext(ctx context.Context) (interface{}, error) {
+	g := c.gen
+	g.warnings = append([]string(nil), c.warnings...)
+	g.retries = RetryStats{}
+
+	result, err := g.generateDocument(newGenState(ctx), c.plan)
+	if err != nil {
+		return nil, err
+	}
+	g.stampSeed(c.plan, result)
+	return result, nil
+}
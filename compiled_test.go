@@ -0,0 +1,103 @@
+package schemagen
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Test compiled schemas generate the same documents as Generate
+func TestCompiledSchema(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"code": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]{2}$"},
+			"items": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"sku": {"type": "string"}}, "required": ["sku"]}}
+		},
+		"required": ["id", "code", "items"]
+	}`)
+
+	gen := NewGenerator().SetSeed(42)
+	compiled, err := gen.Compile(schema)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	reference := NewGenerator().SetSeed(42)
+	for i := 0; i < 5; i++ {
+		got, err := compiled.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		want, err := reference.Generate(schema)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("document %d differs:\n%v\n%v", i, got, want)
+		}
+	}
+
+	if _, err := gen.Compile([]byte(`{"type": "string", "minLength": 5, "maxLength": 2}`)); err == nil {
+		t.Error("expected Compile() to fail on an invalid schema")
+	}
+}
+
+// Test documents do not share const and enum objects and arrays
+func TestCompiledSchemaCopiesValues(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"meta": {"const": {"tags": ["a"]}},
+			"pair": {"enum": [[1, 2]]}
+		},
+		"required": ["meta", "pair"]
+	}`)
+
+	compiled, err := NewGenerator().SetSeed(42).Compile(schema)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	first, err := compiled.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	doc := first.(map[string]interface{})
+	doc["meta"].(map[string]interface{})["tags"].([]interface{})[0] = "changed"
+	doc["pair"].([]interface{})[0] = 0.0
+
+	second, err := compiled.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got := fmt.Sprint(second); got != "map[meta:map[tags:[a]] pair:[1 2]]" {
+		t.Errorf("Expected the schema values, got %s", got)
+	}
+}
+
+func BenchmarkCompiledArrayOfObjects(b *testing.B) {
+	schema := []byte(`{
+		"type": "array",
+		"minItems": 100,
+		"maxItems": 100,
+		"items": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"name": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3}
+			},
+			"required": ["id", "name", "tags"]
+		}
+	}`)
+
+	compiled, err := NewGenerator().SetSeed(1).Compile(schema)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := compiled.Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
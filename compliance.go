@@ -0,0 +1,249 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ComplianceReport summarizes, per keyword, whether documents generated for
+// JSON-Schema-Test-Suite schemas validate against those schemas
+type ComplianceReport struct {
+	Keywords []KeywordCompliance `json:"keywords"`
+}
+
+// KeywordCompliance is the result for one suite file, e.g. "minLength.json"
+type KeywordCompliance struct {
+	Keyword     string              `json:"keyword"`
+	Schemas     int                 `json:"schemas"`
+	Passed      int                 `json:"passed"`
+	Failed      int                 `json:"failed"`
+	Unsupported int                 `json:"unsupported"` // schemas using keywords schemagen does not understand
+	Failures    []ComplianceFailure `json:"failures,omitempty"`
+}
+
+// ComplianceFailure describes a suite schema that did not pass
+type ComplianceFailure struct {
+	Description string `json:"description"`
+	Reason      string `json:"reason"`
+}
+
+// FullySupported reports whether every schema for the keyword passed
+func (k KeywordCompliance) FullySupported() bool {
+	return k.Schemas > 0 && k.Passed == k.Schemas
+}
+
+// FullySupported returns the keywords whose schemas all passed
+func (r *ComplianceReport) FullySupported() []string {
+	var keywords []string
+	for _, k := range r.Keywords {
+		if k.FullySupported() {
+			keywords = append(keywords, k.Keyword)
+		}
+	}
+	return keywords
+}
+
+// suiteGroup is a test group in the JSON-Schema-Test-Suite format. Only the
+// schema is used by the compliance runner; the tests are used by schemagen's
+// own validator tests.
+type suiteGroup struct {
+	Description string          `json:"description"`
+	Schema      json.RawMessage `json:"schema"`
+	Tests       []suiteTest     `json:"tests"`
+}
+
+type suiteTest struct {
+	Description string      `json:"description"`
+	Data        interface{} `json:"data"`
+	Valid       bool        `json:"valid"`
+}
+
+// Compliance runs the generator against JSON-Schema-Test-Suite fixtures.
+//
+// suite holds the suite's *.json files for one draft (for example the
+// tests/draft2020-12 directory of the official repository). For every schema
+// in the suite, samples documents are generated and validated against it.
+// Schemas using keywords that schemagen does not understand are counted as
+// unsupported rather than generated from.
+func (g *Generator) Compliance(suite fs.FS, samples int) (*ComplianceReport, error) {
+	if samples < 1 {
+		samples = 1
+	}
+
+	files, err := fs.Glob(suite, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	report := &ComplianceReport{}
+	for _, file := range files {
+		data, err := fs.ReadFile(suite, file)
+		if err != nil {
+			return nil, err
+		}
+
+		var groups []suiteGroup
+		if err := json.Unmarshal(data, &groups); err != nil {
+			return nil, fmt.Errorf("failed to parse suite file %s: %w", file, err)
+		}
+
+		result := KeywordCompliance{Keyword: strings.TrimSuffix(path.Base(file), ".json")}
+		for _, group := range groups {
+			result.Schemas++
+			reason, supported := g.checkSuiteSchema(group.Schema, samples)
+			switch {
+			case !supported:
+				result.Unsupported++
+				result.Failures = append(result.Failures, ComplianceFailure{Description: group.Description, Reason: reason})
+			case reason != "":
+				result.Failed++
+				result.Failures = append(result.Failures, ComplianceFailure{Description: group.Description, Reason: reason})
+			default:
+				result.Passed++
+			}
+		}
+		report.Keywords = append(report.Keywords, result)
+	}
+
+	return report, nil
+}
+
+// checkSuiteSchema generates samples documents for a suite schema and
+// validates them. It returns a failure reason (empty on success) and whether
+// the schema only uses supported keywords.
+func (g *Generator) checkSuiteSchema(schemaJSON []byte, samples int) (string, bool) {
+	var raw interface{}
+	if err := json.Unmarshal(schemaJSON, &raw); err != nil {
+		return err.Error(), false
+	}
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return "boolean schemas are not supported", false
+	}
+
+	unknown := make(map[string]bool)
+	collectUnknownKeywords(raw, unknown)
+	if len(unknown) > 0 {
+		keywords := make([]string, 0, len(unknown))
+		for k := range unknown {
+			keywords = append(keywords, k)
+		}
+		sort.Strings(keywords)
+		return "unsupported keywords: " + strings.Join(keywords, ", "), false
+	}
+
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return err.Error(), false
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return err.Error(), true
+	}
+
+	for i := 0; i < samples; i++ {
+		doc, err := g.Generate(schemaJSON)
+		if err != nil {
+			return fmt.Sprintf("generation failed: %v", err), true
+		}
+
+		// Round-trip through JSON so the document is checked as a consumer sees it
+		encoded, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Sprintf("generated document cannot be encoded: %v", err), true
+		}
+		var decoded interface{}
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			return fmt.Sprintf("generated document cannot be decoded: %v", err), true
+		}
+
+		if errs := validateInstance(plan, decoded, ""); len(errs) > 0 {
+			return fmt.Sprintf("generated %s: %v", encoded, errs[0]), true
+		}
+	}
+
+	return "", true
+}
+
+// annotationKeywords are keywords that never affect validation
+var annotationKeywords = map[string]bool{
+	"$schema": true, "$comment": true, "$anchor": true,
+	"readOnly": true, "writeOnly": true,
+}
+
+// knownKeywords returns the keywords understood by Schema, derived from its JSON tags
+func knownKeywords() map[string]bool {
+	known := make(map[string]bool)
+	t := reflect.TypeOf(Schema{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			known[name] = true
+		}
+	}
+	for k := range annotationKeywords {
+		known[k] = true
+	}
+	return known
+}
+
+// Keywords whose values are subschemas, in the shape they hold them
+var (
+	schemaMapKeywords = map[string]bool{
+		"properties": true, "patternProperties": true, "$defs": true,
+		"definitions": true, "dependentSchemas": true, "dependencies": true,
+	}
+	schemaListKeywords = map[string]bool{
+		"allOf": true, "anyOf": true, "oneOf": true, "prefixItems": true, "items": true,
+	}
+	schemaKeywords = map[string]bool{
+		"additionalProperties": true, "additionalItems": true, "not": true,
+		"if": true, "then": true, "else": true, "contains": true,
+		"propertyNames": true, "unevaluatedItems": true, "unevaluatedProperties": true,
+		"items": true,
+	}
+)
+
+// collectUnknownKeywords walks a raw schema and records keywords that Schema
+// does not understand
+func collectUnknownKeywords(raw interface{}, unknown map[string]bool) {
+	known := knownKeywords()
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for k, value := range obj {
+			if !known[k] {
+				unknown[k] = true
+			}
+
+			switch {
+			case schemaMapKeywords[k]:
+				if m, ok := value.(map[string]interface{}); ok {
+					for _, sub := range m {
+						walk(sub)
+					}
+				}
+			case schemaListKeywords[k]:
+				if list, ok := value.([]interface{}); ok {
+					for _, sub := range list {
+						walk(sub)
+					}
+				} else if schemaKeywords[k] {
+					walk(value)
+				}
+			case schemaKeywords[k]:
+				walk(value)
+			}
+		}
+	}
+	walk(raw)
+}
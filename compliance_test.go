@@ -0,0 +1,74 @@
+package schemagen
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCompliance(t *testing.T) {
+	gen := NewGenerator().SetSeed(42)
+	report, err := gen.Compliance(os.DirFS("testdata/suite"), 5)
+	if err != nil {
+		t.Fatalf("Compliance() error = %v", err)
+	}
+
+	byKeyword := make(map[string]KeywordCompliance)
+	for _, k := range report.Keywords {
+		byKeyword[k.Keyword] = k
+	}
+
+	for _, keyword := range []string{"type", "minLength", "maximum", "enum", "properties", "not", "uniqueItems", "ref", "allOf"} {
+		k, ok := byKeyword[keyword]
+		if !ok {
+			t.Errorf("Missing report for %s", keyword)
+			continue
+		}
+		if !k.FullySupported() {
+			t.Errorf("Expected %s to be fully supported, got %+v", keyword, k)
+		}
+	}
+
+	contains := byKeyword["contains"]
+	if contains.Unsupported != 1 || contains.FullySupported() {
+		t.Errorf("Expected contains to be reported as unsupported, got %+v", contains)
+	}
+	if len(contains.Failures) != 1 || !strings.Contains(contains.Failures[0].Reason, "contains") {
+		t.Errorf("Expected failure reason to name the keyword, got %+v", contains.Failures)
+	}
+
+	supported := report.FullySupported()
+	if len(supported) != 9 {
+		t.Errorf("Expected 9 fully supported keywords, got %v", supported)
+	}
+}
+
+func TestComplianceInvalidSuiteFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/broken.json", []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewGenerator().Compliance(os.DirFS(dir), 1); err == nil {
+		t.Error("Expected error for invalid suite file")
+	}
+}
+
+func TestCollectUnknownKeywords(t *testing.T) {
+	raw := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			// property names are not keywords
+			"not": map[string]interface{}{"type": "string", "contains": true},
+		},
+		"enum":  []interface{}{map[string]interface{}{"if": 1}},
+		"allOf": []interface{}{map[string]interface{}{"unevaluatedProperties": true}},
+	}
+
+	unknown := make(map[string]bool)
+	collectUnknownKeywords(raw, unknown)
+
+	if len(unknown) != 2 || !unknown["contains"] || !unknown["unevaluatedProperties"] {
+		t.Errorf("Expected contains and unevaluatedProperties, got %v", unknown)
+	}
+}
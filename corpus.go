@@ -0,0 +1,100 @@
+package schemagen
+
+import (
+	"strings"
+)
+
+// markovChain is a word-level Markov chain of order 2, trained on a text
+// corpus. Tokens keep their punctuation, so generated text is punctuated
+// like the corpus.
+type markovChain struct {
+	next   map[string][]string // successors of each pair of tokens, joined by a space
+	starts [][2]string         // pairs of tokens that begin a sentence
+}
+
+// newMarkovChain trains a chain on a corpus, or returns nil when the corpus
+// has fewer than three words
+func newMarkovChain(corpus string) *markovChain {
+	tokens := strings.Fields(corpus)
+	if len(tokens) < 3 {
+		return nil
+	}
+	m := &markovChain{next: make(map[string][]string)}
+	for i := 0; i+2 < len(tokens); i++ {
+		key := tokens[i] + " " + tokens[i+1]
+		m.next[key] = append(m.next[key], tokens[i+2])
+		if i == 0 || endsSentence(tokens[i-1]) {
+			m.starts = append(m.starts, [2]string{tokens[i], tokens[i+1]})
+		}
+	}
+	return m
+}
+
+// endsSentence reports whether a token ends a sentence
+func endsSentence(token string) bool {
+	return strings.HasSuffix(token, ".") || strings.HasSuffix(token, "!") || strings.HasSuffix(token, "?")
+}
+
+// SetTextCorpus trains a simple Markov model on a text corpus, such as
+// support tickets or product reviews, so that long strings read like it.
+// Strings whose schema names the corpus with x-corpus are generated from
+// it; the corpus named "" is used for the strings SetProseText makes
+// sentences of. An empty corpus removes the name.
+func (g *Generator) SetTextCorpus(name, corpus string) *Generator {
+	chain := newMarkovChain(corpus)
+	if chain == nil {
+		delete(g.corpora, name)
+		return g
+	}
+	if g.corpora == nil {
+		g.corpora = make(map[string]*markovChain)
+	}
+	g.corpora[name] = chain
+	return g
+}
+
+// corpusText generates text from a corpus with a length within the bounds
+// of a node, see proseLengths. Text ends with a sentence when the bounds
+// allow.
+func (g *Generator) corpusText(m *markovChain, n *node) string {
+	lo, hi := proseLengths(n)
+	target := lo + g.rand.Intn(hi-lo+1)
+	if target == 0 {
+		return ""
+	}
+
+	// Walk the chain, starting a new sentence where it ends
+	var prev [2]string
+	var pending []string
+	next := func() string {
+		if len(pending) > 0 {
+			token := pending[0]
+			pending = pending[1:]
+			return token
+		}
+		if successors := m.next[prev[0]+" "+prev[1]]; len(successors) > 0 {
+			return successors[g.rand.Intn(len(successors))]
+		}
+		start := m.starts[g.rand.Intn(len(m.starts))]
+		pending = []string{start[1]}
+		return start[0]
+	}
+
+	var text []rune
+	for len(text) < target || !endsSentence(prev[1]) {
+		token := next()
+		word := []rune(token)
+		if len(text) > 0 {
+			word = append([]rune(" "), word...)
+		}
+		if len(text)+len(word) > hi {
+			if len(text) < lo {
+				text = append(text, word[:hi-len(text)]...)
+			}
+			break
+		}
+		text = append(text, word...)
+		prev = [2]string{prev[1], token}
+	}
+	return string(text)
+}
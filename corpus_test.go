@@ -0,0 +1,62 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// reviews is a small corpus of product reviews
+const reviews = `Great blender, crushes ice in seconds. The lid leaks a little when full.
+Battery life is shorter than advertised. Still, the sound quality is great for the price.
+Arrived late and the box was damaged. The blender itself works fine.
+The sound quality is muddy at high volume. Returned it after a week.`
+
+// Test strings generated from a text corpus use its words within their bounds
+func TestTextCorpus(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"review": {"type": "string", "x-corpus": "reviews", "minLength": 30, "maxLength": 120},
+			"description": {"type": "string"},
+			"title": {"type": "string", "x-corpus": "reviews", "maxLength": 10}
+		},
+		"required": ["review", "description", "title"]
+	}`)
+
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(reviews) {
+		words[w] = true
+	}
+	gen := NewGenerator().SetSeed(42).SetProseText(true).
+		SetTextCorpus("reviews", reviews).
+		SetTextCorpus("", reviews)
+	for i := 0; i < 20; i++ {
+		result, err := gen.GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		for _, tt := range []struct {
+			key    string
+			lo, hi int
+		}{{"review", 30, 120}, {"description", 40, 200}, {"title", 0, 10}} {
+			text := result[tt.key].(string)
+			if n := utf8.RuneCountInString(text); n < tt.lo || n > tt.hi {
+				t.Errorf("%s: length %d outside [%d, %d]: %q", tt.key, n, tt.lo, tt.hi, text)
+			}
+			if tt.key == "title" {
+				continue
+			}
+			for _, w := range strings.Fields(text) {
+				if !words[w] {
+					t.Errorf("%s: word %q is not from the corpus: %q", tt.key, w, text)
+				}
+			}
+		}
+	}
+
+	_, err := NewGenerator().SetSeed(42).Generate([]byte(`{"type": "string", "x-corpus": "tickets"}`))
+	if err == nil || !strings.Contains(err.Error(), `unknown text corpus "tickets"`) {
+		t.Errorf("expected an unknown corpus error, got %v", err)
+	}
+}
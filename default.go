@@ -0,0 +1,46 @@
+package schemagen
+
+import "sync"
+
+var (
+	defaultOnce sync.Once
+	defaultMu   sync.Mutex // a Generator is not safe for concurrent use
+	defaultGen  *Generator
+)
+
+// defaultGenerator runs fn with the package-level generator, creating it on
+// first use. Calls are serialized, so the package-level functions are safe
+// for concurrent use.
+func defaultGenerator(fn func(g *Generator)) {
+	defaultOnce.Do(func() {
+		defaultGen = NewGenerator().SetAutoTune(true)
+	})
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	fn(defaultGen)
+}
+
+// Generate generates random JSON data with a shared default generator. It is
+// meant for one-off use, e.g. in tests; use a Generator for seeds and options.
+func Generate(schemaJSON []byte) (value interface{}, err error) {
+	defaultGenerator(func(g *Generator) { value, err = g.Generate(schemaJSON) })
+	return value, err
+}
+
+// GenerateBytes is like Generate but returns the data as JSON bytes
+func GenerateBytes(schemaJSON []byte) (data []byte, err error) {
+	defaultGenerator(func(g *Generator) { data, err = g.GenerateBytes(schemaJSON) })
+	return data, err
+}
+
+// GenerateMap is like Generate but requires the schema to produce an object
+func GenerateMap(schemaJSON []byte) (obj map[string]interface{}, err error) {
+	defaultGenerator(func(g *Generator) { obj, err = g.GenerateMap(schemaJSON) })
+	return obj, err
+}
+
+// GenerateSlice is like Generate but requires the schema to produce an array
+func GenerateSlice(schemaJSON []byte) (items []interface{}, err error) {
+	defaultGenerator(func(g *Generator) { items, err = g.GenerateSlice(schemaJSON) })
+	return items, err
+}
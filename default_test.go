@@ -0,0 +1,46 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestDefaultGenerator(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"id": {"type": "integer", "minimum": 1}}, "required": ["id"]}`)
+
+	if value, err := Generate(schema); err != nil || value.(map[string]interface{})["id"] == nil {
+		t.Errorf("Generate() = %v, %v", value, err)
+	}
+	if data, err := GenerateBytes(schema); err != nil || !json.Valid(data) {
+		t.Errorf("GenerateBytes() = %s, %v", data, err)
+	}
+	if obj, err := GenerateMap(schema); err != nil || obj["id"] == nil {
+		t.Errorf("GenerateMap() = %v, %v", obj, err)
+	}
+	if items, err := GenerateSlice([]byte(`{"type": "array", "minItems": 1, "items": {"type": "string"}}`)); err != nil || len(items) == 0 {
+		t.Errorf("GenerateSlice() = %v, %v", items, err)
+	}
+	if _, err := GenerateMap([]byte(`{"type": "string"}`)); err == nil {
+		t.Error("Expected error for non-object schema")
+	}
+}
+
+// Test the package-level functions are safe for concurrent use; run with -race
+func TestDefaultGeneratorConcurrent(t *testing.T) {
+	schema := []byte(`{"type": "array", "maxItems": 5, "items": {"type": "string"}}`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := Generate(schema); err != nil {
+					t.Errorf("Generate() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,46 @@
+package schemagen
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CheckDeterminism generates a schema runs times with seed, each run on a
+// fresh fork of g, and reports the values that differed from the first run,
+// grouped like DiffDocuments but including x-volatile values. Differences
+// point at randomness that escapes the seeds, such as map iteration order
+// or a random source of a format provider, override or hook, which would
+// make golden files flaky. Documents with the same values but a different
+// encoding are reported under the constraint "encoding". An empty result
+// means every run was the same.
+func (g *Generator) CheckDeterminism(schemaJSON []byte, seed int64, runs int) ([]DiffGroup, error) {
+	if runs < 2 {
+		return nil, fmt.Errorf("at least 2 runs are needed, got %d", runs)
+	}
+
+	var first []byte
+	var firstDoc interface{}
+	d := &differ{groups: make(map[diffKey]*DiffGroup)}
+	for run := 0; run < runs; run++ {
+		data, err := g.Fork(seed).GenerateBytes(schemaJSON)
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", run, err)
+		}
+		var doc interface{}
+		if err := decodeDocument(data, &doc); err != nil {
+			return nil, fmt.Errorf("run %d: %w", run, err)
+		}
+		if run == 0 {
+			first, firstDoc = data, doc
+			continue
+		}
+
+		switch {
+		case !jsonEqual(firstDoc, doc):
+			d.compare(nil, firstDoc, doc, "", "")
+		case !bytes.Equal(first, data):
+			d.add("", "encoding", "", string(first), string(data))
+		}
+	}
+	return d.sorted(), nil
+}
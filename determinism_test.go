@@ -0,0 +1,47 @@
+package schemagen
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test randomness that escapes the seeds is reported by path
+func TestCheckDeterminism(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"tags": {"type": "array", "minItems": 2, "items": {"type": "string", "format": "uuid"}},
+			"score": {"type": "number", "x-volatile": true},
+			"token": {"type": "integer"}
+		},
+		"required": ["name", "tags", "score", "token"]
+	}`)
+
+	// Seed 0 is a seed like any other
+	for _, seed := range []int64{0, 42} {
+		groups, err := NewGenerator().CheckDeterminism(schema, seed, 5)
+		if err != nil {
+			t.Fatalf("CheckDeterminism() error = %v", err)
+		}
+		if len(groups) != 0 {
+			t.Errorf("expected runs with seed %d to match, got %v", seed, groups)
+		}
+	}
+
+	// An override drawing from the global random source leaks
+	leaky := NewGenerator().SetOverrideGlob("/token", func(*Schema) (interface{}, error) {
+		return rand.Int63(), nil
+	})
+	groups, err := leaky.CheckDeterminism(schema, 42, 5)
+	if err != nil {
+		t.Fatalf("CheckDeterminism() error = %v", err)
+	}
+	if len(groups) != 1 || groups[0].SchemaPath != "/token" || groups[0].Constraint != "value" || len(groups[0].Differences) == 0 {
+		t.Errorf("expected differences at /token only, got %v", groups)
+	}
+
+	if _, err := NewGenerator().CheckDeterminism(schema, 42, 1); err == nil {
+		t.Error("expected an error for a single run")
+	}
+}
@@ -0,0 +1,317 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Difference is a value that differs between two documents
+type Difference struct {
+	Path string          `json:"path"` // JSON Pointer of the value
+	A    json.RawMessage `json:"a"`    // value in the first document, nil when absent
+	B    json.RawMessage `json:"b"`    // value in the second document, nil when absent
+}
+
+// String formats a difference as "/orders/0/total: 10 != 12"
+func (d Difference) String() string {
+	text := func(raw json.RawMessage) string {
+		if raw == nil {
+			return "(absent)"
+		}
+		return string(raw)
+	}
+	return fmt.Sprintf("%s: %s != %s", d.Path, text(d.A), text(d.B))
+}
+
+// DiffGroup holds the differences found at one schema location for one
+// constraint
+type DiffGroup struct {
+	// SchemaPath is the JSON Pointer of the values with array indices
+	// written as "*", e.g. "/orders/*/total", so differences in every item
+	// of an array are grouped together
+	SchemaPath string `json:"schemaPath"`
+
+	// Constraint is what differs: "type" for values of different JSON
+	// types, "value" for different values of the same type, "property" for
+	// properties present in one document only and "items" for array items
+	// present in one document only
+	Constraint string `json:"constraint"`
+
+	Differences []Difference `json:"differences"`
+}
+
+// DiffDocuments compares two JSON documents of a schema and reports their
+// differences grouped by schema location and constraint, ordered by
+// location. Values of schemas marked x-volatile, such as generated ids and
+// timestamps, are not compared, so snapshot tests can tolerate them. An
+// empty result means the documents match.
+func DiffDocuments(a, b, schemaJSON []byte) ([]DiffGroup, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var docA, docB interface{}
+	if err := decodeDocument(a, &docA); err != nil {
+		return nil, fmt.Errorf("failed to parse first document: %w", err)
+	}
+	if err := decodeDocument(b, &docB); err != nil {
+		return nil, fmt.Errorf("failed to parse second document: %w", err)
+	}
+
+	d := &differ{groups: make(map[diffKey]*DiffGroup)}
+	d.compare(plan, docA, docB, "", "")
+	return d.sorted(), nil
+}
+
+// VolatilePlaceholder replaces the values of x-volatile schemas in the
+// output of MaskVolatile
+const VolatilePlaceholder = "<volatile>"
+
+// MaskVolatile replaces the values of schemas marked x-volatile in a JSON
+// document with VolatilePlaceholder, so documents that differ only in ids,
+// timestamps and other intentionally random values encode the same. Use it
+// before writing and before comparing golden files. Keys of the result are
+// sorted.
+func MaskVolatile(document, schemaJSON []byte) ([]byte, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := decodeDocument(document, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	return encodeDocument(maskVolatile(plan, doc))
+}
+
+// maskVolatile masks the volatile values in v, described by n
+func maskVolatile(n *node, v interface{}) interface{} {
+	n = describing(n, v)
+	if n != nil && n.schema.Volatile {
+		return VolatilePlaceholder
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = maskVolatile(propertyNode(n, key), item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskVolatile(itemNode(n, i), item)
+		}
+	}
+	return v
+}
+
+// decodeDocument decodes a JSON document, keeping numbers exact
+func decodeDocument(data []byte, v *interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// encodeDocument encodes a JSON document with sorted keys and without
+// escaping HTML characters
+func encodeDocument(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+type diffKey struct {
+	schemaPath string
+	constraint string
+}
+
+// differ walks two documents and the plan describing them
+type differ struct {
+	groups map[diffKey]*DiffGroup
+}
+
+// sorted returns the groups ordered by location, then constraint
+func (d *differ) sorted() []DiffGroup {
+	groups := make([]DiffGroup, 0, len(d.groups))
+	for _, group := range d.groups {
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].SchemaPath != groups[j].SchemaPath {
+			return groups[i].SchemaPath < groups[j].SchemaPath
+		}
+		return groups[i].Constraint < groups[j].Constraint
+	})
+	return groups
+}
+
+// absent marks a value missing from one of the documents
+type absent struct{}
+
+func (d *differ) add(schemaPath, constraint, path string, a, b interface{}) {
+	key := diffKey{schemaPath, constraint}
+	group, ok := d.groups[key]
+	if !ok {
+		group = &DiffGroup{SchemaPath: schemaPath, Constraint: constraint}
+		d.groups[key] = group
+	}
+	encode := func(v interface{}) json.RawMessage {
+		if _, missing := v.(absent); missing {
+			return nil
+		}
+		data, _ := json.Marshal(v)
+		return data
+	}
+	group.Differences = append(group.Differences, Difference{Path: path, A: encode(a), B: encode(b)})
+}
+
+// compare compares the values at path in both documents. n is the schema
+// describing them, nil when unknown.
+func (d *differ) compare(n *node, a, b interface{}, path, schemaPath string) {
+	n = describing(n, a)
+	if n != nil && n.schema.Volatile {
+		return
+	}
+
+	switch kind := valueKind(a); {
+	case kind != valueKind(b):
+		d.add(schemaPath, "type", path, a, b)
+	case kind == "object":
+		objA, objB := a.(map[string]interface{}), b.(map[string]interface{})
+		keys := sortedKeys(objA)
+		for key := range objB {
+			if _, ok := objA[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := propertyNode(n, key)
+			if child != nil && child.schema.Volatile {
+				continue
+			}
+			va, inA := objA[key]
+			vb, inB := objB[key]
+			switch {
+			case !inA:
+				d.add(childPath(schemaPath, key), "property", childPath(path, key), absent{}, vb)
+			case !inB:
+				d.add(childPath(schemaPath, key), "property", childPath(path, key), va, absent{})
+			default:
+				d.compare(child, va, vb, childPath(path, key), childPath(schemaPath, key))
+			}
+		}
+	case kind == "array":
+		arrA, arrB := a.([]interface{}), b.([]interface{})
+		for i := 0; i < max(len(arrA), len(arrB)); i++ {
+			child := itemNode(n, i)
+			if child != nil && child.schema.Volatile {
+				continue
+			}
+			itemPath := childPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(arrA):
+				d.add(schemaPath+"/*", "items", itemPath, absent{}, arrB[i])
+			case i >= len(arrB):
+				d.add(schemaPath+"/*", "items", itemPath, arrA[i], absent{})
+			default:
+				d.compare(child, arrA[i], arrB[i], itemPath, schemaPath+"/*")
+			}
+		}
+	case !jsonEqual(a, b):
+		d.add(schemaPath, "value", path, a, b)
+	}
+}
+
+// valueKind is the JSON type of a value, with integers counted as numbers
+func valueKind(v interface{}) string {
+	if kind := jsonTypeOf(v); kind != "integer" {
+		return kind
+	}
+	return "number"
+}
+
+// describing returns the schema that describes a value: the merged allOf
+// schema, or the first oneOf or anyOf alternative the value matches. It
+// stops early at a schema marked x-volatile or x-pii.
+func describing(n *node, v interface{}) *node {
+	for n != nil && !n.schema.Volatile && !n.schema.PII {
+		inner := composed(n, v)
+		if inner == n {
+			return n
+		}
+		n = inner
+	}
+	return n
+}
+
+// matching returns the schema that describes a value like describing, past
+// any x-volatile or x-pii marks
+func matching(n *node, v interface{}) *node {
+	for n != nil {
+		inner := composed(n, v)
+		if inner == n {
+			return n
+		}
+		n = inner
+	}
+	return nil
+}
+
+// composed returns the schema one step inside a composition that describes
+// a value: the merged allOf schema, or the first oneOf or anyOf alternative
+// the value matches, nil when none does. Other schemas are returned as they
+// are.
+func composed(n *node, v interface{}) *node {
+	switch {
+	case n.merged != nil:
+		return n.merged
+	case len(n.oneOf) > 0 || len(n.anyOf) > 0:
+		for _, alt := range append(append([]*node(nil), n.oneOf...), n.anyOf...) {
+			if len(validateInstance(alt, v, "")) == 0 {
+				return alt
+			}
+		}
+		return nil
+	}
+	return n
+}
+
+// propertyNode returns the schema of a property, nil when unknown
+func propertyNode(n *node, name string) *node {
+	if n == nil {
+		return nil
+	}
+	if child := n.property(name); child != nil {
+		return child
+	}
+	return n.additional
+}
+
+// itemNode returns the schema of an array item, nil when unknown
+func itemNode(n *node, i int) *node {
+	switch {
+	case n == nil:
+		return nil
+	case i < len(n.tuple):
+		return n.tuple[i]
+	case n.tuple != nil:
+		return n.rest
+	}
+	return n.items
+}
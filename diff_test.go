@@ -0,0 +1,152 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffDocuments(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "uuid", "x-volatile": true},
+			"name": {"type": "string"},
+			"age": {"type": "integer"},
+			"nickname": {"type": "string"},
+			"orders": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"createdAt": {"type": "string", "format": "date-time", "x-volatile": true},
+						"total": {"type": "number"}
+					}
+				}
+			},
+			"contact": {
+				"oneOf": [
+					{"type": "object", "properties": {"email": {"type": "string"}, "token": {"type": "string", "x-volatile": true}}, "required": ["email"]},
+					{"type": "object", "properties": {"phone": {"type": "string"}}, "required": ["phone"]}
+				]
+			}
+		}
+	}`)
+
+	a := []byte(`{"id": "a1", "name": "Ada", "age": 36, "nickname": "ada",
+		"orders": [{"createdAt": "2024-01-01", "total": 10}, {"createdAt": "2024-01-02", "total": 5}],
+		"contact": {"email": "ada@example.com", "token": "x"}}`)
+	b := []byte(`{"id": "b2", "name": "Ada", "age": "36",
+		"orders": [{"createdAt": "2025-01-01", "total": 12}, {"createdAt": "2025-01-02", "total": 5}, {"total": 1}],
+		"contact": {"email": "ada@example.com", "token": "y"}}`)
+
+	groups, err := DiffDocuments(a, b, schema)
+	if err != nil {
+		t.Fatalf("DiffDocuments() error = %v", err)
+	}
+
+	want := []struct {
+		schemaPath, constraint string
+		differences            []string
+	}{
+		{"/age", "type", []string{`/age: 36 != "36"`}},
+		{"/nickname", "property", []string{`/nickname: "ada" != (absent)`}},
+		{"/orders/*", "items", []string{`/orders/2: (absent) != {"total":1}`}},
+		{"/orders/*/total", "value", []string{`/orders/0/total: 10 != 12`}},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("DiffDocuments() = %+v, want %d groups", groups, len(want))
+	}
+	for i, g := range groups {
+		w := want[i]
+		if g.SchemaPath != w.schemaPath || g.Constraint != w.constraint || len(g.Differences) != len(w.differences) {
+			t.Errorf("group %d = %+v, want %s %s", i, g, w.schemaPath, w.constraint)
+			continue
+		}
+		for j, d := range g.Differences {
+			if d.String() != w.differences[j] {
+				t.Errorf("difference %q, want %q", d.String(), w.differences[j])
+			}
+		}
+	}
+}
+
+// Test equal documents and volatile fields give no differences, and
+// invalid input fails
+func TestDiffDocumentsEqual(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"ts": {"type": "integer", "x-volatile": true}, "n": {"type": "number"}}}`)
+
+	groups, err := DiffDocuments([]byte(`{"ts": 1, "n": 1}`), []byte(`{"n": 1.0, "ts": 2}`), schema)
+	if err != nil || len(groups) != 0 {
+		t.Errorf("DiffDocuments() = %v, %v, want no differences", groups, err)
+	}
+	groups, err = DiffDocuments([]byte(`{"ts": 1}`), []byte(`{}`), schema)
+	if err != nil || len(groups) != 0 {
+		t.Errorf("DiffDocuments() = %v, %v, want volatile property ignored", groups, err)
+	}
+
+	tests := []struct {
+		a, b, schema string
+		wantErr      string
+	}{
+		{`{`, `{}`, `{}`, "failed to parse first document"},
+		{`{}`, `[`, `{}`, "failed to parse second document"},
+		{`{}`, `{}`, `{"type": 1}`, "failed to parse schema"},
+	}
+	for _, tt := range tests {
+		if _, err := DiffDocuments([]byte(tt.a), []byte(tt.b), []byte(tt.schema)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("DiffDocuments(%s, %s, %s) error = %v, want %q", tt.a, tt.b, tt.schema, err, tt.wantErr)
+		}
+	}
+}
+
+// Test volatile values are masked so golden files stay stable
+func TestMaskVolatile(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "x-volatile": true},
+			"events": {"type": "array", "items": {"type": "object", "properties": {"at": {"$ref": "#/$defs/timestamp"}, "kind": {"type": "string"}}}},
+			"meta": {"type": "object", "additionalProperties": {"type": "integer", "x-volatile": true}}
+		},
+		"$defs": {"timestamp": {"type": "string", "format": "date-time", "x-volatile": true}}
+	}`)
+
+	masked, err := MaskVolatile([]byte(`{"id": "a1", "events": [{"at": "2024-01-01T00:00:00Z", "kind": "created"}], "meta": {"took": 12}, "v": 1}`), schema)
+	if err != nil {
+		t.Fatalf("MaskVolatile() error = %v", err)
+	}
+	want := `{"events":[{"at":"<volatile>","kind":"created"}],"id":"<volatile>","meta":{"took":"<volatile>"},"v":1}`
+	if string(masked) != want {
+		t.Errorf("MaskVolatile() = %s, want %s", masked, want)
+	}
+
+	// Generated documents with different seeds agree once masked, apart
+	// from their non-volatile values
+	gen := NewGenerator().SetSeed(1)
+	a, _ := gen.GenerateBytes([]byte(`{"type": "object", "properties": {"id": {"type": "integer", "x-volatile": true}, "kind": {"const": "user"}}, "required": ["id", "kind"]}`))
+	b, _ := gen.SetSeed(2).GenerateBytes([]byte(`{"type": "object", "properties": {"id": {"type": "integer", "x-volatile": true}, "kind": {"const": "user"}}, "required": ["id", "kind"]}`))
+	ma, _ := MaskVolatile(a, []byte(`{"properties": {"id": {"x-volatile": true}}}`))
+	mb, _ := MaskVolatile(b, []byte(`{"properties": {"id": {"x-volatile": true}}}`))
+	if string(ma) != string(mb) {
+		t.Errorf("Expected masked documents to match, got %s and %s", ma, mb)
+	}
+
+	// x-volatile next to a $ref marks the shared definition where it is used
+	refSchema := []byte(`{
+		"type": "object",
+		"properties": {"id": {"$ref": "#/$defs/id", "x-volatile": true}, "owner": {"$ref": "#/$defs/id"}},
+		"$defs": {"id": {"type": "string"}}
+	}`)
+	masked, err = MaskVolatile([]byte(`{"id": "a1", "owner": "b2"}`), refSchema)
+	if want := `{"id":"<volatile>","owner":"b2"}`; err != nil || string(masked) != want {
+		t.Errorf("MaskVolatile() = %s, %v, want %s", masked, err, want)
+	}
+	groups, err := DiffDocuments([]byte(`{"id": "a1", "owner": "b2"}`), []byte(`{"id": "c3", "owner": "b2"}`), refSchema)
+	if err != nil || len(groups) != 0 {
+		t.Errorf("DiffDocuments() = %v, %v, want the referenced volatile id ignored", groups, err)
+	}
+
+	if _, err := MaskVolatile([]byte(`{`), schema); err == nil || !strings.Contains(err.Error(), "failed to parse document") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package schemagen
+
+import "strconv"
+
+// Duplicates configures duplicate-record injection for GenerateRelatedN
+type Duplicates struct {
+	Rate   float64 // share of documents that repeat an earlier document, 0 to 1
+	Mutate bool    // change one value in each duplicate, keeping x-sequence, x-unique and pool values
+}
+
+// SetDuplicates makes GenerateRelatedN re-emit earlier documents of the
+// batch, to simulate duplicate events when testing deduplication
+func (g *Generator) SetDuplicates(d Duplicates) *Generator {
+	g.Duplicates = d
+	return g
+}
+
+// duplicate returns a copy of a random earlier document, or false when the
+// next document should be a new one
+func (g *Generator) duplicate(st *genState, plan *node, originals []interface{}) (interface{}, bool) {
+	if len(originals) == 0 || !g.atRate(st, nil, "duplicates", g.Duplicates.Rate) {
+		return nil, false
+	}
+
+	doc := deepCopy(originals[g.shape.Intn(len(originals))])
+	if g.Duplicates.Mutate {
+		g.mutateLeaf(st, plan, doc)
+	}
+	return doc, true
+}
+
+// leaf is a scalar inside a document together with the schema it was
+// generated from
+type leaf struct {
+	node  *node
+	path  string
+	value interface{}
+	set   func(v interface{})
+}
+
+// collectLeaves finds the scalars of a document whose schema is known,
+// skipping the root and values shared across a call (sequences, unique
+// values, pools)
+func collectLeaves(plan *node, doc interface{}) []leaf {
+	var leaves []leaf
+	walkDocument(plan, doc, "", nil, func(n *node, v interface{}, path string, set func(interface{})) {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return
+		}
+		if set != nil && !n.related() {
+			leaves = append(leaves, leaf{node: n, path: path, value: v, set: set})
+		}
+	})
+	return leaves
+}
+
+// walkDocument calls visit for every value of a document whose schema is
+// known, parents before children. set replaces the value in its parent and
+// is nil for the root. Members are found through the merged allOf schema or
+// the oneOf or anyOf alternative the value matches.
+func walkDocument(n *node, v interface{}, path string, set func(interface{}), visit func(n *node, v interface{}, path string, set func(interface{}))) {
+	visit(n, v, path, set)
+
+	if n = matching(n, v); n == nil {
+		return
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(v) {
+			child := n.property(k)
+			if child == nil {
+				child = n.additional
+			}
+			if child != nil {
+				walkDocument(child, v[k], childPath(path, k), func(x interface{}) { v[k] = x }, visit)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			child := n.items
+			if n.tuple != nil {
+				child = nil
+				if i < len(n.tuple) {
+					child = n.tuple[i]
+				}
+			}
+			if child != nil {
+				walkDocument(child, v[i], childPath(path, strconv.Itoa(i)), func(x interface{}) { v[i] = x }, visit)
+			}
+		}
+	}
+}
+
+// mutateLeaf regenerates one scalar of a document with a different value.
+// Documents without a changeable scalar are left as they are.
+func (g *Generator) mutateLeaf(st *genState, plan *node, doc interface{}) {
+	leaves := collectLeaves(plan, doc)
+	if len(leaves) == 0 {
+		return
+	}
+
+	target := leaves[g.rand.Intn(len(leaves))]
+	value, err := g.retry("duplicate mutation", target.path, func() (interface{}, error) {
+		return g.generate(st, target.node, target.path, 0)
+	}, func(v interface{}) bool {
+		return !jsonEqual(v, target.value)
+	})
+	if err == nil {
+		target.set(value)
+	}
+}
+
+// deepCopy copies a generated document or a value of the schema so it can
+// be changed independently
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			c[k] = deepCopy(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
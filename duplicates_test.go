@@ -0,0 +1,78 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+const duplicateEventSchema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer", "x-sequence": true},
+		"kind": {"type": "string", "enum": ["created", "updated", "deleted"]},
+		"amount": {"type": "number", "minimum": 0, "maximum": 100},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["id", "kind", "amount", "tags"]
+}`
+
+func TestDuplicates(t *testing.T) {
+	allOfSchema := `{"allOf": [` + duplicateEventSchema + `, {"type": "object"}]}`
+	tests := []struct {
+		name   string
+		schema string
+		mutate bool
+	}{
+		{name: "exact copies", schema: duplicateEventSchema, mutate: false},
+		{name: "mutated copies", schema: duplicateEventSchema, mutate: true},
+		{name: "mutated copies under allOf", schema: allOfSchema, mutate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42).SetDuplicates(Duplicates{Rate: 0.2, Mutate: tt.mutate})
+			docs, err := gen.GenerateRelatedN([]byte(tt.schema), 50)
+			if err != nil {
+				t.Fatalf("GenerateRelatedN() error = %v", err)
+			}
+
+			byID := make(map[int64]map[string]interface{})
+			duplicates := 0
+			for i, doc := range docs {
+				obj := doc.(map[string]interface{})
+				id := obj["id"].(int64)
+
+				first, seen := byID[id]
+				if !seen {
+					byID[id] = obj
+					continue
+				}
+				duplicates++
+
+				equal := reflect.DeepEqual(first, obj)
+				if equal == tt.mutate {
+					t.Errorf("Document %d: duplicate of id %d equal=%v, mutate=%v", i, id, equal, tt.mutate)
+				}
+			}
+
+			// The first document can never be a duplicate
+			if duplicates < 9 || duplicates > 10 {
+				t.Errorf("Expected 9-10 duplicates, got %d", duplicates)
+			}
+		})
+	}
+}
+
+// Test duplicates are copies that do not share state with the original
+func TestDuplicatesAreCopies(t *testing.T) {
+	gen := NewGenerator().SetSeed(1).SetDuplicates(Duplicates{Rate: 1})
+	docs, err := gen.GenerateRelatedN([]byte(duplicateEventSchema), 2)
+	if err != nil {
+		t.Fatalf("GenerateRelatedN() error = %v", err)
+	}
+
+	docs[1].(map[string]interface{})["kind"] = "changed"
+	if docs[0].(map[string]interface{})["kind"] == "changed" {
+		t.Error("Expected the duplicate to be an independent copy")
+	}
+}
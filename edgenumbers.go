@@ -0,0 +1,91 @@
+package schemagen
+
+import (
+	"math"
+)
+
+// edgeCaseIntegers are integers that trip up serializers and parsers
+var edgeCaseIntegers = []int64{
+	0, -1, 1,
+	math.MaxInt8, math.MaxUint8, math.MaxUint8 + 1, math.MaxUint16,
+	math.MaxInt32, math.MaxInt32 + 1, math.MinInt32, math.MaxUint32, math.MaxUint32 + 1,
+	maxSafeNumber, maxSafeNumber + 1, -maxSafeNumber, -maxSafeNumber - 1, // the float64 limit and past it
+	math.MaxInt64, math.MinInt64,
+}
+
+// edgeCaseFloats are numbers that trip up serializers and parsers
+var edgeCaseFloats = []float64{
+	math.Copysign(0, -1), 0,
+	math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64, // subnormal
+	2.2250738585072014e-308, 1e-21, // smallest normal, tiny
+	1e-7, 1e21, // where encoders switch to exponent notation
+	0.1, 0.30000000000000004, 1.0 / 3, 2.0 / 3, // long decimal expansions
+	123456789.12345679,
+	maxSafeNumber, -maxSafeNumber, maxSafeNumber + 2,
+	math.MaxFloat64, -math.MaxFloat64,
+}
+
+// SetEdgeCaseNumbers makes half of the numbers edge cases that stay within
+// the schema's bounds and multipleOf, for testing serialization and parsing
+// downstream: -0, subnormal and tiny floats, long decimal expansions such as
+// 0.30000000000000004, integers around 2^53 and the int32 and int64 limits,
+// and the bounds themselves. Open bounds are not filled from the window, so
+// numbers without a maximum may be as large as math.MaxFloat64.
+func (g *Generator) SetEdgeCaseNumbers(edge bool) *Generator {
+	g.EdgeCaseNumbers = edge
+	return g
+}
+
+// edgeCaseNumber picks an edge case allowed by a schema, half of the time
+func (g *Generator) edgeCaseNumber(schema *Schema, isInteger bool) (interface{}, bool) {
+	if g.rand.Intn(2) == 0 {
+		return nil, false
+	}
+
+	var candidates []interface{}
+	if isInteger {
+		lo, hi, ok := schema.integerRange()
+		if !ok {
+			return nil, false
+		}
+		values := append([]int64{lo, hi}, edgeCaseIntegers...)
+		if lo < hi {
+			values = append(values, lo+1, hi-1)
+		}
+		for _, v := range values {
+			if v >= lo && v <= hi && integerMultipleOf(v, schema.MultipleOf) {
+				candidates = append(candidates, v)
+			}
+		}
+	} else {
+		values := edgeCaseFloats
+		for _, bound := range []*float64{schema.Minimum, schema.Maximum} {
+			if bound != nil {
+				values = append(values, *bound, math.Nextafter(*bound, 0))
+			}
+		}
+		for _, v := range values {
+			if len(validateNumber(schema, v, v, "")) == 0 {
+				candidates = append(candidates, v)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	return candidates[g.rand.Intn(len(candidates))], true
+}
+
+// integerMultipleOf reports exactly whether v is a multiple of a whole
+// multipleOf; only 0 is accepted for fractional ones
+func integerMultipleOf(v int64, multipleOf *float64) bool {
+	if multipleOf == nil || *multipleOf <= 0 {
+		return true
+	}
+	m := *multipleOf
+	if m != math.Trunc(m) || m >= math.MaxInt64 {
+		return v == 0
+	}
+	return v%int64(m) == 0
+}
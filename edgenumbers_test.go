@@ -0,0 +1,55 @@
+package schemagen
+
+import (
+	"math"
+	"testing"
+)
+
+// Test edge-case numbers stay within bounds and show up
+func TestEdgeCaseNumbers(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   interface{} // an edge case expected among the values
+	}{
+		{"open integer", `{"type": "integer"}`, int64(math.MaxInt64)},
+		{"bounded integer", `{"type": "integer", "minimum": -10, "maximum": 300, "multipleOf": 2}`, int64(256)},
+		{"exclusive integer", `{"type": "integer", "exclusiveMinimum": 0, "maximum": 9007199254740993}`, int64(9007199254740993)},
+		{"open number", `{"type": "number"}`, math.SmallestNonzeroFloat64},
+		{"unit number", `{"type": "number", "minimum": 0, "maximum": 1}`, 0.30000000000000004},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42).SetEdgeCaseNumbers(true)
+			plan, err := compile(mustParse(t, tt.schema))
+			if err != nil {
+				t.Fatalf("compile() error = %v", err)
+			}
+			found := false
+			for i := 0; i < 500; i++ {
+				v, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if errs := validateInstance(plan, v, ""); len(errs) > 0 {
+					t.Fatalf("%v breaks the schema: %v", v, errs[0])
+				}
+				found = found || v == tt.want
+			}
+			if !found {
+				t.Errorf("expected %v among the values", tt.want)
+			}
+		})
+	}
+}
+
+// mustParse parses a schema for a test
+func mustParse(t *testing.T, schema string) *Schema {
+	t.Helper()
+	s, err := ParseSchema([]byte(schema))
+	if err != nil {
+		t.Fatalf("ParseSchema() error = %v", err)
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+)
+
+// marshalShuffled encodes a generated value as JSON with the keys of every
+// object in an order derived from seed. The same value and seed always give
+// the same bytes.
+func marshalShuffled(v interface{}, seed int64, pretty bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeShuffled(&buf, v, rand.New(rand.NewSource(seed))); err != nil {
+		return nil, err
+	}
+	if !pretty {
+		return buf.Bytes(), nil
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func encodeShuffled(buf *bytes.Buffer, v interface{}, rnd *rand.Rand) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// Sort first so the permutation only depends on the seed
+		keys := sortedKeys(v)
+		rnd.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := encodeShuffled(buf, v[k], rnd); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeShuffled(buf, item, rnd); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test shuffled key order is reproducible from the seed and keeps the document intact
+func TestShuffleKeys(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"a": {"type": "integer"},
+			"b": {"type": "integer"},
+			"c": {"type": "integer"},
+			"d": {"type": "integer"},
+			"e": {"type": "integer"},
+			"f": {"type": "object", "properties": {"x": {"type": "integer"}, "y": {"type": "integer"}, "z": {"type": "integer"}}}
+		}
+	}`)
+
+	gen := func(seed int64) []byte {
+		data, err := NewGenerator().SetSeed(seed).SetGenerateAllFields(true).SetShuffleKeys(true).GenerateBytes(schema)
+		if err != nil {
+			t.Fatalf("GenerateBytes() error = %v", err)
+		}
+		return data
+	}
+
+	first := gen(42)
+	if again := gen(42); string(first) != string(again) {
+		t.Fatalf("Same seed should give identical bytes.\nGot:\n%s\n%s", first, again)
+	}
+
+	// Test the values match the unshuffled encoding
+	plain, err := NewGenerator().SetSeed(42).SetGenerateAllFields(true).GenerateBytes(schema)
+	if err != nil {
+		t.Fatalf("GenerateBytes() error = %v", err)
+	}
+	var shuffled, sorted interface{}
+	if err := json.Unmarshal(first, &shuffled); err != nil {
+		t.Fatalf("Shuffled output is not valid JSON: %v\n%s", err, first)
+	}
+	if err := json.Unmarshal(plain, &sorted); err != nil {
+		t.Fatalf("Plain output is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(shuffled, sorted) {
+		t.Errorf("Shuffling changed the document.\nShuffled: %s\nPlain: %s", first, plain)
+	}
+
+	// Test some seed produces a non-sorted order
+	unsorted := false
+	for seed := int64(0); seed < 10 && !unsorted; seed++ {
+		data := gen(seed)
+		unsorted = strings.Index(string(data), `"a"`) > strings.Index(string(data), `"b"`)
+	}
+	if !unsorted {
+		t.Error("Expected at least one seed to produce keys out of sorted order")
+	}
+}
+
+// Test Result.Bytes honors the shuffle option, including pretty output
+func TestResultBytesShuffleKeys(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"a": {"type": "boolean"}, "b": {"type": "boolean"}, "c": {"type": "boolean"}}}`)
+
+	bytesFor := func() []byte {
+		res, err := NewGenerator().SetSeed(3).SetGenerateAllFields(true).SetShuffleKeys(true).GenerateResult(schema)
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		data, err := res.Bytes(true)
+		if err != nil {
+			t.Fatalf("Bytes() error = %v", err)
+		}
+		return data
+	}
+
+	first := bytesFor()
+	if !strings.Contains(string(first), "\n  ") {
+		t.Errorf("Expected indented output, got %s", first)
+	}
+	if again := bytesFor(); string(first) != string(again) {
+		t.Errorf("Same seed should give identical bytes.\nGot:\n%s\n%s", first, again)
+	}
+}
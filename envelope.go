@@ -0,0 +1,258 @@
+package schemagen
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// EnvelopeFormat selects a REST convention for wrapping resources
+type EnvelopeFormat int
+
+const (
+	// EnvelopeJSONAPI wraps resources as JSON:API data/attributes/relationships
+	EnvelopeJSONAPI EnvelopeFormat = iota
+	// EnvelopeHAL wraps resources with HAL _links and _embedded
+	EnvelopeHAL
+)
+
+// Envelope describes how generated resources are wrapped
+type Envelope struct {
+	Format  EnvelopeFormat
+	Type    string // resource type, e.g. "orders", also used in links
+	BaseURL string // prefix of links, e.g. "https://api.example.com"
+	IDField string // property holding the resource id, "id" by default
+
+	// Relationships maps properties that refer to other resources to the type
+	// of those resources. Values may be ids, objects with an id, or arrays of
+	// either.
+	Relationships map[string]string
+}
+
+// GenerateEnvelope generates n resources like GenerateRelatedN and wraps them
+// in a collection document of the envelope's format. Resource ids come from
+// the id property, or count up from 1 when it is missing, and links are built
+// from the base URL, type and id so they agree across the document.
+func (g *Generator) GenerateEnvelope(schemaJSON []byte, env Envelope, n int) (map[string]interface{}, error) {
+	if env.Type == "" {
+		return nil, fmt.Errorf("envelope type is required")
+	}
+	if env.IDField == "" {
+		env.IDField = "id"
+	}
+
+	docs, err := g.GenerateRelatedN(schemaJSON, n)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]map[string]interface{}, len(docs))
+	ids := make([]string, len(docs))
+	seen := make(map[string]bool)
+	for i, doc := range docs {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected object resource, got %T", doc)
+		}
+		resources[i] = obj
+
+		// Keep ids unique so links never point at two resources
+		id, ok := env.id(obj)
+		if !ok {
+			id = strconv.Itoa(i + 1)
+		}
+		if seen[id] {
+			id = fmt.Sprintf("%s-%d", id, i+1)
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+
+	if env.Format == EnvelopeHAL {
+		return env.hal(resources, ids), nil
+	}
+	return env.jsonAPI(resources, ids), nil
+}
+
+// jsonAPI builds a JSON:API collection document
+func (env Envelope) jsonAPI(resources []map[string]interface{}, ids []string) map[string]interface{} {
+	data := make([]interface{}, len(resources))
+	var included []interface{}
+	seen := make(map[string]bool)
+
+	for i, obj := range resources {
+		attributes := make(map[string]interface{})
+		relationships := make(map[string]interface{})
+		for _, key := range sortedKeys(obj) {
+			value := obj[key]
+			relType, related := env.Relationships[key]
+			switch {
+			case key == env.IDField:
+			case related:
+				relationships[key] = map[string]interface{}{"data": env.linkage(relType, value, func(id string, embedded map[string]interface{}) {
+					if seen[relType+"/"+id] {
+						return
+					}
+					seen[relType+"/"+id] = true
+					included = append(included, env.jsonAPIResource(relType, id, withoutKey(embedded, env.IDField), nil))
+				})}
+			default:
+				attributes[key] = value
+			}
+		}
+		data[i] = env.jsonAPIResource(env.Type, ids[i], attributes, relationships)
+	}
+
+	doc := map[string]interface{}{
+		"data":  data,
+		"links": map[string]interface{}{"self": env.link(env.Type, "")},
+	}
+	if len(included) > 0 {
+		doc["included"] = included
+	}
+	return doc
+}
+
+// jsonAPIResource builds a JSON:API resource object
+func (env Envelope) jsonAPIResource(resourceType, id string, attributes, relationships map[string]interface{}) map[string]interface{} {
+	resource := map[string]interface{}{
+		"type":       resourceType,
+		"id":         id,
+		"attributes": attributes,
+		"links":      map[string]interface{}{"self": env.link(resourceType, id)},
+	}
+	if len(relationships) > 0 {
+		resource["relationships"] = relationships
+	}
+	return resource
+}
+
+// linkage returns the JSON:API resource linkage of a related value, calling
+// embed for every related object that carries more than its id
+func (env Envelope) linkage(relType string, value interface{}, embed func(id string, obj map[string]interface{})) interface{} {
+	if items, ok := value.([]interface{}); ok {
+		linkages := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if l := env.linkage(relType, item, embed); l != nil {
+				linkages = append(linkages, l)
+			}
+		}
+		return linkages
+	}
+
+	id, ok := env.relatedID(value)
+	if !ok {
+		return nil
+	}
+	if obj, isObject := value.(map[string]interface{}); isObject && len(obj) > 1 {
+		embed(id, obj)
+	}
+	return map[string]interface{}{"type": relType, "id": id}
+}
+
+// hal builds a HAL collection document
+func (env Envelope) hal(resources []map[string]interface{}, ids []string) map[string]interface{} {
+	items := make([]interface{}, len(resources))
+	for i, obj := range resources {
+		items[i] = env.halResource(env.Type, ids[i], obj)
+	}
+	return map[string]interface{}{
+		"_links":    map[string]interface{}{"self": map[string]interface{}{"href": env.link(env.Type, "")}},
+		"_embedded": map[string]interface{}{env.Type: items},
+	}
+}
+
+// halResource builds a HAL resource, turning related ids into links and
+// related objects into embedded resources
+func (env Envelope) halResource(resourceType, id string, obj map[string]interface{}) map[string]interface{} {
+	resource := make(map[string]interface{})
+	links := map[string]interface{}{"self": map[string]interface{}{"href": env.link(resourceType, id)}}
+	embedded := make(map[string]interface{})
+
+	for _, key := range sortedKeys(obj) {
+		value := obj[key]
+		relType, related := env.Relationships[key]
+		if !related {
+			resource[key] = value
+			continue
+		}
+
+		var hrefs, objects []interface{}
+		values, isList := value.([]interface{})
+		if !isList {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			relID, ok := env.relatedID(v)
+			if !ok {
+				continue
+			}
+			hrefs = append(hrefs, map[string]interface{}{"href": env.link(relType, relID)})
+			if child, isObject := v.(map[string]interface{}); isObject {
+				objects = append(objects, env.halResource(relType, relID, child))
+			}
+		}
+
+		switch {
+		case isList:
+			links[key] = hrefs
+		case len(hrefs) == 1:
+			links[key] = hrefs[0]
+		}
+		switch {
+		case isList && len(objects) > 0:
+			embedded[key] = objects
+		case len(objects) == 1:
+			embedded[key] = objects[0]
+		}
+	}
+
+	resource["_links"] = links
+	if len(embedded) > 0 {
+		resource["_embedded"] = embedded
+	}
+	return resource
+}
+
+// id returns the id property of a resource
+func (env Envelope) id(obj map[string]interface{}) (string, bool) {
+	value, ok := obj[env.IDField]
+	if !ok || value == nil {
+		return "", false
+	}
+	return scalarText(value), true
+}
+
+// relatedID returns the id of a related value: its id property for objects,
+// or the value itself
+func (env Envelope) relatedID(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case nil, []interface{}:
+		return "", false
+	case map[string]interface{}:
+		return env.id(value)
+	default:
+		return scalarText(value), true
+	}
+}
+
+// link returns the URL of a collection, or of one resource when id is set
+func (env Envelope) link(resourceType, id string) string {
+	href := strings.TrimSuffix(env.BaseURL, "/") + "/" + resourceType
+	if id != "" {
+		href += "/" + url.PathEscape(id)
+	}
+	return href
+}
+
+// withoutKey returns a copy of an object without one key
+func withoutKey(obj map[string]interface{}, key string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		if k != key {
+			copied[k] = v
+		}
+	}
+	return copied
+}
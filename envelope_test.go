@@ -0,0 +1,124 @@
+package schemagen
+
+import (
+	"testing"
+)
+
+var envelopeSchema = []byte(`{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer", "x-sequence": true},
+		"total": {"const": 10},
+		"customer": {"const": {"id": "c1", "name": "Ann"}},
+		"tags": {"const": ["t1", "t2"]}
+	},
+	"required": ["id", "total", "customer", "tags"]
+}`)
+
+func TestGenerateEnvelopeJSONAPI(t *testing.T) {
+	env := Envelope{
+		Format:        EnvelopeJSONAPI,
+		Type:          "orders",
+		BaseURL:       "https://api.example.com/",
+		Relationships: map[string]string{"customer": "customers", "tags": "tags"},
+	}
+	doc, err := NewGenerator().SetSeed(42).GenerateEnvelope(envelopeSchema, env, 2)
+	if err != nil {
+		t.Fatalf("GenerateEnvelope() error = %v", err)
+	}
+
+	data := doc["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("Expected 2 resources, got %d", len(data))
+	}
+	first := data[0].(map[string]interface{})
+	if first["type"] != "orders" || first["id"] != "1" {
+		t.Errorf("Unexpected resource identity %v/%v", first["type"], first["id"])
+	}
+	if self := first["links"].(map[string]interface{})["self"]; self != "https://api.example.com/orders/1" {
+		t.Errorf("Unexpected self link %v", self)
+	}
+	attributes := first["attributes"].(map[string]interface{})
+	if len(attributes) != 1 || attributes["total"] == nil {
+		t.Errorf("Expected only total in attributes, got %v", attributes)
+	}
+
+	relationships := first["relationships"].(map[string]interface{})
+	customer := relationships["customer"].(map[string]interface{})["data"].(map[string]interface{})
+	if customer["type"] != "customers" || customer["id"] != "c1" {
+		t.Errorf("Unexpected customer linkage %v", customer)
+	}
+	if tags := relationships["tags"].(map[string]interface{})["data"].([]interface{}); len(tags) != 2 {
+		t.Errorf("Expected 2 tag linkages, got %v", tags)
+	}
+
+	// The customer is shared by both orders and included once
+	included := doc["included"].([]interface{})
+	if len(included) != 1 {
+		t.Fatalf("Expected 1 included resource, got %v", included)
+	}
+	if attrs := included[0].(map[string]interface{})["attributes"].(map[string]interface{}); attrs["name"] != "Ann" || attrs["id"] != nil {
+		t.Errorf("Unexpected included attributes %v", attrs)
+	}
+}
+
+func TestGenerateEnvelopeHAL(t *testing.T) {
+	env := Envelope{
+		Format:        EnvelopeHAL,
+		Type:          "orders",
+		Relationships: map[string]string{"customer": "customers", "tags": "tags"},
+	}
+	doc, err := NewGenerator().SetSeed(42).GenerateEnvelope(envelopeSchema, env, 2)
+	if err != nil {
+		t.Fatalf("GenerateEnvelope() error = %v", err)
+	}
+
+	if href := doc["_links"].(map[string]interface{})["self"].(map[string]interface{})["href"]; href != "/orders" {
+		t.Errorf("Unexpected collection link %v", href)
+	}
+	orders := doc["_embedded"].(map[string]interface{})["orders"].([]interface{})
+	second := orders[1].(map[string]interface{})
+	links := second["_links"].(map[string]interface{})
+	if href := links["self"].(map[string]interface{})["href"]; href != "/orders/2" {
+		t.Errorf("Unexpected self link %v", href)
+	}
+	if href := links["customer"].(map[string]interface{})["href"]; href != "/customers/c1" {
+		t.Errorf("Unexpected customer link %v", href)
+	}
+	if tags := links["tags"].([]interface{}); len(tags) != 2 {
+		t.Errorf("Expected 2 tag links, got %v", tags)
+	}
+	customer := second["_embedded"].(map[string]interface{})["customer"].(map[string]interface{})
+	if customer["name"] != "Ann" || customer["_links"] == nil {
+		t.Errorf("Unexpected embedded customer %v", customer)
+	}
+	if second["customer"] != nil || second["total"] == nil {
+		t.Errorf("Unexpected resource fields %v", second)
+	}
+}
+
+// Test missing and repeated ids get unique replacements
+func TestGenerateEnvelopeIDs(t *testing.T) {
+	env := Envelope{Type: "things"}
+
+	doc, err := NewGenerator().SetSeed(42).GenerateEnvelope([]byte(`{"type": "object", "properties": {"id": {"const": "x"}}, "required": ["id"]}`), env, 2)
+	if err != nil {
+		t.Fatalf("GenerateEnvelope() error = %v", err)
+	}
+	data := doc["data"].([]interface{})
+	if a, b := data[0].(map[string]interface{})["id"], data[1].(map[string]interface{})["id"]; a != "x" || b != "x-2" {
+		t.Errorf("Expected ids x and x-2, got %v and %v", a, b)
+	}
+
+	doc, err = NewGenerator().SetSeed(42).GenerateEnvelope([]byte(`{"type": "object", "properties": {"n": {"const": 1}}}`), env, 1)
+	if err != nil {
+		t.Fatalf("GenerateEnvelope() error = %v", err)
+	}
+	if id := doc["data"].([]interface{})[0].(map[string]interface{})["id"]; id != "1" {
+		t.Errorf("Expected id 1, got %v", id)
+	}
+
+	if _, err := NewGenerator().GenerateEnvelope(envelopeSchema, Envelope{}, 1); err == nil {
+		t.Error("Expected error without a type")
+	}
+}
@@ -0,0 +1,206 @@
+package schemagen
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ErrorCatalog describes the error responses of an API
+type ErrorCatalog struct {
+	// Schemas maps status codes to the schema of their response body
+	Schemas map[int][]byte
+
+	// RequestSchema is the schema of the request body, optional. Field errors
+	// of 400 and 422 responses then name fields of the request and the
+	// constraints they break.
+	RequestSchema []byte
+}
+
+// ErrorResponse is the representative response of a status code
+type ErrorResponse struct {
+	Status int         `json:"status"`
+	Body   interface{} `json:"body"`
+}
+
+// Property names filled in error bodies, matched case-insensitively
+var (
+	statusKeys     = []string{"status", "statuscode", "status_code", "code"}
+	titleKeys      = []string{"title", "error", "message"}
+	fieldListKeys  = []string{"errors", "fielderrors", "field_errors", "details", "violations", "invalidparams", "invalid_params"}
+	pointerKeys    = []string{"pointer", "path", "jsonpointer"}
+	fieldNameKeys  = []string{"field", "name", "param", "parameter", "property"}
+	fieldMsgKeys   = []string{"message", "detail", "reason", "description"}
+	fieldCodeKeys  = []string{"code", "keyword", "rule"}
+	validationCode = map[int]bool{http.StatusBadRequest: true, http.StatusUnprocessableEntity: true}
+)
+
+// GenerateErrorCatalog generates one representative body per status code,
+// in status order, for client test suites that need a fixed set of error
+// responses. Properties the bodies commonly have are filled to match the
+// status: status or code numbers get the status code, titles and messages
+// its text, e.g. "Not Found", and string codes its name, e.g. "not_found".
+// With a request schema, the items of field error lists of 400 and 422
+// responses describe near misses of the request: their field or pointer, a
+// message and the broken keyword. Values the body schema does not allow are
+// left as generated.
+func (g *Generator) GenerateErrorCatalog(catalog ErrorCatalog) ([]ErrorResponse, error) {
+	statuses := make([]int, 0, len(catalog.Schemas))
+	for status := range catalog.Schemas {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	var fieldErrors []map[string]string
+	if catalog.RequestSchema != nil {
+		var err error
+		if fieldErrors, err = g.requestFieldErrors(catalog.RequestSchema); err != nil {
+			return nil, fmt.Errorf("request schema: %w", err)
+		}
+	}
+
+	responses := make([]ErrorResponse, 0, len(statuses))
+	for _, status := range statuses {
+		plan, err := g.prepare(catalog.Schemas[status], "")
+		if err != nil {
+			return nil, fmt.Errorf("status %d: %w", status, err)
+		}
+		body, err := g.generateDocument(newGenState(context.Background()), plan)
+		if err != nil {
+			return nil, fmt.Errorf("status %d: %w", status, err)
+		}
+
+		fill := errorFiller{plan: plan, root: body}
+		if obj, ok := body.(map[string]interface{}); ok {
+			fill.object(obj, status)
+			if nested, ok := obj["error"].(map[string]interface{}); ok {
+				fill.object(nested, status)
+			}
+			if validationCode[status] && len(fieldErrors) > 0 {
+				fill.fieldErrors(obj, fieldErrors)
+			}
+		}
+		responses = append(responses, ErrorResponse{Status: status, Body: body})
+	}
+	return responses, nil
+}
+
+// requestFieldErrors describes the near misses of a request as field errors
+// with "pointer", "field", "message" and "code" entries
+func (g *Generator) requestFieldErrors(schemaJSON []byte) ([]map[string]string, error) {
+	plan, err := g.prepare(schemaJSON, "")
+	if err != nil {
+		return nil, err
+	}
+	misses, err := g.GenerateNearMisses(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldErrors := make([]map[string]string, 0, len(misses))
+	for _, miss := range misses {
+		errs := validateInstance(plan, miss.Value, "")
+		if len(errs) == 0 {
+			continue
+		}
+		pointer := miss.Path
+		if name, ok := strings.CutPrefix(errs[0].Message, "missing required property "); ok {
+			pointer = childPath(pointer, name)
+		}
+		if pointer == "" {
+			continue
+		}
+		fieldErrors = append(fieldErrors, map[string]string{
+			"pointer": pointer,
+			"field":   pointerField(pointer),
+			"message": errs[0].Message,
+			"code":    miss.Keyword,
+		})
+	}
+	return fieldErrors, nil
+}
+
+// pointerField turns a JSON Pointer into a dotted field name, e.g.
+// "/address/zip" into "address.zip"
+func pointerField(pointer string) string {
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return strings.Join(tokens, ".")
+}
+
+// errorFiller sets well-known properties of a generated error body, keeping
+// only values its schema allows
+type errorFiller struct {
+	plan *node
+	root interface{}
+}
+
+// set replaces obj[key] with value unless the body then breaks its schema
+func (f errorFiller) set(obj map[string]interface{}, key string, value interface{}) {
+	old := obj[key]
+	obj[key] = value
+	if len(validateInstance(f.plan, f.root, "")) > 0 {
+		obj[key] = old
+	}
+}
+
+// object fills the status properties of an object
+func (f errorFiller) object(obj map[string]interface{}, status int) {
+	text := http.StatusText(status)
+	for _, key := range sortedKeys(obj) {
+		name := strings.ToLower(key)
+		switch obj[key].(type) {
+		case int64, float64:
+			if slices.Contains(statusKeys, name) {
+				f.set(obj, key, int64(status))
+			}
+		case string:
+			switch {
+			case text == "":
+			case slices.Contains(titleKeys, name):
+				f.set(obj, key, text)
+			case name == "code":
+				f.set(obj, key, strings.ToLower(strings.ReplaceAll(text, " ", "_")))
+			}
+		}
+	}
+}
+
+// fieldErrors fills the items of field error lists with the field errors
+// of a request, in turn
+func (f errorFiller) fieldErrors(obj map[string]interface{}, fieldErrors []map[string]string) {
+	for _, key := range sortedKeys(obj) {
+		items, ok := obj[key].([]interface{})
+		if !ok || !slices.Contains(fieldListKeys, strings.ToLower(key)) {
+			continue
+		}
+		for i, item := range items {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			fieldError := fieldErrors[i%len(fieldErrors)]
+			for _, prop := range sortedKeys(entry) {
+				if _, isString := entry[prop].(string); !isString {
+					continue
+				}
+				name := strings.ToLower(prop)
+				switch {
+				case slices.Contains(pointerKeys, name):
+					f.set(entry, prop, fieldError["pointer"])
+				case slices.Contains(fieldNameKeys, name):
+					f.set(entry, prop, fieldError["field"])
+				case slices.Contains(fieldMsgKeys, name):
+					f.set(entry, prop, fieldError["message"])
+				case slices.Contains(fieldCodeKeys, name):
+					f.set(entry, prop, fieldError["code"])
+				}
+			}
+		}
+	}
+}
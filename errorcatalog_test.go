@@ -0,0 +1,71 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test error catalogs fill status properties and field errors
+func TestGenerateErrorCatalog(t *testing.T) {
+	problem := []byte(`{
+		"type": "object",
+		"properties": {
+			"status": {"type": "integer"},
+			"title": {"type": "string"},
+			"code": {"type": "string"},
+			"errors": {"type": "array", "minItems": 2, "maxItems": 2, "items": {
+				"type": "object",
+				"properties": {"field": {"type": "string"}, "message": {"type": "string"}, "code": {"type": "string"}},
+				"required": ["field", "message", "code"]
+			}}
+		},
+		"required": ["status", "title", "code", "errors"]
+	}`)
+	conflict := []byte(`{
+		"type": "object",
+		"properties": {"status": {"type": "integer", "maximum": 400}, "title": {"type": "string", "maxLength": 3}},
+		"required": ["status", "title"]
+	}`)
+	request := []byte(`{
+		"type": "object",
+		"properties": {"email": {"type": "string", "minLength": 5, "maxLength": 40}},
+		"required": ["email"]
+	}`)
+
+	responses, err := NewGenerator().SetSeed(42).GenerateErrorCatalog(ErrorCatalog{
+		Schemas:       map[int][]byte{409: conflict, 404: problem, 400: problem},
+		RequestSchema: request,
+	})
+	if err != nil {
+		t.Fatalf("GenerateErrorCatalog() error = %v", err)
+	}
+	if len(responses) != 3 || responses[0].Status != 400 || responses[1].Status != 404 || responses[2].Status != 409 {
+		t.Fatalf("expected responses for 400, 404 and 409 in order, got %v", responses)
+	}
+
+	notFound := responses[1].Body.(map[string]interface{})
+	if notFound["status"] != int64(404) || notFound["title"] != "Not Found" || notFound["code"] != "not_found" {
+		t.Errorf("404 body does not match its status: %v", notFound)
+	}
+
+	badRequest := responses[0].Body.(map[string]interface{})
+	for _, item := range badRequest["errors"].([]interface{}) {
+		fieldError := item.(map[string]interface{})
+		if fieldError["field"] != "email" {
+			t.Errorf("expected a field error for email, got %v", fieldError)
+		}
+		code, _ := fieldError["code"].(string)
+		if code != "minLength" && code != "maxLength" && code != "required" {
+			t.Errorf("expected a broken keyword as code, got %v", fieldError)
+		}
+		if msg, _ := fieldError["message"].(string); !strings.Contains(msg, "email") && !strings.Contains(msg, "length") {
+			t.Errorf("expected a validation message, got %v", fieldError)
+		}
+	}
+
+	// Values the body schema does not allow are left as generated
+	conflictBody := responses[2].Body.(map[string]interface{})
+	if conflictBody["status"] == int64(409) || conflictBody["title"] == "Conflict" {
+		t.Errorf("expected values breaking the schema to be left out, got %v", conflictBody)
+	}
+}
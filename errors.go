@@ -0,0 +1,65 @@
+package schemagen
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMaxDepth is the cause of generation errors for schemas that nest
+// deeper than MaxDepth, see SetMaxDepth
+var ErrMaxDepth = errors.New("maximum recursion depth exceeded")
+
+// GenerationError reports the value a generation call could not produce.
+// Find it with errors.As; the cause is reached with errors.Is, e.g. for
+// ErrMaxDepth or context.Canceled.
+type GenerationError struct {
+	Path    string // JSON Pointer of the value, "" for the document root
+	Keyword string // keyword that could not be satisfied, e.g. "multipleOf", when known
+	Err     error  // the cause
+}
+
+func (e *GenerationError) Error() string {
+	at := e.Path
+	if at == "" {
+		at = "document root"
+	}
+	if e.Keyword != "" {
+		at += " (" + e.Keyword + ")"
+	}
+	return fmt.Sprintf("cannot generate %s: %v", at, e.Err)
+}
+
+// Unwrap returns the cause
+func (e *GenerationError) Unwrap() error {
+	return e.Err
+}
+
+// keywordError is a cause naming the keyword it comes from, which is
+// recorded in the GenerationError
+type keywordError struct {
+	keyword string
+	err     error
+}
+
+func (e *keywordError) Error() string { return e.err.Error() }
+func (e *keywordError) Unwrap() error { return e.err }
+
+// keywordErrorf formats a cause for a keyword
+func keywordErrorf(keyword, format string, args ...interface{}) error {
+	return &keywordError{keyword: keyword, err: fmt.Errorf(format, args...)}
+}
+
+// locateError turns an error from generating the value at path into a
+// GenerationError. Errors located deeper in the document keep their path.
+func locateError(err error, path string) error {
+	var located *GenerationError
+	if errors.As(err, &located) {
+		return err
+	}
+	located = &GenerationError{Path: path, Err: err}
+	var kw *keywordError
+	if errors.As(err, &kw) {
+		located.Keyword = kw.keyword
+	}
+	return located
+}
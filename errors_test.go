@@ -0,0 +1,73 @@
+package schemagen
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Test generation errors carry the path and keyword of the failing value
+func TestGenerationErrorPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		path    string
+		keyword string
+	}{
+		{
+			name: "nested multipleOf",
+			schema: `{"type": "object", "required": ["orders"], "properties": {
+				"orders": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["total"], "properties": {
+					"total": {"type": "number", "minimum": 1.1, "maximum": 1.9, "multipleOf": 1}
+				}}}
+			}}`,
+			path:    "/orders/0/total",
+			keyword: "multipleOf",
+		},
+		{
+			name:    "root minProperties",
+			schema:  `{"type": "object", "minProperties": 2, "additionalProperties": false, "properties": {"a": {"type": "string"}}}`,
+			path:    "",
+			keyword: "minProperties",
+		},
+		{
+			name:    "escaped name",
+			schema:  `{"type": "object", "required": ["a/b"], "properties": {"a/b": {"type": "integer", "minimum": 1, "maximum": 4, "multipleOf": 5}}}`,
+			path:    "/a~1b",
+			keyword: "multipleOf",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator().SetSeed(42).Generate([]byte(tt.schema))
+			var genErr *GenerationError
+			if !errors.As(err, &genErr) {
+				t.Fatalf("expected a GenerationError, got %v", err)
+			}
+			if genErr.Path != tt.path || genErr.Keyword != tt.keyword {
+				t.Errorf("got path %q keyword %q, want %q and %q", genErr.Path, genErr.Keyword, tt.path, tt.keyword)
+			}
+		})
+	}
+}
+
+// Test the causes of generation errors are found with errors.Is
+func TestGenerationErrorCause(t *testing.T) {
+	nested := []byte(`{"type": "object", "required": ["a"], "properties": {"a": {"type": "object", "required": ["b"], "properties": {"b": {"type": "object", "required": ["c"], "properties": {"c": {"type": "string"}}}}}}}`)
+	_, err := NewGenerator().SetSeed(42).SetMaxDepth(2).Generate(nested)
+	if !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("expected ErrMaxDepth, got %v", err)
+	}
+	var genErr *GenerationError
+	if errors.As(err, &genErr) && genErr.Path != "/a/b" {
+		t.Errorf("expected the error at /a/b, got %q", genErr.Path)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewGenerator().SetSeed(42).GenerateWithContext(ctx, nested)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package schemagen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// exampleSeed makes documentation examples identical on every run
+const exampleSeed = 1
+
+// ExampleSet holds documentation examples for a schema
+type ExampleSet struct {
+	Examples []json.RawMessage `json:"examples"` // pretty-printed documents
+
+	// Descriptions holds the description of every described schema, keyed by
+	// the JSON Pointer of the value it describes. Items of arrays with a
+	// single item schema are written as "*", e.g. "/orders/*/id".
+	Descriptions map[string]string `json:"descriptions,omitempty"`
+}
+
+// Examples generates n examples of a schema for documentation. Unlike
+// Generate, the output is deterministic, includes optional properties, uses
+// the schema's examples, example and default values where present, and is
+// pretty-printed. Property descriptions are returned next to the examples so
+// they can be shown as annotations in developer portals.
+func Examples(schemaJSON []byte, n int) (*ExampleSet, error) {
+	g := NewGenerator().SetSeed(exampleSeed).SetGenerateAllFields(true)
+	plan, err := g.prepare(schemaJSON, "")
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ExampleSet{Descriptions: make(map[string]string)}
+	for i := 0; i < n; i++ {
+		st := newGenState(context.Background())
+		st.example = i + 1
+
+		value, err := g.generate(st, plan, "", 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate example %d: %w", i, err)
+		}
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		set.Examples = append(set.Examples, data)
+	}
+
+	collectDescriptions(plan, "", set.Descriptions, make(map[*node]bool))
+	return set, nil
+}
+
+// documentedValue returns a copy of the value a node documents for example
+// number i (counting from 1): its examples in turn, then example, then
+// default. Values that do not match the schema are skipped with a warning.
+func (g *Generator) documentedValue(n *node, i int, path string) (interface{}, bool) {
+	schema := n.schema
+	var candidates []interface{}
+	for j := range schema.Examples {
+		candidates = append(candidates, schema.Examples[(i-1+j)%len(schema.Examples)])
+	}
+	for _, v := range []interface{}{schema.Example, schema.Default} {
+		if v != nil {
+			candidates = append(candidates, v)
+		}
+	}
+
+	for _, value := range candidates {
+		if errs := validateInstance(n, value, path); len(errs) > 0 {
+			g.warnf("skipped documented value: %v", errs[0])
+			continue
+		}
+		return deepCopy(value), true
+	}
+	return nil, false
+}
+
+// SetPreferExamples controls whether a schema's examples, example and
+// default values are used instead of random data where present, so mocks
+// look like the documented payloads. One of them is picked at random for
+// every value; documented values that do not match the schema are skipped
+// with a warning.
+func (g *Generator) SetPreferExamples(prefer bool) *Generator {
+	g.PreferExamples = prefer
+	return g
+}
+
+// preferredValue picks a copy of one of the documented values of a node
+// that match its schema
+func (g *Generator) preferredValue(n *node, path string) (interface{}, bool) {
+	schema := n.schema
+	candidates := append([]interface{}(nil), schema.Examples...)
+	for _, v := range []interface{}{schema.Example, schema.Default} {
+		if v != nil {
+			candidates = append(candidates, v)
+		}
+	}
+
+	for len(candidates) > 0 {
+		i := g.rand.Intn(len(candidates))
+		value := candidates[i]
+		errs := validateInstance(n, value, path)
+		if len(errs) == 0 {
+			return deepCopy(value), true
+		}
+		g.warnf("skipped documented value: %v", errs[0])
+		candidates = append(candidates[:i], candidates[i+1:]...)
+	}
+	return nil, false
+}
+
+// collectDescriptions records the descriptions of a plan by JSON Pointer
+func collectDescriptions(n *node, path string, descriptions map[string]string, visiting map[*node]bool) {
+	if n == nil || visiting[n] {
+		return
+	}
+	visiting[n] = true
+	defer delete(visiting, n)
+
+	if n.schema.Description != "" {
+		if _, exists := descriptions[path]; !exists {
+			descriptions[path] = n.schema.Description
+		}
+	}
+
+	for _, prop := range n.properties {
+		collectDescriptions(prop.node, childPath(path, prop.name), descriptions, visiting)
+	}
+	collectDescriptions(n.items, childPath(path, "*"), descriptions, visiting)
+	for i, item := range n.tuple {
+		collectDescriptions(item, childPath(path, strconv.Itoa(i)), descriptions, visiting)
+	}
+	for _, group := range [][]*node{n.oneOf, n.anyOf, n.allOf} {
+		for _, c := range group {
+			collectDescriptions(c, path, descriptions, visiting)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExamples(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"description": "A customer",
+		"properties": {
+			"name": {"type": "string", "description": "Full name", "examples": ["Ada Lovelace", "Grace Hopper"]},
+			"plan": {"type": "string", "enum": ["free", "pro"], "default": "free"},
+			"email": {"type": "string", "format": "email"},
+			"orders": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {"id": {"type": "string", "format": "uuid", "description": "Order id"}},
+					"required": ["id"]
+				}
+			}
+		}
+	}`)
+
+	set, err := Examples(schema, 3)
+	if err != nil {
+		t.Fatalf("Examples() error = %v", err)
+	}
+	if len(set.Examples) != 3 {
+		t.Fatalf("Expected 3 examples, got %d", len(set.Examples))
+	}
+
+	names := []string{"Ada Lovelace", "Grace Hopper", "Ada Lovelace"}
+	for i, raw := range set.Examples {
+		if !strings.Contains(string(raw), "\n  ") {
+			t.Errorf("Example %d is not pretty-printed: %s", i, raw)
+		}
+
+		var doc map[string]interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			t.Fatalf("Example %d is invalid JSON: %v", i, err)
+		}
+
+		// Test documented values are used, optional properties included
+		if doc["name"] != names[i] {
+			t.Errorf("Example %d: expected name %q, got %v", i, names[i], doc["name"])
+		}
+		if doc["plan"] != "free" {
+			t.Errorf("Example %d: expected default plan, got %v", i, doc["plan"])
+		}
+		if !strings.Contains(doc["email"].(string), "@") {
+			t.Errorf("Example %d: expected an email, got %v", i, doc["email"])
+		}
+	}
+
+	// Test examples are identical on every run
+	again, _ := Examples(schema, 3)
+	for i := range set.Examples {
+		if string(set.Examples[i]) != string(again.Examples[i]) {
+			t.Errorf("Example %d differs between runs", i)
+		}
+	}
+
+	want := map[string]string{"": "A customer", "/name": "Full name", "/orders/*/id": "Order id"}
+	if len(set.Descriptions) != len(want) {
+		t.Errorf("Expected descriptions %v, got %v", want, set.Descriptions)
+	}
+	for path, description := range want {
+		if set.Descriptions[path] != description {
+			t.Errorf("Expected description %q at %q, got %q", description, path, set.Descriptions[path])
+		}
+	}
+}
+
+// Test documented values that break the schema are skipped, and documented
+// values are copied into the documents
+func TestDocumentedValuesChecked(t *testing.T) {
+	set, err := Examples([]byte(`{"type": "object", "properties": {"code": {"type": "string", "maxLength": 3, "examples": ["abc", "stale"]}}, "required": ["code"]}`), 2)
+	if err != nil {
+		t.Fatalf("Examples() error = %v", err)
+	}
+	for i, want := range []string{`"abc"`, `"abc"`} {
+		if !strings.Contains(string(set.Examples[i]), want) {
+			t.Errorf("Example %d: expected %s, got %s", i, want, set.Examples[i])
+		}
+	}
+
+	compiled, err := NewGenerator().SetSeed(42).SetPreferExamples(true).Compile([]byte(`{"type": "object", "properties": {"tags": {"type": "array", "examples": [["a", "b"]]}}, "required": ["tags"]}`))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	first, _ := compiled.Generate()
+	first.(map[string]interface{})["tags"].([]interface{})[0] = "changed"
+	second, _ := compiled.Generate()
+	if tags := second.(map[string]interface{})["tags"].([]interface{}); tags[0] != "a" {
+		t.Errorf("Expected documents not to share the example, got %v", tags)
+	}
+}
+
+// Test documented values replace random ones when preferred, unless they
+// break the schema
+func TestPreferExamples(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "examples": ["Ada Lovelace", "Grace Hopper"], "default": "Anonymous"},
+			"age": {"type": "integer", "minimum": 18, "examples": [12, 30]},
+			"email": {"type": "string", "format": "email"}
+		},
+		"required": ["name", "age", "email"]
+	}`)
+
+	gen := NewGenerator().SetSeed(42).SetPreferExamples(true)
+	names := make(map[interface{}]bool)
+	warned := false
+	for i := 0; i < 30; i++ {
+		res, err := gen.GenerateResult(schema)
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		doc, _ := res.AsObject()
+		names[doc["name"]] = true
+		if doc["age"] != 30.0 {
+			t.Errorf("Expected the only valid example age, got %v", doc["age"])
+		}
+		if !strings.Contains(doc["email"].(string), "@") {
+			t.Errorf("Expected a random email, got %v", doc["email"])
+		}
+		for _, w := range res.Warnings() {
+			if !strings.Contains(w, "skipped documented value") || !strings.Contains(w, "/age") {
+				t.Errorf("Unexpected warning %q", w)
+			}
+			warned = true
+		}
+	}
+	if !warned {
+		t.Error("Expected a warning for the invalid example")
+	}
+	if len(names) != 3 || !names["Ada Lovelace"] || !names["Grace Hopper"] || !names["Anonymous"] {
+		t.Errorf("Expected every documented name, got %v", names)
+	}
+
+	// Without the option values are random
+	doc, err := NewGenerator().SetSeed(42).GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if doc["name"] == "Ada Lovelace" || doc["name"] == "Grace Hopper" || doc["name"] == "Anonymous" {
+		t.Errorf("Expected a random name, got %v", doc["name"])
+	}
+}
@@ -0,0 +1,195 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// dataKeywords hold instance data rather than subschemas, so a "$ref" inside
+// them is not a reference
+var dataKeywords = map[string]bool{"const": true, "enum": true, "default": true, "examples": true}
+
+// ExtractSubschema returns the schema at a JSON Pointer in a larger schema
+// as a self-contained document. Definitions it refers to elsewhere in the
+// document are copied into its $defs and references are rewritten to match,
+// so the result can be published or generated from on its own. References
+// to other documents are left as they are.
+//
+// The pointer may be written as a URI fragment, e.g. "#/$defs/address".
+func ExtractSubschema(schemaJSON []byte, pointer string) ([]byte, error) {
+	if _, err := ParseSchema(schemaJSON); err != nil {
+		return nil, err
+	}
+	pointer = strings.TrimPrefix(pointer, "#")
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%q is not a JSON Pointer", pointer)
+	}
+
+	x := &extractor{source: schemaJSON, root: pointer, refs: make(map[string]string), defs: make(map[string]interface{})}
+	target, err := x.decode(pointer)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := target.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at %q is not a schema object", pointer)
+	}
+	for name := range asObject(obj["$defs"]) {
+		x.defs[name] = nil // keep the names of existing definitions
+	}
+	if err := x.rewrite(obj); err != nil {
+		return nil, err
+	}
+
+	// Bundled definitions may refer to further definitions in turn
+	for len(x.pending) > 0 {
+		def := x.pending[0]
+		x.pending = x.pending[1:]
+		value, err := x.decode(def.pointer)
+		if err != nil {
+			return nil, err
+		}
+		if err := x.rewrite(value); err != nil {
+			return nil, err
+		}
+		x.defs[def.name] = value
+	}
+
+	if len(x.bundled) > 0 {
+		defs := asObject(obj["$defs"])
+		if defs == nil {
+			defs = make(map[string]interface{})
+		}
+		for _, name := range x.bundled {
+			defs[name] = x.defs[name]
+		}
+		obj["$defs"] = defs
+	}
+	if _, ok := obj["$schema"]; !ok && pointer != "" {
+		if dialect, err := x.decode("/$schema"); err == nil {
+			obj["$schema"] = dialect
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// extractor collects the definitions an extracted subschema depends on
+type extractor struct {
+	source  []byte
+	root    string                 // pointer of the extracted subschema
+	refs    map[string]string      // pointer of a bundled schema to its new $ref
+	defs    map[string]interface{} // taken $defs names to bundled schemas
+	bundled []string               // names of bundled definitions, in order
+	pending []extractedDef         // bundled definitions not yet rewritten
+}
+
+// extractedDef is a schema copied into the extracted $defs
+type extractedDef struct {
+	name    string
+	pointer string
+}
+
+// decode returns a copy of the value at a pointer in the source
+func (x *extractor) decode(pointer string) (interface{}, error) {
+	raw, ok := rawValue(x.source, pointer)
+	if !ok {
+		return nil, fmt.Errorf("%q not found", "#"+pointer)
+	}
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// rewrite points the local references in a schema at their new location
+func (x *extractor) rewrite(value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+			pointer, err := url.PathUnescape(ref[1:])
+			if err != nil || (pointer != "" && !strings.HasPrefix(pointer, "/")) {
+				return fmt.Errorf("cannot resolve $ref %q: not a JSON Pointer", ref)
+			}
+			if v["$ref"], err = x.target(pointer); err != nil {
+				return fmt.Errorf("cannot resolve $ref %q: %w", ref, err)
+			}
+		}
+		for key, item := range v {
+			if dataKeywords[key] {
+				continue
+			}
+			if err := x.rewrite(item); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := x.rewrite(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// target returns the new $ref for a pointer into the source, scheduling the
+// schema it points to for bundling
+func (x *extractor) target(pointer string) (string, error) {
+	// References into the extracted subschema stay inside it
+	if x.root == "" || pointer == x.root || strings.HasPrefix(pointer, x.root+"/") {
+		return "#" + strings.TrimPrefix(pointer, x.root), nil
+	}
+	if ref, ok := x.refs[pointer]; ok {
+		return ref, nil
+	}
+	if _, ok := rawValue(x.source, pointer); !ok {
+		return "", fmt.Errorf("not found")
+	}
+
+	name := definitionName(pointer)
+	for i := 2; ; i++ {
+		if _, taken := x.defs[name]; !taken {
+			break
+		}
+		name = definitionName(pointer) + "_" + strconv.Itoa(i)
+	}
+	x.defs[name] = nil
+	x.bundled = append(x.bundled, name)
+	x.pending = append(x.pending, extractedDef{name: name, pointer: pointer})
+
+	ref := "#" + childPath("/$defs", name)
+	x.refs[pointer] = ref
+	return ref, nil
+}
+
+// definitionName derives a $defs name from the last token of a pointer that
+// is not an array index
+func definitionName(pointer string) string {
+	tokens := pointerTokens(pointer)
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if _, err := strconv.Atoi(tokens[i]); err != nil && tokens[i] != "" {
+			return tokens[i]
+		}
+	}
+	return "root"
+}
+
+// asObject returns v as a JSON object, or nil
+func asObject(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	return obj
+}
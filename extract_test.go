@@ -0,0 +1,140 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractSubschema(t *testing.T) {
+	source := []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"order": {
+				"type": "object",
+				"properties": {
+					"billing": {"$ref": "#/$defs/address"},
+					"shipping": {"$ref": "#/$defs/address"},
+					"backup": {"$ref": "#/properties/order/properties/billing"},
+					"lines": {"type": "array", "items": {"$ref": "#/$defs/line"}},
+					"note": {"const": {"$ref": "#/not/a/ref"}}
+				}
+			}
+		},
+		"$defs": {
+			"address": {"type": "object", "properties": {"zip": {"$ref": "#/$defs/zip"}}},
+			"zip": {"type": "string", "pattern": "^[0-9]{5}$"},
+			"line": {"type": "object", "properties": {"parent": {"$ref": "#/$defs/line"}}},
+			"unused": {"type": "null"}
+		}
+	}`)
+
+	tests := []struct {
+		name    string
+		pointer string
+		want    string
+	}{
+		{
+			name:    "bundles transitive definitions",
+			pointer: "/properties/order",
+			want: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {
+					"billing": {"$ref": "#/$defs/address"},
+					"shipping": {"$ref": "#/$defs/address"},
+					"backup": {"$ref": "#/properties/billing"},
+					"lines": {"type": "array", "items": {"$ref": "#/$defs/line"}},
+					"note": {"const": {"$ref": "#/not/a/ref"}}
+				},
+				"$defs": {
+					"address": {"type": "object", "properties": {"zip": {"$ref": "#/$defs/zip"}}},
+					"zip": {"type": "string", "pattern": "^[0-9]{5}$"},
+					"line": {"type": "object", "properties": {"parent": {"$ref": "#/$defs/line"}}}
+				}
+			}`,
+		},
+		{
+			name:    "recursive definition refers to itself",
+			pointer: "#/$defs/line",
+			want: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"properties": {"parent": {"$ref": "#"}}
+			}`,
+		},
+		{
+			name:    "without references",
+			pointer: "/$defs/zip",
+			want:    `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "string", "pattern": "^[0-9]{5}$"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractSubschema(source, tt.pointer)
+			if err != nil {
+				t.Fatalf("ExtractSubschema() error = %v", err)
+			}
+			var gotValue, wantValue interface{}
+			json.Unmarshal(got, &gotValue)
+			json.Unmarshal([]byte(tt.want), &wantValue)
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+
+			// The extracted schema stands on its own
+			if _, err := NewGenerator().SetSeed(42).Generate(got); err != nil {
+				t.Errorf("Generate() error = %v", err)
+			}
+		})
+	}
+}
+
+// Test bundled names do not clash with the subschema's own definitions
+func TestExtractSubschemaNames(t *testing.T) {
+	source := []byte(`{
+		"$defs": {
+			"id": {"type": "integer"},
+			"user": {
+				"properties": {"id": {"$ref": "#/$defs/id"}, "group": {"$ref": "#/$defs/user/$defs/id"}},
+				"$defs": {"id": {"type": "string"}}
+			}
+		}
+	}`)
+
+	got, err := ExtractSubschema(source, "/$defs/user")
+	if err != nil {
+		t.Fatalf("ExtractSubschema() error = %v", err)
+	}
+	want := `{"$defs":{"id":{"type":"string"},"id_2":{"type":"integer"}},"properties":{"group":{"$ref":"#/$defs/id"},"id":{"$ref":"#/$defs/id_2"}}}`
+	if string(got) != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestExtractSubschemaErrors(t *testing.T) {
+	source := []byte(`{"properties": {"a": {"$ref": "#/$defs/missing"}, "b": {"type": "string"}, "c": {"const": 1}}}`)
+
+	tests := []struct {
+		name    string
+		pointer string
+		want    string
+	}{
+		{"missing location", "/properties/z", "not found"},
+		{"not a pointer", "properties", "not a JSON Pointer"},
+		{"dangling reference", "/properties/a", "cannot resolve"},
+		{"not a schema", "/properties/c/const", "not a schema object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractSubschema(source, tt.pointer)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
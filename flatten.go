@@ -0,0 +1,178 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Field is a leaf value of a flattened schema
+type Field struct {
+	// Path is the dotted path of the value. Array items are written as
+	// "[]", tuple items by index, e.g. "orders[].lines[0].sku", and
+	// additional properties as "*".
+	Path        string   `json:"path"`
+	Types       []string `json:"types,omitempty"`
+	Required    bool     `json:"required"`              // present in every document
+	Constraints []string `json:"constraints,omitempty"` // e.g. "pattern=^[0-9]{5}$"
+	Description string   `json:"description,omitempty"`
+}
+
+// String formats a field as "user.address.zip: string pattern=^[0-9]{5}$"
+func (f Field) String() string {
+	types := "any"
+	if len(f.Types) > 0 {
+		types = strings.Join(f.Types, "|")
+	}
+	return strings.TrimSpace(f.Path + ": " + types + " " + strings.Join(f.Constraints, " "))
+}
+
+// Flatten lists the leaf values of the documents a schema produces, with
+// their types and constraints, ordered by property name. Objects are
+// flattened into their properties and arrays into their items, which suits
+// tabular consumers and documentation. Alternatives of oneOf and anyOf are listed
+// together and are never required; recursive schemas end in a field with
+// the constraint "recursive".
+func Flatten(schemaJSON []byte) ([]Field, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &flattener{index: make(map[string]int), visiting: make(map[*node]bool)}
+	f.walk(plan, "", true)
+	return f.fields, nil
+}
+
+// flattener collects the leaves of a plan
+type flattener struct {
+	fields   []Field
+	index    map[string]int // path to position in fields
+	visiting map[*node]bool
+}
+
+func (f *flattener) walk(n *node, path string, required bool) {
+	if n.merged != nil {
+		n = n.merged
+	}
+	if f.visiting[n] {
+		f.add(n, path, false, "recursive")
+		return
+	}
+	f.visiting[n] = true
+	defer delete(f.visiting, n)
+
+	// Alternatives share the path of the value they describe
+	for _, group := range [][]*node{n.oneOf, n.anyOf} {
+		for _, alt := range group {
+			f.walk(alt, path, false)
+		}
+	}
+	if len(n.oneOf) > 0 || len(n.anyOf) > 0 {
+		return
+	}
+
+	switch {
+	case len(n.properties) > 0 || n.additional != nil:
+		for _, prop := range n.properties {
+			f.walk(prop.node, joinPath(path, prop.name), required && prop.required)
+		}
+		if n.additional != nil {
+			f.walk(n.additional, joinPath(path, "*"), false)
+		}
+	case n.items != nil:
+		f.walk(n.items, path+"[]", required && n.minItems > 0)
+	case len(n.tuple) > 0:
+		for i, item := range n.tuple {
+			f.walk(item, path+"["+strconv.Itoa(i)+"]", required && i < n.minItems)
+		}
+		if n.rest != nil {
+			f.walk(n.rest, path+"[]", false)
+		}
+	default:
+		f.add(n, path, required)
+	}
+}
+
+// add records a leaf; a path seen before, from another alternative, gains
+// the alternative's types and constraints
+func (f *flattener) add(n *node, path string, required bool, extra ...string) {
+	if i, ok := f.index[path]; ok {
+		field := &f.fields[i]
+		for _, t := range n.types {
+			if !slices.Contains(field.Types, t) {
+				field.Types = append(field.Types, t)
+			}
+		}
+		for _, c := range append(fieldConstraints(n.schema), extra...) {
+			if !slices.Contains(field.Constraints, c) {
+				field.Constraints = append(field.Constraints, c)
+			}
+		}
+		field.Required = field.Required && required
+		return
+	}
+
+	f.index[path] = len(f.fields)
+	f.fields = append(f.fields, Field{
+		Path:        path,
+		Types:       append([]string(nil), n.types...),
+		Required:    required,
+		Constraints: append(fieldConstraints(n.schema), extra...),
+		Description: n.schema.Description,
+	})
+}
+
+// fieldConstraints describes the value keywords of a schema as key=value
+func fieldConstraints(schema *Schema) []string {
+	var constraints []string
+	add := func(key string, value interface{}) {
+		data, _ := json.Marshal(value)
+		constraints = append(constraints, key+"="+string(data))
+	}
+	addText := func(key, value string) {
+		if value != "" {
+			constraints = append(constraints, key+"="+value)
+		}
+	}
+	addInt := func(key string, value *int) {
+		if value != nil {
+			constraints = append(constraints, key+"="+strconv.Itoa(*value))
+		}
+	}
+	addFloat := func(key string, value *float64) {
+		if value != nil {
+			constraints = append(constraints, key+"="+strconv.FormatFloat(*value, 'g', -1, 64))
+		}
+	}
+
+	if schema.Const != nil {
+		add("const", schema.Const)
+	}
+	if len(schema.Enum) > 0 {
+		add("enum", schema.Enum)
+	}
+	addText("format", schema.Format)
+	addText("pattern", schema.Pattern)
+	addInt("minLength", schema.MinLength)
+	addInt("maxLength", schema.MaxLength)
+	addFloat("minimum", schema.Minimum)
+	addFloat("maximum", schema.Maximum)
+	addFloat("exclusiveMinimum", schema.ExclusiveMinimum)
+	addFloat("exclusiveMaximum", schema.ExclusiveMaximum)
+	addFloat("multipleOf", schema.MultipleOf)
+	return constraints
+}
+
+// joinPath appends a property name to a dotted path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,74 @@
+package schemagen
+
+import (
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"user": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string", "minLength": 1, "description": "Full name"},
+					"address": {"$ref": "#/$defs/address"}
+				},
+				"required": ["name", "address"]
+			},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "minItems": 1},
+			"point": {"type": "array", "items": [{"type": "number"}, {"type": "number", "maximum": 90}]},
+			"id": {"oneOf": [{"type": "integer", "minimum": 1}, {"type": "string", "format": "uuid"}]},
+			"meta": {"allOf": [{"properties": {"v": {"type": "integer"}}}, {"properties": {"v": {"multipleOf": 2}}}]},
+			"next": {"$ref": "#"}
+		},
+		"required": ["user", "tags"],
+		"$defs": {
+			"address": {"type": "object", "properties": {"zip": {"type": "string", "pattern": "^[0-9]{5}$"}}, "required": ["zip"]}
+		}
+	}`)
+
+	fields, err := Flatten(schema)
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+
+	want := []struct {
+		text     string
+		required bool
+	}{
+		{"id: integer|string minimum=1 format=uuid", false},
+		{"meta.v: integer multipleOf=2", false},
+		{"next: object recursive", false},
+		{"point[0]: number", false},
+		{"point[1]: number maximum=90", false},
+		{`tags[]: any enum=["a","b"]`, true},
+		{"user.address.zip: string pattern=^[0-9]{5}$", true},
+		{"user.name: string minLength=1", true},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("Expected %d fields, got %v", len(want), fields)
+	}
+	for i, w := range want {
+		if got := fields[i].String(); got != w.text {
+			t.Errorf("Field %d: expected %q, got %q", i, w.text, got)
+		}
+		if fields[i].Required != w.required {
+			t.Errorf("%s: expected required %v", fields[i].Path, w.required)
+		}
+	}
+	if fields[7].Description != "Full name" {
+		t.Errorf("Expected description, got %q", fields[7].Description)
+	}
+}
+
+// Test a scalar root is a single field with an empty path
+func TestFlattenScalar(t *testing.T) {
+	fields, err := Flatten([]byte(`{"type": "string", "maxLength": 3}`))
+	if err != nil {
+		t.Fatalf("Flatten() error = %v", err)
+	}
+	if len(fields) != 1 || fields[0].Path != "" || !fields[0].Required || fields[0].String() != ": string maxLength=3" {
+		t.Errorf("Unexpected fields %+v", fields)
+	}
+}
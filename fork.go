@@ -0,0 +1,28 @@
+package schemagen
+
+import (
+	"maps"
+	"slices"
+)
+
+// Fork returns a Generator with the same settings and its own random state,
+// seeded with seed. A Generator is not safe for concurrent use; to generate
+// fixtures in parallel, give every goroutine a fork. Forks made with the
+// same seed generate the same documents, whatever the goroutines' timing.
+//
+// Forks share the RefResolver, format providers, overrides, hooks and KeyFunc of g, which must
+// then be safe for concurrent use; plugins are. A CompiledSchema belongs to
+// the Generator that compiled it, so compile the schema again on each fork.
+func (g *Generator) Fork(seed int64) *Generator {
+	fork := *g
+	fork.FormatProviders = maps.Clone(g.FormatProviders)
+	fork.corpora = maps.Clone(g.corpora)
+	fork.overrides = slices.Clone(g.overrides)
+	fork.bundles = slices.Clone(g.bundles)
+	fork.hooks = slices.Clone(g.hooks)
+	fork.smartNames = maps.Clone(g.smartNames)
+	fork.warnings = nil
+	fork.retries = RetryStats{}
+	fork.refDocs = nil // the document cache is not safe to share
+	return fork.SetSeed(seed)
+}
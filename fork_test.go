@@ -0,0 +1,55 @@
+package schemagen
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Test forks generate in parallel like separately seeded generators
+func TestFork(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"code": {"type": "string", "pattern": "^[A-Z]{3}$"},
+			"count": {"type": "integer", "minimum": 1, "maximum": 9},
+			"note": {"type": "string"}
+		},
+		"required": ["id", "code"]
+	}`)
+
+	base := NewGenerator().SetGenerateAllFields(true)
+	results := make([]string, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(gen *Generator) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				doc, err := gen.Generate(schema)
+				if err != nil {
+					t.Errorf("Generate() error = %v", err)
+					return
+				}
+				results[i] += fmt.Sprint(doc)
+			}
+		}(base.Fork(int64(i + 1)))
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		gen := NewGenerator().SetSeed(int64(i + 1)).SetGenerateAllFields(true)
+		want := ""
+		for j := 0; j < 20; j++ {
+			doc, err := gen.Generate(schema)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			want += fmt.Sprint(doc)
+		}
+		if got != want {
+			t.Errorf("fork %d generated different documents than a generator seeded with %d", i, i+1)
+		}
+	}
+}
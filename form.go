@@ -0,0 +1,235 @@
+package schemagen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FormEncoding describes how a property is serialized into form fields, like
+// the style and explode fields of an OpenAPI encoding object
+type FormEncoding struct {
+	Style   string // "form" (default), "spaceDelimited", "pipeDelimited" or "deepObject"; "simple" for headers
+	Explode *bool  // defaults to true for forms and queries with style "form" and false otherwise
+}
+
+// formPair is one serialized form field
+type formPair struct {
+	key, value string
+}
+
+// GenerateForm generates an application/x-www-form-urlencoded request body
+// for an object schema. It returns the body and the matching Content-Type
+// header.
+//
+// Scalars become single fields. Arrays and objects follow the OpenAPI
+// serialization rules for their style: with style "form" and explode (the
+// default) arrays repeat the field and objects are flattened into one field
+// per property; without explode they are joined with commas. deepObject
+// writes nested objects as name[key]=value. encoding may be nil.
+func (g *Generator) GenerateForm(schemaJSON []byte, encoding map[string]FormEncoding) (io.Reader, string, error) {
+	if err := checkStyles(encoding, "form", "spaceDelimited", "pipeDelimited", "deepObject"); err != nil {
+		return nil, "", err
+	}
+	obj, err := g.generateFlat(schemaJSON)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var fields []string
+	for _, name := range sortedKeys(obj) {
+		for _, pair := range formPairs(name, obj[name], encoding[name]) {
+			fields = append(fields, url.QueryEscape(pair.key)+"="+url.QueryEscape(pair.value))
+		}
+	}
+	return strings.NewReader(strings.Join(fields, "&")), "application/x-www-form-urlencoded", nil
+}
+
+// GenerateQuery generates a query string for an object schema, serializing
+// each property with the same rules as GenerateForm
+func (g *Generator) GenerateQuery(schemaJSON []byte, encoding map[string]FormEncoding) (url.Values, error) {
+	if err := checkStyles(encoding, "form", "spaceDelimited", "pipeDelimited", "deepObject"); err != nil {
+		return nil, err
+	}
+	obj, err := g.generateFlat(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values)
+	for _, name := range sortedKeys(obj) {
+		for _, pair := range formPairs(name, obj[name], encoding[name]) {
+			values.Add(pair.key, pair.value)
+		}
+	}
+	return values, nil
+}
+
+// GenerateHeader generates HTTP headers for an object schema, one header per
+// property. Values follow the OpenAPI "simple" style: arrays are joined with
+// commas, and objects are written as "k,v,k2,v2", or "k=v,k2=v2" with
+// explode. encoding may be nil.
+func (g *Generator) GenerateHeader(schemaJSON []byte, encoding map[string]FormEncoding) (http.Header, error) {
+	if err := checkStyles(encoding, "simple"); err != nil {
+		return nil, err
+	}
+	obj, err := g.generateFlat(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	for _, name := range sortedKeys(obj) {
+		// Unlike forms, headers are not exploded by default
+		explode := encoding[name].Explode
+		header.Set(name, headerText(obj[name], explode != nil && *explode))
+	}
+	return header, nil
+}
+
+// checkStyles rejects encodings with styles outside the allowed ones; an
+// empty style is always allowed
+func checkStyles(encoding map[string]FormEncoding, allowed ...string) error {
+	for name, enc := range encoding {
+		if enc.Style == "" {
+			continue
+		}
+		supported := false
+		for _, style := range allowed {
+			supported = supported || enc.Style == style
+		}
+		if !supported {
+			return fmt.Errorf("unsupported style %q for %s", enc.Style, name)
+		}
+	}
+	return nil
+}
+
+// generateFlat generates an object to be serialized field by field
+func (g *Generator) generateFlat(schemaJSON []byte) (map[string]interface{}, error) {
+	plan, err := g.prepare(schemaJSON, "object")
+	if err != nil {
+		return nil, err
+	}
+	value, err := g.generate(newGenState(context.Background()), plan, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected object at root, got %T", value)
+	}
+	return obj, nil
+}
+
+// exploded reports whether arrays and objects are split into several fields
+func (e FormEncoding) exploded() bool {
+	if e.Explode != nil {
+		return *e.Explode
+	}
+	return e.Style == "" || e.Style == "form"
+}
+
+// delimiter returns the separator of joined array items
+func (e FormEncoding) delimiter() string {
+	switch e.Style {
+	case "spaceDelimited":
+		return " "
+	case "pipeDelimited":
+		return "|"
+	}
+	return ","
+}
+
+// formPairs serializes one property into form fields
+func formPairs(name string, value interface{}, enc FormEncoding) []formPair {
+	switch value := value.(type) {
+	case []interface{}:
+		if enc.exploded() {
+			pairs := make([]formPair, len(value))
+			for i, item := range value {
+				pairs[i] = formPair{name, fieldText(item)}
+			}
+			return pairs
+		}
+		texts := make([]string, len(value))
+		for i, item := range value {
+			texts[i] = fieldText(item)
+		}
+		return []formPair{{name, strings.Join(texts, enc.delimiter())}}
+	case map[string]interface{}:
+		if enc.Style == "deepObject" {
+			return deepObjectPairs(name, value)
+		}
+		if enc.exploded() {
+			var pairs []formPair
+			for _, key := range sortedKeys(value) {
+				pairs = append(pairs, formPair{key, fieldText(value[key])})
+			}
+			return pairs
+		}
+		var texts []string
+		for _, key := range sortedKeys(value) {
+			texts = append(texts, key, fieldText(value[key]))
+		}
+		return []formPair{{name, strings.Join(texts, ",")}}
+	default:
+		return []formPair{{name, scalarText(value)}}
+	}
+}
+
+// deepObjectPairs serializes an object as name[key]=value fields, nesting
+// brackets for nested objects and repeating fields for arrays
+func deepObjectPairs(name string, obj map[string]interface{}) []formPair {
+	var pairs []formPair
+	for _, key := range sortedKeys(obj) {
+		field := name + "[" + key + "]"
+		switch value := obj[key].(type) {
+		case map[string]interface{}:
+			pairs = append(pairs, deepObjectPairs(field, value)...)
+		case []interface{}:
+			for _, item := range value {
+				pairs = append(pairs, formPair{field, fieldText(item)})
+			}
+		default:
+			pairs = append(pairs, formPair{field, scalarText(value)})
+		}
+	}
+	return pairs
+}
+
+// headerText formats a value in the "simple" style
+func headerText(value interface{}, explode bool) string {
+	var texts []string
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			texts = append(texts, fieldText(item))
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			if explode {
+				texts = append(texts, key+"="+fieldText(value[key]))
+			} else {
+				texts = append(texts, key, fieldText(value[key]))
+			}
+		}
+	default:
+		return scalarText(value)
+	}
+	return strings.Join(texts, ",")
+}
+
+// fieldText formats a value nested too deep for the style as JSON
+func fieldText(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return scalarText(v)
+}
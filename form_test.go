@@ -0,0 +1,167 @@
+package schemagen
+
+import (
+	"io"
+	"net/url"
+	"testing"
+)
+
+func TestGenerateForm(t *testing.T) {
+	off := false
+	on := true
+
+	tests := []struct {
+		name     string
+		schema   string
+		encoding map[string]FormEncoding
+		want     string
+	}{
+		{
+			name:   "scalars",
+			schema: `{"type": "object", "properties": {"name": {"const": "Ann Lee"}, "age": {"const": 30}, "ok": {"const": true}}, "required": ["name", "age", "ok"]}`,
+			want:   "age=30&name=Ann+Lee&ok=true",
+		},
+		{
+			name:   "exploded array",
+			schema: `{"type": "object", "properties": {"tag": {"const": ["a", "b"]}}, "required": ["tag"]}`,
+			want:   "tag=a&tag=b",
+		},
+		{
+			name:     "joined array",
+			schema:   `{"type": "object", "properties": {"tag": {"const": ["a", "b"]}}, "required": ["tag"]}`,
+			encoding: map[string]FormEncoding{"tag": {Explode: &off}},
+			want:     "tag=a%2Cb",
+		},
+		{
+			name:     "space delimited array",
+			schema:   `{"type": "object", "properties": {"tag": {"const": ["a", "b"]}}, "required": ["tag"]}`,
+			encoding: map[string]FormEncoding{"tag": {Style: "spaceDelimited"}},
+			want:     "tag=a+b",
+		},
+		{
+			name:     "pipe delimited array",
+			schema:   `{"type": "object", "properties": {"tag": {"const": ["a", "b"]}}, "required": ["tag"]}`,
+			encoding: map[string]FormEncoding{"tag": {Style: "pipeDelimited"}},
+			want:     "tag=a%7Cb",
+		},
+		{
+			name:   "exploded object",
+			schema: `{"type": "object", "properties": {"color": {"const": {"R": 100, "G": 200}}}, "required": ["color"]}`,
+			want:   "G=200&R=100",
+		},
+		{
+			name:     "joined object",
+			schema:   `{"type": "object", "properties": {"color": {"const": {"R": 100, "G": 200}}}, "required": ["color"]}`,
+			encoding: map[string]FormEncoding{"color": {Style: "form", Explode: &off}},
+			want:     "color=G%2C200%2CR%2C100",
+		},
+		{
+			name:     "deep object",
+			schema:   `{"type": "object", "properties": {"filter": {"const": {"size": {"min": 1}, "tags": ["x", "y"]}}}, "required": ["filter"]}`,
+			encoding: map[string]FormEncoding{"filter": {Style: "deepObject", Explode: &on}},
+			want:     "filter%5Bsize%5D%5Bmin%5D=1&filter%5Btags%5D=x&filter%5Btags%5D=y",
+		},
+		{
+			name:   "nested value as JSON",
+			schema: `{"type": "object", "properties": {"rows": {"const": [[1, 2]]}}, "required": ["rows"]}`,
+			want:   "rows=%5B1%2C2%5D",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType, err := NewGenerator().SetSeed(42).GenerateForm([]byte(tt.schema), tt.encoding)
+			if err != nil {
+				t.Fatalf("GenerateForm() error = %v", err)
+			}
+			if contentType != "application/x-www-form-urlencoded" {
+				t.Errorf("Unexpected content type %q", contentType)
+			}
+			data, _ := io.ReadAll(body)
+			if string(data) != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, data)
+			}
+		})
+	}
+}
+
+// Test generated forms parse and unknown styles are rejected
+func TestGenerateFormErrors(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"q": {"type": "string"}, "n": {"type": "integer"}}, "required": ["q", "n"]}`)
+
+	body, _, err := NewGenerator().SetSeed(42).GenerateForm(schema, nil)
+	if err != nil {
+		t.Fatalf("GenerateForm() error = %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	values, err := url.ParseQuery(string(data))
+	if err != nil || len(values["q"]) != 1 || len(values["n"]) != 1 {
+		t.Errorf("Unexpected form %s: %v", data, err)
+	}
+
+	if _, _, err := NewGenerator().GenerateForm(schema, map[string]FormEncoding{"q": {Style: "matrix"}}); err == nil {
+		t.Error("Expected error for unsupported style")
+	}
+	if _, _, err := NewGenerator().GenerateForm([]byte(`{"type": "string"}`), nil); err == nil {
+		t.Error("Expected error for non-object schema")
+	}
+}
+
+func TestGenerateQuery(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"q": {"const": "red shoes"},
+			"size": {"const": [40, 41]},
+			"filter": {"const": {"brand": "acme"}}
+		},
+		"required": ["q", "size", "filter"]
+	}`)
+
+	values, err := NewGenerator().SetSeed(42).GenerateQuery(schema, map[string]FormEncoding{"filter": {Style: "deepObject"}})
+	if err != nil {
+		t.Fatalf("GenerateQuery() error = %v", err)
+	}
+	want := "filter%5Bbrand%5D=acme&q=red+shoes&size=40&size=41"
+	if got := values.Encode(); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestGenerateHeader(t *testing.T) {
+	on := true
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"X-Request-Id": {"const": "abc"},
+			"X-Tags": {"const": ["a", "b"]},
+			"X-Color": {"const": {"R": 100, "G": 200}},
+			"X-Point": {"const": {"x": 1, "y": 2}}
+		},
+		"required": ["X-Request-Id", "X-Tags", "X-Color", "X-Point"]
+	}`)
+
+	header, err := NewGenerator().SetSeed(42).GenerateHeader(schema, map[string]FormEncoding{"X-Point": {Explode: &on}})
+	if err != nil {
+		t.Fatalf("GenerateHeader() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"X-Request-Id", "abc"},
+		{"X-Tags", "a,b"},
+		{"X-Color", "G,200,R,100"},
+		{"X-Point", "x=1,y=2"},
+	}
+	for _, tt := range tests {
+		if got := header.Get(tt.name); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := NewGenerator().GenerateHeader(schema, map[string]FormEncoding{"X-Tags": {Style: "form"}}); err == nil {
+		t.Error("Expected error for unsupported header style")
+	}
+}
@@ -0,0 +1,201 @@
+package schemagen
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// formatLengths holds the shortest and longest strings generated for a
+// format, with -1 for unbounded
+var formatLengths = map[string][2]int{
+	"uuid":      {36, 36},
+	"date":      {10, 10},
+	"time":      {8, 8},
+	"date-time": {20, 25},
+	"ipv4":      {7, 15},
+	"ipv6":      {2, 39},
+	"email":     {6, 254},
+	"hostname":  {4, 253},
+	"uri":       {12, -1},
+	"url":       {12, -1},
+	"byte":      {0, -1},
+	"binary":    {0, -1},
+}
+
+// formatLengthBounds returns the lengths a format string of a node may
+// have: minLength and maxLength, where a missing maxLength leaves the
+// format's own limit
+func formatLengthBounds(n *node) (int, int) {
+	lo, hi := n.minLength, n.maxLength
+	if n.schema.MaxLength == nil {
+		hi = math.MaxInt32
+		if bounds, ok := formatLengths[n.schema.Format]; ok && bounds[1] >= 0 {
+			hi = max(bounds[1], lo)
+		}
+	}
+	return lo, hi
+}
+
+// checkFormatLength fails when no string of the node's format has a length
+// within minLength and maxLength
+func checkFormatLength(n *node) error {
+	format := n.schema.Format
+	bounds, ok := formatLengths[format]
+	if !ok {
+		return nil
+	}
+	lo, hi := formatLengthBounds(n)
+	impossible := bounds[0] > hi || (bounds[1] >= 0 && bounds[1] < lo)
+	if format == "byte" {
+		// Base64 comes in blocks of 4 characters
+		impossible = (lo+3)/4*4 > hi
+	}
+	if impossible {
+		return fmt.Errorf("format %q cannot produce strings of %d to %d characters", format, n.minLength, n.maxLength)
+	}
+	return nil
+}
+
+// generateFormatString generates a string of the node's format within
+// minLength and maxLength. Emails, hostnames, URIs, IPv4 addresses and
+// binary strings are resized to fit; other formats are generated again
+// until one fits.
+func (g *Generator) generateFormatString(n *node, path string) (string, error) {
+	schema := n.schema
+	if schema.MinLength == nil && schema.MaxLength == nil {
+		return g.generateStringFromFormat(schema.Format, path)
+	}
+
+	lo, hi := formatLengthBounds(n)
+	fits := func(v interface{}) bool {
+		length := utf8.RuneCountInString(v.(string))
+		return length >= lo && length <= hi
+	}
+	value, err := g.retry("format+length", path, func() (interface{}, error) {
+		s, err := g.generateStringFromFormat(schema.Format, path)
+		if err != nil || fits(s) {
+			return s, err
+		}
+		target := min(max(utf8.RuneCountInString(s), lo), hi)
+		if resized, ok := g.resizeFormat(schema.Format, s, target, lo, hi); ok {
+			return resized, nil
+		}
+		return s, nil
+	}, fits)
+	if err != nil {
+		return "", err
+	}
+	return value.(string), nil
+}
+
+// resizeFormat turns a generated string of a format into one of target
+// characters, or of a length within lo and hi for base64
+func (g *Generator) resizeFormat(format, s string, target, lo, hi int) (string, bool) {
+	switch format {
+	case "email":
+		local, domain, _ := strings.Cut(s, "@")
+		localLength := min(max(target-1-len(domain), 1), 64)
+		if localLength+1+len(domain) != target {
+			var ok bool
+			if domain, ok = g.hostOfLength(target-1-localLength, topLevelDomain(domain)); !ok {
+				return "", false
+			}
+		}
+		local = strings.ReplaceAll(local, ".", "")
+		if len(local) > localLength {
+			return local[:localLength] + "@" + domain, true
+		}
+		return local + g.lowerLetters(localLength-len(local)) + "@" + domain, true
+	case "hostname":
+		return g.hostOfLength(target, topLevelDomain(s))
+	case "uri", "url":
+		scheme, rest, _ := strings.Cut(s, "://")
+		host, _, _ := strings.Cut(rest, "/")
+		base := scheme + "://" + host
+		if len(base) > target {
+			host, ok := g.hostOfLength(target-len(scheme)-3, topLevelDomain(host))
+			return scheme + "://" + host, ok
+		}
+		if len(base) == target {
+			return base, true
+		}
+		return base + "/" + g.lowerLetters(target-len(base)-1), true
+	case "ipv4":
+		// Octets of 1 to 3 digits, adding up to the target
+		digits := target - 3
+		octets := make([]string, 4)
+		for i := range octets {
+			left := 3 - i
+			size := max(1, digits-3*left) + g.rand.Intn(min(3, digits-left)-max(1, digits-3*left)+1)
+			digits -= size
+			switch size {
+			case 1:
+				octets[i] = strconv.Itoa(g.rand.Intn(10))
+			case 2:
+				octets[i] = strconv.Itoa(10 + g.rand.Intn(90))
+			default:
+				octets[i] = strconv.Itoa(100 + g.rand.Intn(156))
+			}
+		}
+		return strings.Join(octets, "."), true
+	case "binary":
+		return g.lowerLetters(target), true
+	case "byte":
+		length := max(target/4*4, (lo+3)/4*4)
+		if length > hi {
+			return "", false
+		}
+		data := make([]byte, length/4*3)
+		g.rand.Read(data)
+		return base64.StdEncoding.EncodeToString(data), true
+	}
+	return "", false
+}
+
+// hostOfLength generates a hostname of exactly length characters ending in
+// the given top-level domain
+func (g *Generator) hostOfLength(length int, tld string) (string, bool) {
+	rest := length - len(tld) - 1
+	if rest < 1 && len(tld) > 2 {
+		return g.hostOfLength(length, "io")
+	}
+	if rest < 1 || length > 253 {
+		return "", false
+	}
+
+	// Labels are at most 63 characters
+	var labels []string
+	for rest > 0 {
+		size := rest
+		if rest > 63 {
+			size = min(63, rest-2)
+		}
+		labels = append(labels, g.lowerLetters(size))
+		rest -= size
+		if rest > 0 {
+			rest-- // the dot before the next label
+		}
+	}
+	return strings.Join(append(labels, tld), "."), true
+}
+
+// topLevelDomain returns the last label of a hostname, "com" if it has none
+func topLevelDomain(host string) string {
+	if i := strings.LastIndexByte(host, '.'); i >= 0 && i < len(host)-1 {
+		return host[i+1:]
+	}
+	return "com"
+}
+
+// lowerLetters returns n random lowercase ASCII letters
+func (g *Generator) lowerLetters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + g.rand.Intn(26))
+	}
+	return string(b)
+}
@@ -0,0 +1,69 @@
+package schemagen
+
+import (
+	"encoding/base64"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Test format strings honor minLength and maxLength
+func TestFormatWithLength(t *testing.T) {
+	hostname := regexp.MustCompile(`^([a-z0-9-]{1,63}\.)+[a-z]{2,}$`)
+	tests := []struct {
+		format   string
+		min, max int
+		valid    func(string) bool
+	}{
+		{"email", 6, 12, func(s string) bool { _, err := mail.ParseAddress(s); return err == nil }},
+		{"email", 40, 60, func(s string) bool { _, err := mail.ParseAddress(s); return err == nil }},
+		{"hostname", 5, 8, hostname.MatchString},
+		{"hostname", 100, 120, hostname.MatchString},
+		{"uri", 15, 20, func(s string) bool { u, err := url.Parse(s); return err == nil && u.IsAbs() }},
+		{"uri", 120, 130, func(s string) bool { u, err := url.Parse(s); return err == nil && u.IsAbs() }},
+		{"binary", 3, 5, func(s string) bool { return true }},
+		{"byte", 5, 10, func(s string) bool { _, err := base64.StdEncoding.DecodeString(s); return err == nil }},
+		{"ipv4", 7, 10, func(s string) bool { return strings.Count(s, ".") == 3 }},
+		{"uuid", 36, 40, func(s string) bool { return len(s) == 36 }},
+	}
+
+	gen := NewGenerator().SetSeed(42)
+	for _, tt := range tests {
+		schema := []byte(`{"type": "string", "format": "` + tt.format + `", "minLength": ` + strconv.Itoa(tt.min) + `, "maxLength": ` + strconv.Itoa(tt.max) + `}`)
+		for i := 0; i < 30; i++ {
+			result, err := gen.Generate(schema)
+			if err != nil {
+				t.Fatalf("%s: Generate() error = %v", tt.format, err)
+			}
+			s := result.(string)
+			if n := utf8.RuneCountInString(s); n < tt.min || n > tt.max || !tt.valid(s) {
+				t.Fatalf("%s: generated %q (%d characters), want %d to %d", tt.format, s, n, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+// Test formats that cannot fit the length limits fail before generating
+func TestFormatLengthConflict(t *testing.T) {
+	tests := []string{
+		`{"type": "string", "format": "uuid", "maxLength": 20}`,
+		`{"type": "string", "format": "date", "minLength": 11}`,
+		`{"type": "string", "format": "email", "maxLength": 5}`,
+		`{"type": "string", "format": "byte", "minLength": 5, "maxLength": 7}`,
+	}
+	for _, schema := range tests {
+		_, err := NewGenerator().SetSeed(42).Generate([]byte(schema))
+		if err == nil || !strings.Contains(err.Error(), "cannot produce strings of") {
+			t.Errorf("Generate(%s) error = %v, want length conflict", schema, err)
+		}
+	}
+
+	// Without maxLength the format decides how long strings get
+	if _, err := NewGenerator().SetSeed(42).Generate([]byte(`{"type": "string", "format": "uuid", "minLength": 10}`)); err != nil {
+		t.Errorf("Generate() error = %v", err)
+	}
+}
@@ -0,0 +1,403 @@
+package schemagen
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromStruct builds a JSON Schema for the Go type of v, so fixtures for Go
+// types need no hand-written schema. Field names and omission follow the
+// json tags; fields without omitempty are required. Constraints come from
+// validate tags in the style of go-playground/validator (required, min,
+// max, len, gt, gte, lt, lte, oneof, email, url, uuid, ...) and jsonschema
+// tags holding keyword=value pairs:
+//
+//	Age  int    `json:"age" validate:"gte=18,lte=130"`
+//	Code string `json:"code,omitempty" jsonschema:"pattern=^[A-Z]{3}$,description=ISO code"`
+//
+// Named struct types used by fields are placed in $defs, so recursive types
+// work. Encode the schema with json.Marshal to generate from it, e.g. with
+// GenerateInto.
+func FromStruct(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("cannot build a schema for nil")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	b := &structSchema{root: t, names: make(map[reflect.Type]string), defs: make(map[string]interface{})}
+	doc, err := b.inline(t)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.defs) > 0 {
+		doc["$defs"] = b.defs
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchema(data)
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// structSchema builds the schema of a Go type
+type structSchema struct {
+	root  reflect.Type
+	names map[reflect.Type]string // names of the types in defs
+	defs  map[string]interface{}
+}
+
+// schema returns the schema of a type, referring to named struct types
+func (b *structSchema) schema(t reflect.Type) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.Name() == "" || t == timeType || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return b.inline(t)
+	}
+	if t == b.root {
+		return map[string]interface{}{"$ref": "#"}, nil
+	}
+
+	name, ok := b.names[t]
+	if !ok {
+		name = t.Name()
+		for i := 2; b.defs[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", t.Name(), i)
+		}
+		b.names[t] = name
+		b.defs[name] = map[string]interface{}{} // reserved while building
+		def, err := b.inline(t)
+		if err != nil {
+			return nil, err
+		}
+		b.defs[name] = def
+	}
+	return map[string]interface{}{"$ref": "#/$defs/" + name}, nil
+}
+
+// inline returns the schema of a type with the members of structs in place
+func (b *structSchema) inline(t reflect.Type) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case reflect.PointerTo(t).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		// Bounds keep generated values decodable into the type
+		lo, hi := intLimits(t)
+		return map[string]interface{}{"type": "integer", "minimum": lo, "maximum": hi}, nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return map[string]interface{}{"type": "string", "format": "byte"}, nil
+		}
+		items, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		s := map[string]interface{}{"type": "array", "items": items}
+		if t.Kind() == reflect.Array {
+			s["minItems"], s["maxItems"] = t.Len(), t.Len()
+		}
+		return s, nil
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		s := map[string]interface{}{"type": "object", "additionalProperties": values}
+		if t.Key().Kind() != reflect.String {
+			s["propertyNames"] = map[string]interface{}{"type": "string", "pattern": integerKeyPattern(t.Key())}
+		}
+		return s, nil
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		var required []string
+		if err := b.fields(t, properties, &required); err != nil {
+			return nil, err
+		}
+		s := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// fields adds the properties of a struct's fields, with the fields of
+// embedded structs in place like encoding/json
+func (b *structSchema) fields(t reflect.Type, properties map[string]interface{}, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if err := b.fields(ft, properties, required); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s, err := b.schema(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		isRequired := field.Type.Kind() != reflect.Pointer && !strings.Contains(","+opts+",", ",omitempty,")
+		if s["$ref"] == nil {
+			if err := applyValidateTag(s, ft, field.Tag.Get("validate"), &isRequired); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			if err := applyJSONSchemaTag(s, ft, field.Tag.Get("jsonschema"), &isRequired); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		} else if strings.Contains(","+field.Tag.Get("validate")+",", ",required,") {
+			isRequired = true
+		}
+
+		properties[name] = s
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// validateFormats maps validate tag rules to formats
+var validateFormats = map[string]string{
+	"email": "email", "url": "uri", "uri": "uri", "http_url": "uri",
+	"uuid": "uuid", "uuid4": "uuid", "ipv4": "ipv4", "ipv6": "ipv6",
+	"hostname": "hostname", "hostname_rfc1123": "hostname", "base64": "byte",
+}
+
+// validatePatterns maps validate tag rules to patterns
+var validatePatterns = map[string]string{
+	"alpha": "^[a-zA-Z]+$", "alphanum": "^[a-zA-Z0-9]+$", "numeric": "^[0-9]+$",
+	"lowercase": "^[a-z]+$", "uppercase": "^[A-Z]+$", "hexadecimal": "^[0-9a-fA-F]+$",
+}
+
+// applyValidateTag adds the constraints of a validate tag to the schema of
+// a field of type t. Rules after dive apply to elements and are skipped.
+func applyValidateTag(s map[string]interface{}, t reflect.Type, tag string, required *bool) error {
+	if tag == "" {
+		return nil
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "dive":
+			return nil
+		case "required":
+			*required = true
+		case "min", "gte":
+			if err := setBound(s, t, value, "minimum", "minLength", "minItems", "minProperties"); err != nil {
+				return err
+			}
+		case "max", "lte":
+			if err := setBound(s, t, value, "maximum", "maxLength", "maxItems", "maxProperties"); err != nil {
+				return err
+			}
+		case "len":
+			if err := setBound(s, t, value, "", "minLength", "minItems", "minProperties"); err != nil {
+				return err
+			}
+			if err := setBound(s, t, value, "", "maxLength", "maxItems", "maxProperties"); err != nil {
+				return err
+			}
+		case "gt":
+			if err := setBound(s, t, value, "exclusiveMinimum", "", "", ""); err != nil {
+				return err
+			}
+		case "lt":
+			if err := setBound(s, t, value, "exclusiveMaximum", "", "", ""); err != nil {
+				return err
+			}
+		case "oneof":
+			enum, err := tagValues(t, strings.Fields(value))
+			if err != nil {
+				return err
+			}
+			s["enum"] = enum
+		default:
+			if format, ok := validateFormats[key]; ok {
+				s["format"] = format
+			} else if pattern, ok := validatePatterns[key]; ok {
+				s["pattern"] = pattern
+			}
+		}
+	}
+	return nil
+}
+
+// setBound sets the keyword a size rule means for the kind of t: a number
+// bound, a string length, an item count or a property count
+func setBound(s map[string]interface{}, t reflect.Type, value, number, length, items, properties string) error {
+	keyword := number
+	switch t.Kind() {
+	case reflect.String:
+		keyword = length
+	case reflect.Slice, reflect.Array:
+		keyword = items
+	case reflect.Map:
+		keyword = properties
+	}
+	if keyword == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s value %q", keyword, value)
+	}
+	if keyword != number && (n < 0 || n != math.Trunc(n)) {
+		return fmt.Errorf("invalid %s value %q", keyword, value)
+	}
+	s[keyword] = n
+	return nil
+}
+
+// jsonSchemaNumbers are the jsonschema tag keywords with number values
+var jsonSchemaNumbers = map[string]bool{
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true, "multipleOf": true,
+	"minLength": true, "maxLength": true, "minItems": true, "maxItems": true, "minProperties": true, "maxProperties": true,
+}
+
+// applyJSONSchemaTag adds the keyword=value pairs of a jsonschema tag to the
+// schema of a field of type t. enum may be given several times; values of
+// enum, default and example are numbers or booleans for fields of those
+// types.
+func applyJSONSchemaTag(s map[string]interface{}, t reflect.Type, tag string, required *bool) error {
+	if tag == "" {
+		return nil
+	}
+	for _, pair := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(pair, "=")
+		switch {
+		case key == "required":
+			*required = true
+		case key == "uniqueItems":
+			s[key] = value != "false"
+		case jsonSchemaNumbers[key]:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s value %q", key, value)
+			}
+			s[key] = n
+		case key == "enum":
+			v, err := tagValues(t, []string{value})
+			if err != nil {
+				return err
+			}
+			enum, _ := s["enum"].([]interface{})
+			s["enum"] = append(enum, v[0])
+		case key == "default" || key == "example":
+			v, err := tagValues(t, []string{value})
+			if err != nil {
+				return err
+			}
+			s[key] = v[0]
+		case key == "title" || key == "description" || key == "format" || key == "pattern":
+			s[key] = value
+		default:
+			return fmt.Errorf("unsupported jsonschema tag keyword %q", key)
+		}
+	}
+	return nil
+}
+
+// tagValues converts tag values to the JSON values of a field of type t
+func tagValues(t reflect.Type, values []string) ([]interface{}, error) {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		switch t.Kind() {
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid boolean %q", value)
+			}
+			result[i] = b
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q", value)
+			}
+			result[i] = json.Number(value)
+		default:
+			result[i] = value
+		}
+	}
+	return result, nil
+}
+
+// integerKeyPattern matches the map keys encoding/json decodes into an
+// integer type: decimal numbers without leading zeros, a digit shorter than
+// the largest value of the type so none overflows
+func integerKeyPattern(t reflect.Type) string {
+	if t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64 {
+		digits := len(strconv.FormatUint(math.MaxUint64>>(64-t.Bits()), 10)) - 1
+		return fmt.Sprintf("^(0|[1-9][0-9]{0,%d})$", digits-1)
+	}
+	digits := len(strconv.FormatInt(math.MaxInt64>>(64-t.Bits()), 10)) - 1
+	return fmt.Sprintf("^(0|-?[1-9][0-9]{0,%d})$", digits-1)
+}
+
+// intLimits returns the range of a sized integer type
+func intLimits(t reflect.Type) (lo, hi int64) {
+	bits := t.Bits()
+	switch t.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return 0, 1<<bits - 1
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
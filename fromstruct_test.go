@@ -0,0 +1,152 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type structAddress struct {
+	City string `json:"city" validate:"required,min=2,max=20"`
+	Zip  string `json:"zip,omitempty" jsonschema:"pattern=^[0-9]{5}$"`
+}
+
+type structBase struct {
+	ID int64 `json:"id" validate:"gte=1,lte=1000"`
+}
+
+type structUser struct {
+	structBase
+	Email    string            `json:"email" validate:"required,email"`
+	Age      uint8             `json:"age" validate:"gte=18,lte=130"`
+	Role     string            `json:"role" validate:"oneof=admin member guest"`
+	Level    int16             `json:"level"`
+	Score    float64           `json:"score" jsonschema:"minimum=0,maximum=1"`
+	Tags     []string          `json:"tags" validate:"min=1,max=3,dive,alpha"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Home     structAddress     `json:"home"`
+	Work     *structAddress    `json:"work"`
+	Created  time.Time         `json:"created"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Manager  *structUser       `json:"manager,omitempty"`
+	internal string
+	Skipped  string `json:"-"`
+}
+
+// Test schemas built from struct tags generate values of the struct
+func TestFromStruct(t *testing.T) {
+	schema, err := FromStruct(&structUser{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+	if _, ok := schema.Properties["Skipped"]; ok {
+		t.Error("expected fields tagged - to be skipped")
+	}
+	if _, ok := schema.Properties["internal"]; ok {
+		t.Error("expected unexported fields to be skipped")
+	}
+	if _, ok := schema.Properties["id"]; !ok {
+		t.Error("expected the fields of embedded structs in place")
+	}
+	for _, name := range []string{"id", "email", "home"} {
+		if !strings.Contains(","+strings.Join(schema.Required, ",")+",", ","+name+",") {
+			t.Errorf("expected %s to be required, got %v", name, schema.Required)
+		}
+	}
+	if strings.Contains(strings.Join(schema.Required, ","), "work") {
+		t.Errorf("expected pointer fields to be optional, got %v", schema.Required)
+	}
+	if _, ok := schema.Defs["structAddress"]; !ok {
+		t.Errorf("expected named structs in $defs, got %v", schema.Defs)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	gen := NewGenerator().SetSeed(42)
+	for i := 0; i < 20; i++ {
+		var u structUser
+		if err := gen.GenerateInto(data, &u); err != nil {
+			t.Fatalf("GenerateInto() error = %v", err)
+		}
+		switch {
+		case u.ID < 1 || u.ID > 1000:
+			t.Errorf("id %d outside [1, 1000]", u.ID)
+		case !strings.Contains(u.Email, "@"):
+			t.Errorf("expected an email, got %q", u.Email)
+		case u.Age < 18 || u.Age > 130:
+			t.Errorf("age %d outside [18, 130]", u.Age)
+		case u.Role != "admin" && u.Role != "member" && u.Role != "guest":
+			t.Errorf("role %q not in oneof", u.Role)
+		case u.Score < 0 || u.Score > 1:
+			t.Errorf("score %v outside [0, 1]", u.Score)
+		case len(u.Tags) < 1 || len(u.Tags) > 3:
+			t.Errorf("expected 1 to 3 tags, got %v", u.Tags)
+		case len(u.Home.City) < 2 || len(u.Home.City) > 20:
+			t.Errorf("city %q breaks its length", u.Home.City)
+		case u.Created.IsZero():
+			t.Error("expected a creation time")
+		}
+	}
+}
+
+// Test maps with integer keys round-trip through generation
+func TestFromStructIntegerKeys(t *testing.T) {
+	type maps struct {
+		M   map[int]string    `json:"m" validate:"min=2"`
+		I8  map[int8]bool     `json:"i8" validate:"min=2"`
+		U16 map[uint16]string `json:"u16" validate:"min=2"`
+		U64 map[uint64]int    `json:"u64" validate:"min=2"`
+	}
+	schema, err := FromStruct(maps{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	gen := NewGenerator().SetGenerateAllFields(true)
+	for seed := int64(0); seed < 20; seed++ {
+		var m maps
+		if err := gen.SetSeed(seed).GenerateInto(data, &m); err != nil {
+			t.Fatalf("seed %d: GenerateInto() error = %v", seed, err)
+		}
+		if len(m.M) < 2 || len(m.I8) < 2 || len(m.U16) < 2 || len(m.U64) < 2 {
+			t.Errorf("seed %d: expected at least 2 entries per map, got %+v", seed, m)
+		}
+	}
+}
+
+// Test types that have no schema
+func TestFromStructErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "nil"},
+		{"channel field", struct {
+			C chan int `json:"c"`
+		}{}, "field C"},
+		{"map key", map[bool]string{}, "map key"},
+		{"tag keyword", struct {
+			S string `jsonschema:"colour=red"`
+		}{}, "colour"},
+		{"bad bound", struct {
+			S string `validate:"min=x"`
+		}{}, "minLength"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromStruct(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("FromStruct() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// formatExemplars are typical values of each supported format, as JSON
+var formatExemplars = map[string]string{
+	"date-time": `"2024-01-01T00:00:00Z"`,
+	"date":      `"2024-01-01"`,
+	"time":      `"00:00:00"`,
+	"uuid":      `"123e4567-e89b-12d3-a456-426614174000"`,
+	"email":     `"user@example.com"`,
+	"ipv4":      `"127.0.0.1"`,
+	"ipv6":      `"::1"`,
+	"uri":       `"https://example.com/"`,
+	"url":       `"https://example.com/"`,
+	"hostname":  `"example.com"`,
+	"byte":      `"AAAA"`,
+}
+
+// FuzzDictionary exports schema-aware tokens for coverage-guided fuzzers in
+// the AFL/libFuzzer dictionary format: property names, enum and const values,
+// format exemplars and the numbers on both sides of every bound. Tokens are
+// written as they appear in JSON documents, so strings keep their quotes.
+func FuzzDictionary(schemaJSON []byte) ([]byte, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &fuzzDictionary{seen: make(map[string]bool), counts: make(map[string]int)}
+	for _, token := range []string{"true", "false", "null"} {
+		d.add("literal", token)
+	}
+	for _, n := range planNodes(plan) {
+		d.addNode(n)
+	}
+	return d.buf.Bytes(), nil
+}
+
+// fuzzDictionary collects unique tokens in the order they are found
+type fuzzDictionary struct {
+	buf    bytes.Buffer
+	seen   map[string]bool
+	counts map[string]int
+}
+
+// addNode adds the tokens of one schema
+func (d *fuzzDictionary) addNode(n *node) {
+	s := n.schema
+
+	for _, prop := range n.properties {
+		d.addJSON("property", prop.name)
+	}
+	if s.Const != nil {
+		d.addJSON("const", s.Const)
+	}
+	for _, v := range s.Enum {
+		d.addJSON("enum", v)
+	}
+	if exemplar, ok := formatExemplars[s.Format]; ok {
+		d.add("format", exemplar)
+	}
+
+	integer := matchesAnyType(int64(0), n.types) && !matchesAnyType(0.5, n.types)
+	bound := func(v float64) {
+		if integer {
+			d.add("number", strconv.FormatFloat(math.Ceil(v)-1, 'f', -1, 64))
+			d.add("number", strconv.FormatFloat(math.Floor(v)+1, 'f', -1, 64))
+		} else {
+			d.add("number", strconv.FormatFloat(math.Nextafter(v, math.Inf(-1)), 'g', -1, 64))
+			d.add("number", strconv.FormatFloat(math.Nextafter(v, math.Inf(1)), 'g', -1, 64))
+		}
+		d.add("number", strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	for _, v := range []*float64{s.Minimum, s.Maximum, s.ExclusiveMinimum, s.ExclusiveMaximum, s.MultipleOf} {
+		if v != nil {
+			bound(*v)
+		}
+	}
+}
+
+// addJSON adds a value encoded as JSON
+func (d *fuzzDictionary) addJSON(kind string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		d.add(kind, string(data))
+	}
+}
+
+// add writes a token as a named dictionary entry unless it was seen before
+func (d *fuzzDictionary) add(kind, token string) {
+	if d.seen[token] {
+		return
+	}
+	d.seen[token] = true
+	d.counts[kind]++
+	fmt.Fprintf(&d.buf, "%s_%d=\"%s\"\n", kind, d.counts[kind], dictEscape(token))
+}
+
+// dictEscape escapes a token for a dictionary entry: backslashes, quotes and
+// non-printable bytes are written as escapes
+func dictEscape(token string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(token); i++ {
+		c := token[i]
+		switch {
+		case c == '\\' || c == '"':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&buf, "\\x%02X", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
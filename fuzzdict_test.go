@@ -0,0 +1,55 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFuzzDictionary(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"status": {"enum": ["open", "closed"]},
+			"age": {"type": "integer", "minimum": 18, "maximum": 65},
+			"email": {"type": "string", "format": "email"},
+			"kind": {"const": "café"}
+		}
+	}`)
+
+	dict, err := FuzzDictionary(schema)
+	if err != nil {
+		t.Fatalf("FuzzDictionary() error = %v", err)
+	}
+	text := string(dict)
+
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{"literal", `literal_1="true"`},
+		{"property name", `property_1="\"age\""`},
+		{"enum value", `"\"closed\""`},
+		{"format exemplar", `format_1="\"user@example.com\""`},
+		{"below minimum", `="17"`},
+		{"above maximum", `="66"`},
+		{"non-ASCII bytes escaped", `"\"caf\xC3\xA9\""`},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(text, tt.entry) {
+			t.Errorf("%s: expected %s in\n%s", tt.name, tt.entry, text)
+		}
+	}
+
+	// Every line is a named entry, and tokens are not repeated
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		name, token, ok := strings.Cut(line, "=")
+		if !ok || name == "" || !strings.HasPrefix(token, `"`) || !strings.HasSuffix(token, `"`) {
+			t.Errorf("Malformed entry %q", line)
+		}
+		if seen[token] {
+			t.Errorf("Duplicate token %s", token)
+		}
+		seen[token] = true
+	}
+}
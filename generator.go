@@ -2,23 +2,90 @@ package schemagen
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
+	randv2 "math/rand/v2"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
-	"github.com/lucasjones/reggen"
 )
 
 // Generator configuration for generating random JSON data
 type Generator struct {
 	MaxDepth          int
-	Seed              int64
+	Seed              int64 // Seed for value content, see SetValueSeed
+	StructureSeed     int64 // Seed for document shape, see SetStructureSeed
 	rand              *rand.Rand
 	faker             *gofakeit.Faker
-	GenerateAllFields bool // If false, only generate required fields
+	shape             *rand.Rand                // structure decisions: array lengths, types, branches
+	GenerateAllFields bool                      // If false, only generate required fields
+	ShuffleKeys       bool                      // If true, encoded objects use a seed-derived key order
+	OpenRange         OpenRange                 // How numbers are sampled when a bound is missing
+	MaxAttempts       int                       // Attempt budget for constraints met by generate-and-check
+	AutoTune          bool                      // If true, the depth limit and array sizes are adjusted to each schema
+	Duplicates        Duplicates                // Duplicate-record injection for GenerateRelatedN
+	RefResolver       RefResolver               // Loads documents for $ref to other documents, see SetRefResolver
+	BaseURI           string                    // URI that relative $ref in the root schema resolve against
+	MaxProperties     int                       // Cap on properties per object when generating all fields, 0 for none
+	SeedProperty      string                    // Property of object documents recording the seeds, see SetSeedProperty
+	MinAdditional     int                       // Fewest additional properties per object when generating all fields
+	MaxAdditional     int                       // Most additional properties per object when generating all fields
+	PropertyKeys      KeyFunc                   // Names additional properties without propertyNames, random words if nil
+	PreferExamples    bool                      // If true, documented examples and defaults replace random values
+	SkipDeprecated    bool                      // If true, optional properties marked deprecated are not generated
+	FormatProviders   map[string]FormatProvider // Custom providers by format, see SetFormatProvider
+	ValidateOutput    bool                      // If true, documents are checked against the schema, see SetValidateOutput
+	UnicodeText       bool                      // If true, free-form strings mix in characters beyond ASCII
+	ProseText         bool                      // If true, description-like strings are sentences, see SetProseText
+	TextMode          TextMode                  // What free-form strings may contain, see SetTextMode
+	EdgeCaseNumbers   bool                      // If true, half of the numbers are edge cases, see SetEdgeCaseNumbers
+	SmartNames        bool                      // If true, values are inferred from property names, see SetSmartNames
+	Locale            string                    // Locale of generated text, see SetLocale
+
+	corpora    map[string]*markovChain // text corpora by name, see SetTextCorpus
+	overrides  []pathOverride          // in the order they were set, see SetOverrideGlob
+	bundles    []string                // names of the bundles applied, see Use
+	hooks      []Hook                  // in the order they were added, see AddHook
+	smartNames map[string]string       // property names to kinds of values, see SetSmartName
+	warnings   []string                // collected during the current generation call
+	retries    RetryStats              // collected during the current generation call
+	refDocs    *refLoader              // documents loaded by RefResolver, kept across calls
+	calls      int                     // documents generated since the seeds were set, see SetSeedProperty
+}
+
+// genState carries per-call state through the recursive generation functions
+type genState struct {
+	ctx     context.Context
+	locale  *Locale       // locale selected by the nearest x-locale, nil for the default
+	related *relation     // sequences, pools and unique values shared by the call
+	example int           // number of the documentation example being generated, 0 outside Examples
+	lengths map[*node]int // array lengths fixed by an enclosing x-rectangular array
+	depth   int           // depth limit set by auto-tuning, 0 for MaxDepth
+
+	// Time budget, see GenerateWithBudget
+	deadline  time.Time
+	exhausted bool
+	report    *BudgetReport
+}
+
+// newGenState creates the state for a single generation call
+func newGenState(ctx context.Context) *genState {
+	return &genState{ctx: ctx, related: newRelation()}
+}
+
+// pointerEscaper escapes reference tokens as described in RFC 6901
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// childPath appends a reference token to a JSON Pointer
+func childPath(path, token string) string {
+	return path + "/" + pointerEscaper.Replace(token)
 }
 
 // NewGenerator creates a new Generator with default settings
@@ -27,20 +94,51 @@ func NewGenerator() *Generator {
 	return &Generator{
 		MaxDepth:          10,
 		Seed:              seed,
+		StructureSeed:     seed,
 		rand:              rand.New(rand.NewSource(seed)),
-		faker:             gofakeit.New(uint64(seed)),
+		faker:             newFaker(seed),
+		shape:             rand.New(rand.NewSource(seed)),
 		GenerateAllFields: false,
+		OpenRange:         DefaultOpenRange(),
+		MaxAttempts:       DefaultMaxAttempts,
+		MaxAdditional:     2,
 	}
 }
 
-// SetSeed sets a specific seed for deterministic generation
+// SetSeed sets a specific seed for deterministic generation. It is the same
+// as calling SetStructureSeed and SetValueSeed with the same seed.
 func (g *Generator) SetSeed(seed int64) *Generator {
+	return g.SetStructureSeed(seed).SetValueSeed(seed)
+}
+
+// SetStructureSeed sets the seed for decisions about the shape of documents:
+// array lengths, which type or oneOf/anyOf branch is used, how many
+// additional properties are added and the shuffled key order. Keeping it
+// fixed while changing the value seed keeps documents the same shape.
+func (g *Generator) SetStructureSeed(seed int64) *Generator {
+	g.StructureSeed = seed
+	g.shape = rand.New(rand.NewSource(seed))
+	g.calls = 0
+	return g
+}
+
+// SetValueSeed sets the seed for the content of values: strings, numbers,
+// booleans, formats and enum picks. Keeping it fixed while changing the
+// structure seed varies the shape of documents.
+func (g *Generator) SetValueSeed(seed int64) *Generator {
 	g.Seed = seed
 	g.rand = rand.New(rand.NewSource(seed))
-	g.faker = gofakeit.New(uint64(seed))
+	g.faker = newFaker(seed)
+	g.calls = 0
 	return g
 }
 
+// newFaker returns a faker seeded with seed. Unlike gofakeit.New, seed 0 is
+// a seed like any other rather than a request for a random one.
+func newFaker(seed int64) *gofakeit.Faker {
+	return gofakeit.NewFaker(randv2.NewPCG(uint64(seed), uint64(seed)), true)
+}
+
 // SetMaxDepth sets the maximum recursion depth
 func (g *Generator) SetMaxDepth(depth int) *Generator {
 	g.MaxDepth = depth
@@ -53,98 +151,361 @@ func (g *Generator) SetGenerateAllFields(all bool) *Generator {
 	return g
 }
 
+// SetMaxPropertiesPerObject caps the properties of every generated object
+// when all fields are generated. Required properties are always included;
+// which optional properties fill the remaining places is sampled from the
+// structure seed, so wide objects stay readable while a batch still covers
+// every property. Zero removes the cap.
+func (g *Generator) SetMaxPropertiesPerObject(n int) *Generator {
+	g.MaxProperties = n
+	return g
+}
+
+// KeyFunc returns the name of an additional property of the object at path,
+// a JSON Pointer. Names that are taken are asked for again, so the function
+// should not return the same name every time; r is seeded from the value
+// seed for reproducible names.
+type KeyFunc func(r *rand.Rand, path string) string
+
+// SetAdditionalPropertyCount sets how many additional properties objects
+// that allow them get when all fields are generated, drawn uniformly from
+// [min, max]. The default is 0 to 2. minProperties and maxProperties of the
+// schema, and SetMaxPropertiesPerObject, take precedence.
+func (g *Generator) SetAdditionalPropertyCount(min, max int) *Generator {
+	g.MinAdditional = min
+	g.MaxAdditional = max
+	return g
+}
+
+// SetAdditionalPropertyKeys sets the function that names additional
+// properties, e.g. to generate IDs or snake_case keys for map-like payloads.
+// A propertyNames schema takes precedence; nil restores random words.
+func (g *Generator) SetAdditionalPropertyKeys(keys KeyFunc) *Generator {
+	g.PropertyKeys = keys
+	return g
+}
+
+// SetSkipDeprecated controls whether optional properties marked
+// "deprecated": true are left out, so fixtures reflect the payloads clients
+// should send. Deprecated properties that are required, or needed to reach
+// minProperties, are still generated.
+func (g *Generator) SetSkipDeprecated(skip bool) *Generator {
+	g.SkipDeprecated = skip
+	return g
+}
+
+// SetUnicodeText controls whether free-form strings mix in characters
+// beyond ASCII: accented letters, Greek, Cyrillic, CJK and emoji outside the
+// Basic Multilingual Plane. Lengths still count code points, as minLength
+// and maxLength do, so the strings exercise consumers that count bytes or
+// UTF-16 units instead.
+func (g *Generator) SetUnicodeText(unicode bool) *Generator {
+	g.UnicodeText = unicode
+	return g
+}
+
+// SetShuffleKeys controls whether GenerateBytes emits object keys in a
+// shuffled order derived from the seed instead of sorted order. This helps
+// catch consumers that wrongly depend on key order while staying reproducible.
+func (g *Generator) SetShuffleKeys(shuffle bool) *Generator {
+	g.ShuffleKeys = shuffle
+	return g
+}
+
 // Generate generates random JSON data that conforms to the provided schema
 func (g *Generator) Generate(schemaJSON []byte) (interface{}, error) {
-	schema, err := ParseSchema(schemaJSON)
+	return g.generateRoot(newGenState(context.Background()), schemaJSON, "")
+}
+
+// GenerateBytes generates random JSON data and returns it as bytes
+func (g *Generator) GenerateBytes(schemaJSON []byte) ([]byte, error) {
+	result, err := g.Generate(schemaJSON)
 	if err != nil {
 		return nil, err
 	}
+	return g.marshal(result)
+}
 
-	if err := schema.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid schema: %w", err)
+// marshal encodes a generated document, shuffling its keys when set
+func (g *Generator) marshal(v interface{}) ([]byte, error) {
+	if g.ShuffleKeys {
+		return marshalShuffled(v, g.shape.Int63(), false)
+	}
+	return json.Marshal(v)
+}
+
+// GenerateMap generates random JSON data and returns it as an object.
+// It fails before generating anything if the root schema declares a type
+// that cannot produce an object.
+func (g *Generator) GenerateMap(schemaJSON []byte) (map[string]interface{}, error) {
+	result, err := g.generateRoot(newGenState(context.Background()), schemaJSON, "object")
+	if err != nil {
+		return nil, err
 	}
 
-	return g.generate(schema, 0)
+	obj, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected object at root, got %T", result)
+	}
+	return obj, nil
 }
 
-// GenerateBytes generates random JSON data and returns it as bytes
-func (g *Generator) GenerateBytes(schemaJSON []byte) ([]byte, error) {
-	result, err := g.Generate(schemaJSON)
+// GenerateSlice generates random JSON data and returns it as an array.
+// It fails before generating anything if the root schema declares a type
+// that cannot produce an array.
+func (g *Generator) GenerateSlice(schemaJSON []byte) ([]interface{}, error) {
+	result, err := g.generateRoot(newGenState(context.Background()), schemaJSON, "array")
 	if err != nil {
 		return nil, err
 	}
 
-	return json.Marshal(result)
+	arr, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected array at root, got %T", result)
+	}
+	return arr, nil
 }
 
-// GenerateWithContext generates random JSON data with context support for cancellation
-func (g *Generator) GenerateWithContext(ctx context.Context, schemaJSON []byte) (interface{}, error) {
+// GenerateInto generates a document and decodes it into target, a non-nil
+// pointer such as a *struct, with encoding/json rules: properties without
+// a matching field are dropped. It saves the marshal and unmarshal step in
+// tests that work with Go types.
+func (g *Generator) GenerateInto(schemaJSON []byte, target interface{}) error {
+	if v := reflect.ValueOf(target); v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	data, err := g.GenerateBytes(schemaJSON)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode into %T: %w", target, err)
+	}
+	return nil
+}
+
+// generateRoot parses and validates the schema, checks that its declared root
+// type allows the expected type (if any), and generates data from it
+func (g *Generator) generateRoot(st *genState, schemaJSON []byte, expectedType string) (interface{}, error) {
+	plan, err := g.prepare(schemaJSON, expectedType)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := g.generateDocument(st, plan)
+	if err != nil {
+		return nil, err
+	}
+	g.stampSeed(plan, result)
+	return result, nil
+}
+
+// prepare parses, checks and compiles a schema for a new generation call
+func (g *Generator) prepare(schemaJSON []byte, expectedType string) (*node, error) {
 	schema, err := ParseSchema(schemaJSON)
 	if err != nil {
 		return nil, err
 	}
+	return g.prepareSchema(schema, expectedType)
+}
 
-	if err := schema.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid schema: %w", err)
+// prepareSchema checks and compiles a parsed schema for a new generation call
+func (g *Generator) prepareSchema(schema *Schema, expectedType string) (*node, error) {
+	g.warnings = nil
+	g.retries = RetryStats{}
+
+	if expectedType != "" && !schema.Type.IsEmpty() && !schema.Type.Contains(expectedType) {
+		return nil, fmt.Errorf("root schema type %v cannot produce %s", schema.Type.GetTypes(), expectedType)
+	}
+
+	if errs := schema.ValidateSource(schema.source); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid schema: %w", errs[0])
+	}
+	if g.Locale != "" {
+		if _, err := resolveLocale(g.Locale); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := compileWith(schema, g.BaseURI, g.refLoader())
+	if err != nil {
+		return nil, err
 	}
 
-	return g.generateWithContext(ctx, schema, 0)
+	for _, n := range planNodes(plan) {
+		for _, warning := range n.warnings {
+			g.warnf("%s", warning)
+		}
+	}
+	if g.AutoTune {
+		g.autoTune(plan)
+	}
+	return narrowRoot(plan, expectedType), nil
+}
+
+// narrowRoot returns a root node that generates only the expected type, if
+// any, where the root schema allows several. References back to the root
+// keep the full list of types.
+func narrowRoot(plan *node, expectedType string) *node {
+	if expectedType == "" || len(plan.types) < 2 || !slices.Contains(plan.types, expectedType) {
+		return plan
+	}
+	root := *plan
+	root.types = []string{expectedType}
+	return &root
 }
 
-// generate is the core recursive generation function
-func (g *Generator) generate(schema *Schema, depth int) (interface{}, error) {
-	return g.generateWithContext(context.Background(), schema, depth)
+// GenerateWithContext generates random JSON data with context support for cancellation
+func (g *Generator) GenerateWithContext(ctx context.Context, schemaJSON []byte) (interface{}, error) {
+	return g.generateRoot(newGenState(ctx), schemaJSON, "")
 }
 
-// generateWithContext is the core recursive generation function with context support
-func (g *Generator) generateWithContext(ctx context.Context, schema *Schema, depth int) (interface{}, error) {
+// generate is the core recursive generation function. path is the JSON
+// Pointer of the value being generated. Errors are GenerationErrors located
+// at the deepest value that failed.
+func (g *Generator) generate(st *genState, n *node, path string, depth int) (interface{}, error) {
+	gen := func() (interface{}, error) {
+		if fn := g.override(path); fn != nil {
+			return fn(n.schema)
+		}
+		return g.generateNode(st, n, path, depth)
+	}
+	var value interface{}
+	var err error
+	if len(g.hooks) > 0 {
+		value, err = g.hooked(n, path, depth, gen)
+	} else {
+		value, err = gen()
+	}
+	if err != nil {
+		return nil, locateError(err, path)
+	}
+	return value, nil
+}
+
+// generateNode generates the value of a node at path
+func (g *Generator) generateNode(st *genState, n *node, path string, depth int) (interface{}, error) {
 	// Check for context cancellation
 	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("generation cancelled: %w", ctx.Err())
+	case <-st.ctx.Done():
+		return nil, fmt.Errorf("generation cancelled: %w", st.ctx.Err())
 	default:
 	}
 
-	// Check depth limit
-	if depth >= g.MaxDepth {
-		return nil, fmt.Errorf("maximum recursion depth (%d) exceeded", g.MaxDepth)
+	// Check depth limit, which auto-tuning may have set for the plan
+	if depth == 0 && n.maxDepth > 0 {
+		st.depth = n.maxDepth
+	}
+	if limit := g.depthLimit(st); depth >= limit {
+		return nil, fmt.Errorf("%w (%d)", ErrMaxDepth, limit)
+	}
+
+	// x-locale applies to this schema and everything below it
+	if n.locale != nil {
+		parent := st.locale
+		st.locale = n.locale
+		defer func() { st.locale = parent }()
 	}
 
-	// Handle const - must return exact value
+	// Deliberate data-quality problems
+	if g.atRate(st, n, "x-nullable-rate", n.schema.NullableRate) {
+		return nil, nil
+	}
+
+	gen := func() (interface{}, error) {
+		// Values shared across the documents of a call (sequences, pools, unique values)
+		if n.related() {
+			return g.generateRelated(st, n, path, depth)
+		}
+		return g.generateValue(st, n, path, depth)
+	}
+
+	return g.satisfyNot(n, path, func() (interface{}, error) {
+		return g.satisfyObject(n, path, gen)
+	})
+}
+
+// depthLimit returns the depth values of the call must stay under
+func (g *Generator) depthLimit(st *genState) int {
+	if st.depth > 0 {
+		return st.depth
+	}
+	return g.MaxDepth
+}
+
+// fits reports whether a value of n generated at depth can stay under the
+// depth limit. Only auto-tuning of recursive schemas measures the depth
+// values need; other nodes always fit.
+func (g *Generator) fits(st *genState, n *node, depth int) bool {
+	return n.minDepth == 0 || depth+n.minDepth <= g.depthLimit(st)
+}
+
+// fitting returns the nodes that fit at depth, or all of them if none does
+func (g *Generator) fitting(st *genState, nodes []*node, depth int) []*node {
+	var fit []*node
+	for _, n := range nodes {
+		if g.fits(st, n, depth) {
+			fit = append(fit, n)
+		}
+	}
+	if len(fit) == 0 {
+		return nodes
+	}
+	return fit
+}
+
+// generateValue generates a value for a node from its keywords
+func (g *Generator) generateValue(st *genState, n *node, path string, depth int) (interface{}, error) {
+	schema := n.schema
+
+	// Handle const - must return exact value, copied so that documents do
+	// not share objects and arrays with the schema or with each other
 	if schema.Const != nil {
-		return schema.Const, nil
+		return deepCopy(schema.Const), nil
 	}
 
-	// Handle enum - pick one random value
-	if len(schema.Enum) > 0 {
-		return schema.Enum[g.rand.Intn(len(schema.Enum))], nil
+	// Documentation examples show documented values where there are any
+	if st.example > 0 {
+		if value, ok := g.documentedValue(n, st.example, path); ok {
+			return value, nil
+		}
+	}
+	if g.PreferExamples {
+		if value, ok := g.preferredValue(n, path); ok {
+			return value, nil
+		}
+	}
+
+	// Handle enum - pick one of the values the rest of the schema allows
+	if len(n.enum) > 0 {
+		return deepCopy(n.enum[g.rand.Intn(len(n.enum))]), nil
 	}
 
 	// Handle composition keywords
-	if len(schema.OneOf) > 0 {
-		return g.handleOneOf(schema, depth)
+	if len(n.oneOf) > 0 {
+		return g.handleOneOf(st, n, path, depth)
 	}
 
-	if len(schema.AnyOf) > 0 {
-		return g.handleAnyOf(schema, depth)
+	if len(n.anyOf) > 0 {
+		return g.handleAnyOf(st, n, path, depth)
 	}
 
-	if len(schema.AllOf) > 0 {
-		return g.handleAllOf(schema, depth)
+	if len(n.allOf) > 0 {
+		return g.handleAllOf(st, n, path, depth)
 	}
 
 	// Handle type-based generation
-	if !schema.Type.IsEmpty() {
-		return g.generateByType(schema, depth)
+	if len(n.types) > 0 {
+		return g.generateByType(st, n, path, depth)
 	}
 
 	// If no type specified, try to infer from other properties
 	if schema.Properties != nil {
-		return g.generateObject(schema, depth)
+		return g.generateObject(st, n, path, depth)
 	}
 
 	if schema.Items != nil {
-		return g.generateArray(schema, depth)
+		return g.generateArray(st, n, path, depth)
 	}
 
 	// Default to generating an object if we have no other info
@@ -152,36 +513,36 @@ func (g *Generator) generateWithContext(ctx context.Context, schema *Schema, dep
 }
 
 // generateByType generates data based on the type field
-func (g *Generator) generateByType(schema *Schema, depth int) (interface{}, error) {
-	types := schema.Type.GetTypes()
-
-	// If multiple types, randomly choose one
-	if len(types) > 1 {
-		chosenType := types[g.rand.Intn(len(types))]
-		modifiedSchema := *schema
-		modifiedSchema.Type = StringOrArray{Single: chosenType, IsArray: false}
-		return g.generateByType(&modifiedSchema, depth)
-	}
-
-	if len(types) == 0 {
+func (g *Generator) generateByType(st *genState, n *node, path string, depth int) (interface{}, error) {
+	if len(n.types) == 0 {
 		return nil, fmt.Errorf("no type specified")
 	}
 
-	typeName := types[0]
+	// If multiple types, randomly choose one
+	typeName := n.types[0]
+	if len(n.types) > 1 {
+		typeName = n.types[g.shape.Intn(len(n.types))]
+	}
 
 	switch typeName {
 	case "string":
-		return g.generateString(schema)
+		return g.generateString(st, n, path)
 	case "number":
-		return g.generateNumber(schema, false)
+		if v, ok := g.smartNumber(n.schema, path, false); ok {
+			return v, nil
+		}
+		return g.generateNumber(n.schema, false)
 	case "integer":
-		return g.generateNumber(schema, true)
+		if v, ok := g.smartNumber(n.schema, path, true); ok {
+			return v, nil
+		}
+		return g.generateNumber(n.schema, true)
 	case "boolean":
 		return g.generateBoolean()
 	case "object":
-		return g.generateObject(schema, depth)
+		return g.generateObject(st, n, path, depth)
 	case "array":
-		return g.generateArray(schema, depth)
+		return g.generateArray(st, n, path, depth)
 	case "null":
 		return nil, nil
 	default:
@@ -190,53 +551,80 @@ func (g *Generator) generateByType(schema *Schema, depth int) (interface{}, erro
 }
 
 // generateString generates a random string conforming to schema constraints
-func (g *Generator) generateString(schema *Schema) (string, error) {
+func (g *Generator) generateString(st *genState, n *node, path string) (string, error) {
 	// Check pattern first (highest priority)
-	if schema.Pattern != "" {
-		return g.generateStringFromPattern(schema.Pattern)
+	if n.pattern != nil {
+		value, err := g.satisfyLength(n, path, func() (interface{}, error) {
+			if n.schema.MinLength != nil || n.schema.MaxLength != nil {
+				if s, ok := g.patternOfLength(n); ok {
+					return s, nil
+				}
+			}
+			return g.generateStringFromPattern(n)
+		})
+		if err != nil {
+			return "", err
+		}
+		return value.(string), nil
 	}
 
 	// Check format
-	if schema.Format != "" {
-		return g.generateStringFromFormat(schema.Format)
+	if n.schema.Format != "" {
+		return g.generateFormatString(n, path)
 	}
 
-	// Generate random string with length constraints
-	minLen := 0
-	maxLen := 20 // default max length
-
-	if schema.MinLength != nil {
-		minLen = *schema.MinLength
+	locale, err := g.localeOf(st)
+	if err != nil {
+		return "", err
 	}
-	if schema.MaxLength != nil {
-		maxLen = *schema.MaxLength
+	if s, ok := g.smartString(locale, n, path); ok {
+		return s, nil
 	}
 
-	// Ensure min <= max
-	if minLen > maxLen {
-		maxLen = minLen
+	// Generate random string with length constraints
+	var text string
+	if name := n.schema.Corpus; name != "" {
+		chain, ok := g.corpora[name]
+		if !ok {
+			return "", keywordErrorf("x-corpus", "unknown text corpus %q, see SetTextCorpus", name)
+		}
+		text = g.corpusText(chain, n)
+	} else if g.ProseText && isProse(n, path) {
+		if chain, ok := g.corpora[""]; ok {
+			text = g.corpusText(chain, n)
+		} else {
+			text = g.proseText(locale, n)
+		}
+	} else {
+		length := n.minLength
+		if n.maxLength > n.minLength {
+			length = n.minLength + g.rand.Intn(n.maxLength-n.minLength+1)
+		}
+		text = g.randomText(locale, length)
 	}
-
-	length := minLen
-	if maxLen > minLen {
-		length = minLen + g.rand.Intn(maxLen-minLen+1)
+	if g.UnicodeText {
+		text = g.mixUnicode(text)
 	}
-
-	return g.randomString(length), nil
+	return g.applyTextMode(text), nil
 }
 
-// generateStringFromPattern generates a string matching the regex pattern
-func (g *Generator) generateStringFromPattern(pattern string) (string, error) {
-	gen, err := reggen.NewGenerator(pattern)
-	if err != nil {
-		return "", fmt.Errorf("invalid regex pattern: %w", err)
-	}
-
-	return gen.Generate(10), nil // limit to 10 attempts
+// generateStringFromPattern generates a string matching the node's regex pattern
+func (g *Generator) generateStringFromPattern(n *node) (string, error) {
+	// Seed the compiled pattern from the generator so output is reproducible
+	n.pattern.SetSeed(g.rand.Int63())
+	return n.pattern.Generate(10), nil // limit to 10 attempts
 }
 
 // generateStringFromFormat generates a string based on the format keyword
-func (g *Generator) generateStringFromFormat(format string) (string, error) {
+func (g *Generator) generateStringFromFormat(format, path string) (string, error) {
+	if provider, ok := g.FormatProviders[format]; ok {
+		value, err := provider.GenerateFormat(FormatRequest{Format: format, Path: path, Seed: g.rand.Int63()})
+		if err != nil {
+			return "", &keywordError{keyword: "format", err: err}
+		}
+		return value, nil
+	}
+
 	switch format {
 	case "uuid":
 		return g.faker.UUID(), nil
@@ -256,85 +644,59 @@ func (g *Generator) generateStringFromFormat(format string) (string, error) {
 		return g.faker.URL(), nil
 	case "hostname":
 		return g.faker.DomainName(), nil
+	case "byte":
+		data := make([]byte, 12)
+		g.rand.Read(data)
+		return base64.StdEncoding.EncodeToString(data), nil
+	case "binary":
+		return g.faker.LetterN(16), nil
 	default:
 		// For unsupported formats, generate a generic string
+		g.warnf("unsupported format %q, generated a generic word", format)
 		return g.faker.Word(), nil
 	}
 }
 
 // generateNumber generates a random number (integer or float) conforming to constraints
 func (g *Generator) generateNumber(schema *Schema, isInteger bool) (interface{}, error) {
-	var min, max float64
+	min, max, openMin, openMax := g.numberBounds(schema, isInteger)
 
-	// Determine minimum
-	if schema.Minimum != nil {
-		min = *schema.Minimum
-	} else if schema.ExclusiveMinimum != nil {
-		min = *schema.ExclusiveMinimum
-		if isInteger {
-			min = math.Ceil(min)
-		}
-	} else {
-		if isInteger {
-			min = 0
-		} else {
-			min = 0.0
-		}
+	// Ensure min <= max
+	if min > max {
+		return nil, fmt.Errorf("minimum (%v) is greater than maximum (%v)", min, max)
 	}
 
-	// Determine maximum
-	if schema.Maximum != nil {
-		max = *schema.Maximum
-	} else if schema.ExclusiveMaximum != nil {
-		max = *schema.ExclusiveMaximum
-		if isInteger {
-			max = math.Floor(max) - 1
-		}
-	} else {
-		if isInteger {
-			max = 1000
-		} else {
-			max = 1000.0
+	if g.EdgeCaseNumbers {
+		if v, ok := g.edgeCaseNumber(schema, isInteger); ok {
+			return v, nil
 		}
 	}
-
-	// Ensure min <= max
-	if min > max {
-		return nil, fmt.Errorf("minimum (%f) is greater than maximum (%f)", min, max)
+	if isInteger {
+		return g.generateInteger(schema, min, max, openMin, openMax)
 	}
 
 	var result float64
 
-	if isInteger {
-		intMin := int64(math.Ceil(min))
-		intMax := int64(math.Floor(max))
-
-		if intMin > intMax {
-			result = float64(intMin)
-		} else {
-			result = float64(intMin + g.rand.Int63n(intMax-intMin+1))
-		}
+	if (openMin || openMax) && g.OpenRange.Mode == OpenRangeExponential {
+		result = g.sampleOpenRange(min, max, openMin, openMax, false)
 	} else {
-		result = min + g.rand.Float64()*(max-min)
+		// Interpolating keeps ranges wider than the largest float finite
+		f := g.rand.Float64()
+		result = min*(1-f) + max*f
 	}
 
+	// min and max are inclusive, exclusive bounds having been moved inwards;
+	// rounding must not carry the value past them
+	result = math.Min(math.Max(result, min), max)
+
 	// Handle multipleOf constraint
 	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
-		multiple := *schema.MultipleOf
-		result = math.Round(result/multiple) * multiple
-
-		// Ensure result is still within bounds
-		if result < min {
-			result += multiple
-		}
-		if result > max {
-			result -= multiple
+		var err error
+		if result, err = nearestMultiple(result, *schema.MultipleOf, min, max); err != nil {
+			return nil, err
 		}
 	}
 
-	if isInteger {
-		return int64(result), nil
-	}
 	return result, nil
 }
 
@@ -344,195 +706,497 @@ func (g *Generator) generateBoolean() (bool, error) {
 }
 
 // generateObject generates a random object conforming to schema
-func (g *Generator) generateObject(schema *Schema, depth int) (interface{}, error) {
+func (g *Generator) generateObject(st *genState, n *node, path string, depth int) (interface{}, error) {
 	result := make(map[string]interface{})
 
-	if schema.Properties == nil {
-		return result, nil
+	// Generate properties
+	degraded := false
+	missing := make(map[string]bool) // omitted by x-missing-rate
+	sampled := g.sampleOptional(n)
+	for _, prop := range n.properties {
+		// Generate field if it's required or if we're generating all fields;
+		// x-include-rate decides on its own
+		switch {
+		case prop.required:
+		case !g.fits(st, prop.node, depth+1):
+			continue // recursion that would pass the depth limit ends here
+		case g.SkipDeprecated && prop.node.schema.Deprecated:
+			continue
+		case prop.node.schema.IncludeRate > 0:
+			if !g.atRate(st, prop.node, "x-include-rate", prop.node.schema.IncludeRate) {
+				continue
+			}
+		case !g.GenerateAllFields, sampled != nil && !sampled[prop.name]:
+			continue
+		}
+
+		if n.forbids(prop.name) {
+			continue
+		}
+
+		if g.atRate(st, prop.node, "x-missing-rate", prop.node.schema.MissingRate) {
+			missing[prop.name] = true
+			continue
+		}
+
+		// Once the time budget is spent only required fields are generated
+		if !prop.required && st.overBudget() {
+			degraded = true
+			continue
+		}
+
+		value, err := g.generate(st, prop.node, childPath(path, prop.name), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result[prop.name] = value
 	}
 
-	// Track which fields are required
-	requiredMap := make(map[string]bool)
-	for _, fieldName := range schema.Required {
-		requiredMap[fieldName] = true
+	// Required names without a property schema still have to be present
+	for _, name := range n.schema.Required {
+		if _, present := result[name]; present || missing[name] {
+			continue
+		}
+		value, err := g.generateProperty(st, n, name, path, depth)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = value
 	}
 
-	// Generate properties
-	for fieldName, fieldSchema := range schema.Properties {
-		// Generate field if it's required or if we're generating all fields
-		if requiredMap[fieldName] || g.GenerateAllFields {
-			value, err := g.generate(fieldSchema, depth+1)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate field %s: %w", fieldName, err)
+	if err := g.applyDependencies(st, n, result, path, depth); err != nil {
+		return nil, err
+	}
+	applyEnumLabels(n, result)
+
+	hasAdditional := n.additionalAllowed || n.additional != nil
+	if hasAdditional && g.GenerateAllFields && st.overBudget() {
+		degraded = true
+	}
+	if degraded {
+		st.degrade(path)
+	}
+
+	// Generate a few random additional properties if configured
+	numExtra := 0
+	if hasAdditional && g.GenerateAllFields && !st.overBudget() {
+		low := max(g.MinAdditional, 0)
+		numExtra = low + g.shape.Intn(max(g.MaxAdditional-low, 0)+1)
+	}
+	if n.maxProperties >= 0 {
+		numExtra = min(numExtra, n.maxProperties-len(result))
+	}
+	if g.MaxProperties > 0 {
+		numExtra = min(numExtra, g.MaxProperties-len(result))
+	}
+	if n.additional != nil && !g.fits(st, n.additional, depth+1) {
+		numExtra = 0
+	}
+
+	// minProperties is met with optional properties first, then additional ones
+	for _, prop := range n.properties {
+		if len(result) >= n.minProperties {
+			break
+		}
+		if _, present := result[prop.name]; present || missing[prop.name] || n.forbids(prop.name) || !g.fits(st, prop.node, depth+1) {
+			continue
+		}
+		value, err := g.generate(st, prop.node, childPath(path, prop.name), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result[prop.name] = value
+	}
+	numExtra = max(numExtra, n.minProperties-len(result))
+	if numExtra > 0 && n.schema.AdditionalProperties == false {
+		return nil, keywordErrorf("minProperties", "cannot reach minProperties %d without additional properties", n.minProperties)
+	}
+
+	for i := 0; i < numExtra; i++ {
+		key, err := g.propertyName(st, n, result, path, depth)
+		if err != nil {
+			if len(result) < n.minProperties {
+				return nil, fmt.Errorf("failed to generate property name: %w", err)
+			}
+			g.warnf("skipped additional property: %v", err)
+			break
+		}
+		if n.additional == nil {
+			result[key] = g.faker.Word()
+			continue
+		}
+
+		value, err := g.generate(st, n.additional, childPath(path, key), depth+1)
+		if err != nil {
+			if len(result) < n.minProperties {
+				return nil, err
 			}
-			result[fieldName] = value
+			g.warnf("skipped additional property %s: %v", key, err)
+			continue
 		}
+		result[key] = value
 	}
 
-	// Handle additionalProperties if configured
-	if schema.AdditionalProperties != nil && g.GenerateAllFields {
-		switch ap := schema.AdditionalProperties.(type) {
-		case bool:
-			if ap {
-				// Generate a few random additional properties
-				numExtra := g.rand.Intn(3)
-				for i := 0; i < numExtra; i++ {
-					key := g.faker.Word()
-					result[key] = g.faker.Word()
+	return result, nil
+}
+
+// propertyName generates the name of an additional property from
+// propertyNames, or from PropertyKeys or a word without it. Names already in
+// the object or declared as properties are generated again.
+func (g *Generator) propertyName(st *genState, n *node, result map[string]interface{}, path string, depth int) (string, error) {
+	value, err := g.retry("propertyNames", path, func() (interface{}, error) {
+		if n.propertyNames == nil {
+			if g.PropertyKeys != nil {
+				return g.PropertyKeys(g.rand, path), nil
+			}
+			return g.faker.Word(), nil
+		}
+		return g.generate(st, n.propertyNames, path, depth+1)
+	}, func(v interface{}) bool {
+		name, ok := v.(string)
+		if !ok {
+			return false
+		}
+		_, taken := result[name]
+		return !taken && n.property(name) == nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return value.(string), nil
+}
+
+// applyDependencies adds the properties and subschema constraints that
+// dependentRequired/dependentSchemas demand for the properties present
+func (g *Generator) applyDependencies(st *genState, n *node, result map[string]interface{}, path string, depth int) error {
+	applied := make(map[string]bool)
+
+	// Repeat until stable, since added properties can trigger more dependencies
+	for changed := true; changed; {
+		changed = false
+		for _, dep := range n.dependencies {
+			if _, present := result[dep.name]; !present || applied[dep.name] {
+				continue
+			}
+			applied[dep.name] = true
+
+			for _, name := range dep.required {
+				if _, present := result[name]; present {
+					continue
+				}
+				value, err := g.generateProperty(st, n, name, path, depth)
+				if err != nil {
+					return fmt.Errorf("failed to generate field %s required by %s: %w", name, dep.name, err)
 				}
+				result[name] = value
+				changed = true
 			}
-		case map[string]interface{}:
-			// AdditionalProperties is a schema
-			apBytes, _ := json.Marshal(ap)
-			apSchema, err := ParseSchema(apBytes)
-			if err == nil {
-				numExtra := g.rand.Intn(3)
-				for i := 0; i < numExtra; i++ {
-					key := g.faker.Word()
-					value, err := g.generate(apSchema, depth+1)
-					if err == nil {
-						result[key] = value
-					}
+
+			if dep.schema == nil {
+				continue
+			}
+
+			// Merge in whatever the dependent schema adds on top of what we have
+			extra, err := g.generateObject(st, dep.schema, path, depth)
+			if err != nil {
+				return fmt.Errorf("failed to generate dependent schema of %s: %w", dep.name, err)
+			}
+			for name, value := range extra.(map[string]interface{}) {
+				if _, present := result[name]; !present {
+					result[name] = value
+					changed = true
 				}
 			}
 		}
 	}
 
-	return result, nil
+	return nil
 }
 
-// generateArray generates a random array conforming to schema
-func (g *Generator) generateArray(schema *Schema, depth int) (interface{}, error) {
-	minItems := 0
-	maxItems := 5 // default
+// applyEnumLabels writes the x-enum-varnames label of every generated enum
+// value to its x-enum-label-field, replacing whatever the sibling holds so
+// code and label always agree
+func applyEnumLabels(n *node, result map[string]interface{}) {
+	for _, prop := range n.properties {
+		schema := prop.node.schema
+		value, present := result[prop.name]
+		if schema.EnumLabelField == "" || !present {
+			continue
+		}
+		for i, candidate := range schema.Enum {
+			if jsonEqual(candidate, value) {
+				result[schema.EnumLabelField] = schema.EnumVarNames[i]
+				break
+			}
+		}
+	}
+}
 
-	if schema.MinItems != nil {
-		minItems = *schema.MinItems
+// sampleOptional picks the optional properties of an object that fit under
+// MaxProperties and maxProperties, or returns nil when all of them fit
+func (g *Generator) sampleOptional(n *node) map[string]bool {
+	if !g.GenerateAllFields || (g.MaxProperties <= 0 && n.maxProperties < 0) {
+		return nil
+	}
+	limit := g.MaxProperties
+	if n.maxProperties >= 0 && (limit <= 0 || n.maxProperties < limit) {
+		limit = n.maxProperties
 	}
-	if schema.MaxItems != nil {
-		maxItems = *schema.MaxItems
+	if len(n.properties) <= limit {
+		return nil
 	}
 
-	// Ensure min <= max
-	if minItems > maxItems {
-		maxItems = minItems
+	// Properties with x-include-rate are left to their rate
+	var optional []string
+	for _, prop := range n.properties {
+		if !prop.required && prop.node.schema.IncludeRate == 0 {
+			optional = append(optional, prop.name)
+		}
+	}
+	places := max(0, limit-(len(n.properties)-len(optional)))
+
+	// Partial Fisher-Yates shuffle over the optional names
+	sampled := make(map[string]bool, places)
+	for i := 0; i < places && i < len(optional); i++ {
+		j := i + g.shape.Intn(len(optional)-i)
+		optional[i], optional[j] = optional[j], optional[i]
+		sampled[optional[i]] = true
+	}
+	return sampled
+}
+
+// generateProperty generates a value for a named property of an object node,
+// falling back to additionalProperties or a generic word for unknown names
+func (g *Generator) generateProperty(st *genState, n *node, name string, path string, depth int) (interface{}, error) {
+	propNode := n.property(name)
+	if propNode == nil {
+		propNode = n.additional
+	}
+	if propNode == nil {
+		return g.faker.Word(), nil
+	}
+	return g.generate(st, propNode, childPath(path, name), depth+1)
+}
+
+// generateArray generates a random array conforming to schema
+func (g *Generator) generateArray(st *genState, n *node, path string, depth int) (interface{}, error) {
+	length, fixed := st.lengths[n]
+	if !fixed {
+		length = g.arrayLength(n)
+
+		// Recursion that would pass the depth limit ends with the fewest items
+		if n.items != nil && !g.fits(st, n.items, depth+1) {
+			length = n.minItems
+		}
+		if n.rest != nil && !g.fits(st, n.rest, depth+1) {
+			length = min(length, max(n.minItems, len(n.tuple)))
+		}
+	}
+	if n.schema.Rectangular {
+		defer g.fixInnerLengths(st, n)()
 	}
 
-	length := minItems
-	if maxItems > minItems {
-		length = minItems + g.rand.Intn(maxItems-minItems+1)
+	// Once the time budget is spent arrays only get their minimum items,
+	// unless a rectangular array fixed their length
+	if !fixed && length > n.minItems && st.overBudget() {
+		length = n.minItems
+		st.degrade(path)
 	}
 
 	result := make([]interface{}, length)
+	seen := make(map[string]bool) // for uniqueItems
 
-	// Handle items schema
-	if schema.Items == nil {
-		// No items schema, generate arbitrary values
-		for i := 0; i < length; i++ {
-			result[i] = g.faker.Word()
+	for i := 0; i < length; i++ {
+		if st.truncateArray(path, i, n.minItems) {
+			result = result[:i]
+			break
 		}
-		return result, nil
-	}
 
-	// Items can be a single schema or an array of schemas
-	switch items := schema.Items.(type) {
-	case map[string]interface{}:
-		// Single schema for all items
-		itemsBytes, _ := json.Marshal(items)
-		itemSchema, err := ParseSchema(itemsBytes)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse items schema: %w", err)
+		itemPath := childPath(path, strconv.Itoa(i))
+		gen := func() (interface{}, error) {
+			return g.generateItem(st, n, i, itemPath, depth)
 		}
 
-		for i := 0; i < length; i++ {
-			value, err := g.generate(itemSchema, depth+1)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate array item %d: %w", i, err)
+		var value interface{}
+		var err error
+		if n.schema.UniqueItems {
+			// Only the duplicated item is regenerated, not the whole array
+			value, err = g.retry("uniqueItems", itemPath, gen, func(v interface{}) bool {
+				key, err := uniqueKey(v)
+				return err == nil && !seen[key]
+			})
+			if err == nil {
+				key, _ := uniqueKey(value)
+				seen[key] = true
 			}
-			result[i] = value
-		}
-	case []interface{}:
-		// Tuple validation - array of schemas
-		for i := 0; i < length; i++ {
-			if i < len(items) {
-				itemBytes, _ := json.Marshal(items[i])
-				itemSchema, err := ParseSchema(itemBytes)
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse items schema at index %d: %w", i, err)
-				}
-				value, err := g.generate(itemSchema, depth+1)
-				if err != nil {
-					return nil, fmt.Errorf("failed to generate array item %d: %w", i, err)
-				}
-				result[i] = value
+		} else {
+			value, err = gen()
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+
+	return result, nil
+}
+
+// arrayLength picks the length of an array between minItems and maxItems
+func (g *Generator) arrayLength(n *node) int {
+	if n.maxItems > n.minItems {
+		return n.minItems + g.shape.Intn(n.maxItems-n.minItems+1)
+	}
+	return n.minItems
+}
+
+// fixInnerLengths picks one length for every dimension of the arrays nested
+// in a rectangular array, so all rows of a matrix are equally long. It
+// returns a function restoring the previous lengths.
+func (g *Generator) fixInnerLengths(st *genState, n *node) func() {
+	if st.lengths == nil {
+		st.lengths = make(map[*node]int)
+	}
+	previous := make(map[*node]int)
+	for inner := n.items; inner != nil && (inner.items != nil || slices.Contains(inner.types, "array")); inner = inner.items {
+		if _, seen := previous[inner]; seen {
+			break // recursive item schemas
+		}
+		previous[inner] = -1
+		if length, ok := st.lengths[inner]; ok {
+			previous[inner] = length
+		}
+		st.lengths[inner] = g.arrayLength(inner)
+	}
+	return func() {
+		for inner, length := range previous {
+			if length < 0 {
+				delete(st.lengths, inner)
 			} else {
-				// Beyond tuple length, generate generic values
-				result[i] = g.faker.Word()
+				st.lengths[inner] = length
 			}
 		}
-	default:
-		return nil, fmt.Errorf("unsupported items type: %T", items)
 	}
+}
 
-	return result, nil
+// generateItem generates the array item at index i
+func (g *Generator) generateItem(st *genState, n *node, i int, path string, depth int) (interface{}, error) {
+	// No items schema, generate arbitrary values
+	if n.items == nil && n.tuple == nil {
+		return g.faker.Word(), nil
+	}
+
+	// Single schema for all items, or tuple validation
+	itemNode := n.items
+	if n.tuple != nil {
+		if i >= len(n.tuple) && n.rest != nil {
+			return g.generate(st, n.rest, path, depth+1)
+		}
+		if i >= len(n.tuple) {
+			// Beyond tuple length, generate generic values
+			g.warnf("array item %d is beyond the tuple schemas, generated a generic word", i)
+			return g.faker.Word(), nil
+		}
+		itemNode = n.tuple[i]
+	}
+
+	return g.generate(st, itemNode, path, depth+1)
 }
 
 // handleOneOf randomly selects one schema from oneOf and generates data
-func (g *Generator) handleOneOf(schema *Schema, depth int) (interface{}, error) {
-	if len(schema.OneOf) == 0 {
+func (g *Generator) handleOneOf(st *genState, n *node, path string, depth int) (interface{}, error) {
+	if len(n.oneOf) == 0 {
 		return nil, fmt.Errorf("oneOf array is empty")
 	}
 
-	// Pick a random schema
-	chosen := &schema.OneOf[g.rand.Intn(len(schema.OneOf))]
-	return g.generate(chosen, depth)
+	// Pick a random schema, one that fits under the depth limit if any does
+	branches := g.fitting(st, n.oneOf, depth)
+	chosen := branches[g.shape.Intn(len(branches))]
+	return g.generate(st, chosen, path, depth)
 }
 
 // handleAnyOf randomly selects one schema from anyOf and generates data
-func (g *Generator) handleAnyOf(schema *Schema, depth int) (interface{}, error) {
-	if len(schema.AnyOf) == 0 {
+func (g *Generator) handleAnyOf(st *genState, n *node, path string, depth int) (interface{}, error) {
+	if len(n.anyOf) == 0 {
 		return nil, fmt.Errorf("anyOf array is empty")
 	}
 
-	// Pick a random schema
-	chosen := &schema.AnyOf[g.rand.Intn(len(schema.AnyOf))]
-	return g.generate(chosen, depth)
+	// Pick a random schema, one that fits under the depth limit if any does
+	branches := g.fitting(st, n.anyOf, depth)
+	chosen := branches[g.shape.Intn(len(branches))]
+	return g.generate(st, chosen, path, depth)
 }
 
-// handleAllOf attempts to merge all schemas (simplified: use first schema for MVP)
-func (g *Generator) handleAllOf(schema *Schema, depth int) (interface{}, error) {
-	if len(schema.AllOf) == 0 {
+// handleAllOf generates from the merged allOf subschemas and checks the
+// value against each of them, retrying for constraints the merge could not
+// combine
+func (g *Generator) handleAllOf(st *genState, n *node, path string, depth int) (interface{}, error) {
+	if len(n.allOf) == 0 {
 		return nil, fmt.Errorf("allOf array is empty")
 	}
 
-	// For MVP: generate from the first schema
-	// A complete implementation would merge all constraints
-	return g.generate(&schema.AllOf[0], depth)
+	return g.retry("allOf", path, func() (interface{}, error) {
+		return g.generate(st, n.merged, path, depth)
+	}, func(v interface{}) bool {
+		for _, sub := range n.allOf {
+			if len(validateInstance(sub, v, path)) > 0 {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// warnf records a non-fatal generation warning for the current call
+func (g *Generator) warnf(format string, args ...interface{}) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
 }
 
 // randomString generates a random string of specified length using realistic words
 func (g *Generator) randomString(length int) string {
+	return g.randomText(nil, length)
+}
+
+// unicodeRunes are mixed into free-form strings by SetUnicodeText; they take
+// one to four bytes in UTF-8 and one or two units in UTF-16
+var unicodeRunes = []rune("éñüßøçåλΩжЯ漢字日本語한글😀🚀🎉𝄞")
+
+// mixUnicode replaces about a third of the characters of s with characters
+// beyond ASCII, keeping its length in code points
+func (g *Generator) mixUnicode(s string) string {
+	runes := []rune(s)
+	for i := range runes {
+		if g.rand.Intn(3) == 0 {
+			runes[i] = unicodeRunes[g.rand.Intn(len(unicodeRunes))]
+		}
+	}
+	return string(runes)
+}
+
+// randomText generates a random string of exactly length characters (runes)
+// from the words of the given locale, or from gofakeit if locale has none
+func (g *Generator) randomText(locale *Locale, length int) string {
 	if length <= 0 {
 		return ""
 	}
 
+	localized := locale != nil && len(locale.Words) > 0
+
 	// For short lengths, use letter string
-	if length <= 3 {
+	if !localized && length <= 3 {
 		return g.faker.LetterN(uint(length))
 	}
 
-	// For longer strings, generate a sentence and truncate if needed
-	result := g.faker.Word()
+	word := g.faker.Word
+	if localized {
+		word = func() string { return locale.Words[g.rand.Intn(len(locale.Words))] }
+	}
 
-	// If we need more characters, add more words
+	// Generate words until we have enough characters
+	result := []rune(word())
 	for len(result) < length {
-		result += g.faker.Word()
+		result = append(result, []rune(word())...)
 	}
 
 	// Truncate to exact length if needed
-	if len(result) > length {
-		return result[:length]
-	}
-
-	return result
+	return string(result[:length])
 }
@@ -2,8 +2,14 @@ package schemagen
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestGenerateString(t *testing.T) {
@@ -523,6 +529,14 @@ func TestInvalidSchema(t *testing.T) {
 			name:   "conflicting array length",
 			schema: `{"type": "array", "minItems": 10, "maxItems": 5}`,
 		},
+		{
+			name:   "const of another type",
+			schema: `{"type": "integer", "const": "5"}`,
+		},
+		{
+			name:   "no enum value of a declared type",
+			schema: `{"type": ["string", "null"], "enum": [1, 2]}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -536,6 +550,75 @@ func TestInvalidSchema(t *testing.T) {
 	}
 }
 
+// Test const and enum type conflicts name the value and suggest a fix
+func TestConstEnumTypeErrors(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`{"type": "integer", "const": "5"}`, `const "5" is of type string, but the schema allows [integer] (did you mean 5 without quotes?)`},
+		{`{"type": "boolean", "enum": ["false", "true"]}`, `no enum value is of a declared type, enum value 0 "false" is of type string, but the schema allows [boolean] (did you mean false without quotes?)`},
+		{`{"type": "integer", "enum": [2.5]}`, `no enum value is of a declared type, enum value 0 2.5 is of type number, but the schema allows [integer]`},
+		{`{"type": "string", "const": 5}`, `const 5 is of type integer, but the schema allows [string]`},
+	}
+
+	for _, tt := range tests {
+		schema, err := ParseSchema([]byte(tt.schema))
+		if err != nil {
+			t.Fatalf("ParseSchema() error = %v", err)
+		}
+		errs := schema.ValidateWithDetails("")
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.schema, tt.want, errs)
+		}
+	}
+
+	// Values of other types are left out with a warning while one is left
+	gen := NewGenerator().SetSeed(42)
+	for i := 0; i < 10; i++ {
+		res, err := gen.GenerateResult([]byte(`{"type": "string", "enum": [1, "a", true]}`))
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		if res.Value() != "a" || len(res.Warnings()) != 2 {
+			t.Errorf("Expected \"a\" and 2 warnings, got %v and %v", res.Value(), res.Warnings())
+		}
+	}
+
+	// Integers are numbers, and null is allowed when declared
+	schema, _ := ParseSchema([]byte(`{"type": ["number", "null"], "enum": [1, 2.5, null]}`))
+	if err := schema.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+// Test const and enum type conflicts are found in every kind of subschema
+func TestConstEnumTypeErrorsNested(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		path   string
+	}{
+		{"items", `{"type": "array", "items": {"type": "integer", "const": "5"}}`, ".items"},
+		{"prefixItems", `{"type": "array", "prefixItems": [{"type": "integer", "enum": ["5"]}]}`, ".prefixItems[0]"},
+		{"additionalProperties", `{"type": "object", "additionalProperties": {"type": "integer", "const": "5"}}`, ".additionalProperties"},
+		{"dependentSchemas", `{"type": "object", "dependentSchemas": {"a": {"type": "integer", "const": "5"}}}`, ".dependentSchemas.a"},
+		{"$ref", `{"$ref": "#/$defs/five", "$defs": {"five": {"type": "integer", "const": "5"}}}`, ".$defs.five"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator().SetSeed(42).Generate([]byte(tt.schema))
+			if err == nil {
+				t.Fatal("Expected error for const or enum of the wrong type, got nil")
+			}
+			if !strings.Contains(err.Error(), "validation error at "+tt.path+":") {
+				t.Errorf("Expected validation error at %s, got %v", tt.path, err)
+			}
+		})
+	}
+}
+
 func TestMaxDepth(t *testing.T) {
 	schema := `{
 		"type": "object",
@@ -856,6 +939,313 @@ func TestGenerateArrayTuple(t *testing.T) {
 	}
 }
 
+// Test additionalItems generates and limits the items beyond a tuple
+func TestGenerateArrayAdditionalItems(t *testing.T) {
+	tuple := `"items": [{"type": "string"}, {"type": "boolean"}]`
+
+	gen := NewGenerator().SetSeed(42)
+	result, err := gen.Generate([]byte(`{"type": "array", ` + tuple + `, "additionalItems": {"type": "integer", "minimum": 10}, "minItems": 6}`))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	arr := result.([]interface{})
+	if len(arr) < 6 {
+		t.Fatalf("Expected at least 6 items, got %d", len(arr))
+	}
+	for _, item := range arr[2:] {
+		if n, ok := item.(int64); !ok || n < 10 {
+			t.Errorf("Expected integer of at least 10 beyond the tuple, got %v", item)
+		}
+	}
+	if len(gen.warnings) > 0 {
+		t.Errorf("Unexpected warnings %v", gen.warnings)
+	}
+
+	for i := 0; i < 20; i++ {
+		result, err := gen.Generate([]byte(`{"type": "array", ` + tuple + `, "additionalItems": false, "maxItems": 10}`))
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if arr := result.([]interface{}); len(arr) > 2 {
+			t.Fatalf("Expected at most 2 items, got %v", arr)
+		}
+	}
+
+	_, err = gen.Generate([]byte(`{"type": "array", ` + tuple + `, "additionalItems": false, "minItems": 3}`))
+	if err == nil || !strings.Contains(err.Error(), "minItems (3) cannot be reached") {
+		t.Errorf("Expected minItems conflict, got %v", err)
+	}
+}
+
+// Test Draft 2020-12 tuples in prefixItems use items for the rest
+func TestGenerateArrayPrefixItems(t *testing.T) {
+	tuple := `"prefixItems": [{"type": "string"}, {"type": "boolean"}]`
+
+	gen := NewGenerator().SetSeed(42)
+	result, err := gen.Generate([]byte(`{"type": "array", ` + tuple + `, "items": {"type": "integer", "minimum": 10}, "minItems": 5}`))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	arr := result.([]interface{})
+	if len(arr) < 5 {
+		t.Fatalf("Expected at least 5 items, got %d", len(arr))
+	}
+	if _, ok := arr[0].(string); !ok {
+		t.Errorf("Expected string first, got %v", arr[0])
+	}
+	if _, ok := arr[1].(bool); !ok {
+		t.Errorf("Expected boolean second, got %v", arr[1])
+	}
+	for _, item := range arr[2:] {
+		if n, ok := item.(int64); !ok || n < 10 {
+			t.Errorf("Expected integer of at least 10 beyond the tuple, got %v", item)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		result, err := gen.Generate([]byte(`{"type": "array", ` + tuple + `, "items": false}`))
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if arr := result.([]interface{}); len(arr) > 2 {
+			t.Fatalf("Expected at most 2 items, got %v", arr)
+		}
+	}
+
+	tests := []struct {
+		schema  string
+		wantErr string
+	}{
+		{`{"type": "array", ` + tuple + `, "items": false, "minItems": 3}`, "minItems (3) cannot be reached by 2 tuple items with items false"},
+		{`{"type": "array", ` + tuple + `, "items": [{"type": "string"}]}`, "items must be a schema when prefixItems is used"},
+	}
+	for _, tt := range tests {
+		if _, err := gen.Generate([]byte(tt.schema)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Generate(%s) error = %v, want %q", tt.schema, err, tt.wantErr)
+		}
+	}
+}
+
+// Test nested arrays respect the bounds of each dimension, and x-rectangular
+// gives all arrays of a dimension the same length
+func TestGenerateMatrix(t *testing.T) {
+	cube := func(rectangular bool) string {
+		return fmt.Sprintf(`{
+			"type": "array", "minItems": 2, "maxItems": 3, "x-rectangular": %v,
+			"items": {
+				"type": "array", "minItems": 1, "maxItems": 6,
+				"items": {
+					"type": "array", "minItems": 4, "maxItems": 8,
+					"items": {"type": "number", "minimum": 0, "maximum": 1}
+				}
+			}
+		}`, rectangular)
+	}
+
+	gen := NewGenerator().SetSeed(42)
+	ragged := false
+	for i := 0; i < 20; i++ {
+		for _, rectangular := range []bool{true, false} {
+			result, err := gen.Generate([]byte(cube(rectangular)))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			planes := result.([]interface{})
+			if len(planes) < 2 || len(planes) > 3 {
+				t.Fatalf("Expected 2-3 planes, got %d", len(planes))
+			}
+			rows, cols := map[int]bool{}, map[int]bool{}
+			for _, plane := range planes {
+				plane := plane.([]interface{})
+				rows[len(plane)] = true
+				if len(plane) < 1 || len(plane) > 6 {
+					t.Fatalf("Expected 1-6 rows, got %d", len(plane))
+				}
+				for _, row := range plane {
+					row := row.([]interface{})
+					cols[len(row)] = true
+					if len(row) < 4 || len(row) > 8 {
+						t.Fatalf("Expected 4-8 columns, got %d", len(row))
+					}
+				}
+			}
+
+			if rectangular && (len(rows) != 1 || len(cols) != 1) {
+				t.Fatalf("Expected a rectangular cube, got %v", planes)
+			}
+			ragged = ragged || len(rows) > 1 || len(cols) > 1
+		}
+	}
+	if !ragged {
+		t.Error("Expected nested arrays without x-rectangular to vary in length")
+	}
+}
+
+// Test map-like objects get their entry count and key format from
+// minProperties, maxProperties and propertyNames
+func TestGenerateDictionary(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		min, max int
+		key      *regexp.Regexp
+	}{
+		{
+			name:   "uuid keys",
+			schema: `{"type": "object", "additionalProperties": {"type": "integer"}, "propertyNames": {"format": "uuid"}, "minProperties": 3, "maxProperties": 6}`,
+			min:    3, max: 6,
+			key: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`),
+		},
+		{
+			name:   "date keys",
+			schema: `{"type": "object", "additionalProperties": {"type": "number"}, "propertyNames": {"type": "string", "format": "date"}, "minProperties": 5}`,
+			min:    5, max: 5,
+			key: regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`),
+		},
+		{
+			name:   "pattern keys",
+			schema: `{"type": "object", "additionalProperties": {"type": "string"}, "propertyNames": {"pattern": "^sku-[0-9]{4}$"}, "minProperties": 2, "maxProperties": 2}`,
+			min:    2, max: 2,
+			key: regexp.MustCompile(`^sku-[0-9]{4}$`),
+		},
+		{
+			name:   "optional properties first",
+			schema: `{"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "string"}}, "additionalProperties": false, "minProperties": 2}`,
+			min:    2, max: 2,
+			key: regexp.MustCompile(`^[ab]$`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42).SetGenerateAllFields(strings.Contains(tt.schema, "maxProperties"))
+			plan, err := gen.prepare([]byte(tt.schema), "")
+			if err != nil {
+				t.Fatalf("prepare() error = %v", err)
+			}
+			for i := 0; i < 10; i++ {
+				obj, err := gen.GenerateMap([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("GenerateMap() error = %v", err)
+				}
+				if len(obj) < tt.min || len(obj) > tt.max {
+					t.Fatalf("Expected %d-%d entries, got %d", tt.min, tt.max, len(obj))
+				}
+				for key := range obj {
+					if !tt.key.MatchString(key) {
+						t.Fatalf("Unexpected key %q", key)
+					}
+				}
+				if errs := validateInstance(plan, obj, ""); len(errs) > 0 {
+					t.Fatalf("Generated %v violates the schema: %v", obj, errs[0])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDictionaryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"min over max", `{"type": "object", "minProperties": 3, "maxProperties": 2}`, "cannot be greater than maxProperties"},
+		{"required over max", `{"type": "object", "required": ["a", "b"], "maxProperties": 1}`, "less than the 2 required properties"},
+		{"closed object", `{"type": "object", "properties": {"a": {}}, "additionalProperties": false, "minProperties": 2}`, "cannot reach minProperties 2"},
+		{"too few names", `{"type": "object", "propertyNames": {"enum": ["x", "y"]}, "minProperties": 3}`, "could not satisfy propertyNames"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator().SetSeed(42).Generate([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// Test the count and names of additional properties can be configured
+func TestAdditionalPropertyCount(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"], "additionalProperties": {"type": "integer"}}`)
+
+	gen := NewGenerator().SetSeed(42).SetGenerateAllFields(true).SetAdditionalPropertyCount(5, 8).
+		SetAdditionalPropertyKeys(func(r *rand.Rand, path string) string {
+			return fmt.Sprintf("user_%d", r.Intn(1000))
+		})
+	key := regexp.MustCompile(`^user_\d+$`)
+	counts := make(map[int]bool)
+	for i := 0; i < 50; i++ {
+		obj, err := gen.GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		extra := len(obj) - 1
+		if extra < 5 || extra > 8 {
+			t.Fatalf("Expected 5 to 8 additional properties, got %d in %v", extra, obj)
+		}
+		counts[extra] = true
+		for name := range obj {
+			if name != "id" && !key.MatchString(name) {
+				t.Errorf("Unexpected key %q", name)
+			}
+		}
+	}
+	if len(counts) != 4 {
+		t.Errorf("Expected every count from 5 to 8, got %v", counts)
+	}
+
+	// Schema keywords take precedence
+	gen.SetAdditionalPropertyCount(0, 0)
+	obj, err := gen.GenerateMap([]byte(`{"type": "object", "propertyNames": {"pattern": "^k[0-9]{3}$"}, "minProperties": 2, "maxProperties": 2}`))
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	for name := range obj {
+		if !regexp.MustCompile(`^k[0-9]{3}$`).MatchString(name) {
+			t.Errorf("Expected propertyNames to name %q", name)
+		}
+	}
+	if len(obj) != 2 {
+		t.Errorf("Expected minProperties 2, got %v", obj)
+	}
+}
+
+// Test deprecated properties are left out on request unless required
+func TestSkipDeprecated(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"fullName": {"type": "string", "deprecated": true},
+			"legacyId": {"type": "integer", "deprecated": true},
+			"code": {"$ref": "#/$defs/code"}
+		},
+		"required": ["legacyId"],
+		"$defs": {"code": {"type": "string", "deprecated": true}}
+	}`)
+
+	for _, skip := range []bool{false, true} {
+		obj, err := NewGenerator().SetSeed(42).SetGenerateAllFields(true).SetSkipDeprecated(skip).GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		if _, ok := obj["legacyId"]; !ok {
+			t.Errorf("skip=%v: expected required deprecated property, got %v", skip, obj)
+		}
+		for _, name := range []string{"fullName", "code"} {
+			if _, ok := obj[name]; ok == skip {
+				t.Errorf("skip=%v: unexpected presence of %s in %v", skip, name, obj)
+			}
+		}
+		if _, ok := obj["name"]; !ok {
+			t.Errorf("skip=%v: expected name, got %v", skip, obj)
+		}
+	}
+}
+
 // Test array with invalid items type
 func TestGenerateArrayInvalidItems(t *testing.T) {
 	schema := `{
@@ -1105,6 +1495,46 @@ func TestGenerateNumberMultipleOfBounds(t *testing.T) {
 	}
 }
 
+// Test decimal multipleOf producing exact multiples
+func TestGenerateNumberDecimalMultipleOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		multiple string
+	}{
+		{"tenths", `{"type": "number", "multipleOf": 0.1, "minimum": 0, "maximum": 10}`, "0.1"},
+		{"hundredths", `{"type": "number", "multipleOf": 0.01, "minimum": -1, "maximum": 1}`, "0.01"},
+		{"exclusive bounds", `{"type": "number", "multipleOf": 0.1, "exclusiveMinimum": 0.3, "exclusiveMaximum": 0.6}`, "0.1"},
+		{"integer with fractional multiple", `{"type": "integer", "multipleOf": 2.5, "minimum": 0, "maximum": 100}`, "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			multiple, _ := new(big.Rat).SetString(tt.multiple)
+			gen := NewGenerator().SetSeed(42)
+			for i := 0; i < 50; i++ {
+				result, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				data, _ := json.Marshal(result)
+				value, ok := new(big.Rat).SetString(string(data))
+				if !ok {
+					t.Fatalf("cannot read %s as a number", data)
+				}
+				if !new(big.Rat).Quo(value, multiple).IsInt() {
+					t.Errorf("%s is not a multiple of %s", data, tt.multiple)
+				}
+			}
+		})
+	}
+
+	_, err := NewGenerator().SetSeed(42).Generate([]byte(`{"type": "integer", "multipleOf": 5, "minimum": 1, "maximum": 4}`))
+	if err == nil || !strings.Contains(err.Error(), "no multiple of 5") {
+		t.Errorf("expected error for a range without multiples, got %v", err)
+	}
+}
+
 // Test array items parsing error
 func TestGenerateArrayItemsParseError(t *testing.T) {
 	// Create invalid items that will fail to parse
@@ -1141,3 +1571,548 @@ func TestGenerateObjectFieldError(t *testing.T) {
 		t.Error("Expected error for unsupported field type")
 	}
 }
+
+// Test GenerateMap returns a typed object
+func TestGenerateMap(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {"name": {"type": "string"}},
+		"required": ["name"]
+	}`
+
+	gen := NewGenerator().SetSeed(42)
+	obj, err := gen.GenerateMap([]byte(schema))
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+
+	if _, exists := obj["name"]; !exists {
+		t.Error("Required field 'name' is missing")
+	}
+}
+
+// Test GenerateMap rejects non-object root schemas
+func TestGenerateMapWrongRootType(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"declared string", `{"type": "string"}`},
+		{"inferred array", `{"items": {"type": "string"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			if _, err := gen.GenerateMap([]byte(tt.schema)); err == nil {
+				t.Error("Expected error for non-object root schema")
+			}
+		})
+	}
+}
+
+// Test GenerateMap and GenerateSlice generate the expected type of a
+// multi-type root whatever the seed
+func TestGenerateRootOfSeveralTypes(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		gen := NewGenerator().SetSeed(seed)
+		obj, err := gen.GenerateMap([]byte(`{"type": ["object", "null"], "properties": {"next": {"$ref": "#"}}}`))
+		if err != nil || obj == nil {
+			t.Errorf("Seed %d: GenerateMap() = %v, %v", seed, obj, err)
+		}
+		arr, err := gen.GenerateSlice([]byte(`{"type": ["string", "array"], "items": {"type": "integer"}}`))
+		if err != nil || arr == nil {
+			t.Errorf("Seed %d: GenerateSlice() = %v, %v", seed, arr, err)
+		}
+	}
+}
+
+// Test GenerateSlice returns a typed array
+func TestGenerateSlice(t *testing.T) {
+	schema := `{"type": "array", "items": {"type": "integer"}, "minItems": 2}`
+
+	gen := NewGenerator().SetSeed(42)
+	arr, err := gen.GenerateSlice([]byte(schema))
+	if err != nil {
+		t.Fatalf("GenerateSlice() error = %v", err)
+	}
+
+	if len(arr) < 2 {
+		t.Errorf("Expected at least 2 items, got %d", len(arr))
+	}
+}
+
+// Test GenerateSlice rejects non-array root schemas
+func TestGenerateSliceWrongRootType(t *testing.T) {
+	gen := NewGenerator().SetSeed(42)
+	if _, err := gen.GenerateSlice([]byte(`{"type": ["object", "null"]}`)); err == nil {
+		t.Error("Expected error for non-array root schema")
+	}
+}
+
+// Test GenerateInto decodes documents into Go values
+func TestGenerateInto(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type user struct {
+		ID      int64    `json:"id"`
+		Email   string   `json:"email"`
+		Tags    []string `json:"tags"`
+		Address *address `json:"address"`
+		Score   float64  `json:"score"`
+	}
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 9007199254740993, "maximum": 9007199254740993},
+			"email": {"type": "string", "format": "email"},
+			"tags": {"type": "array", "minItems": 1, "items": {"type": "string"}},
+			"address": {"type": "object", "properties": {"city": {"type": "string", "minLength": 1}}, "required": ["city"]},
+			"score": {"type": "number", "minimum": 0, "maximum": 1},
+			"extra": {"type": "string"}
+		},
+		"required": ["id", "email", "tags", "address", "score", "extra"]
+	}`)
+
+	var u user
+	if err := NewGenerator().SetSeed(42).GenerateInto(schema, &u); err != nil {
+		t.Fatalf("GenerateInto() error = %v", err)
+	}
+	if u.ID != 9007199254740993 || !strings.Contains(u.Email, "@") || len(u.Tags) == 0 || u.Address == nil || u.Address.City == "" {
+		t.Errorf("GenerateInto() filled %+v", u)
+	}
+
+	if err := NewGenerator().GenerateInto(schema, u); err == nil {
+		t.Error("Expected error for a non-pointer target")
+	}
+	var id string
+	if err := NewGenerator().GenerateInto([]byte(`{"type": "integer"}`), &id); err == nil {
+		t.Error("Expected error for a target of the wrong type")
+	}
+}
+
+// Test Draft-07 dependencies and their 2019-09+ replacements
+func TestGenerateDependencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{
+			name: "dependencies property list",
+			schema: `{
+				"type": "object",
+				"properties": {
+					"credit_card": {"type": "string"},
+					"billing_address": {"type": "string"}
+				},
+				"required": ["credit_card"],
+				"dependencies": {"credit_card": ["billing_address"]}
+			}`,
+		},
+		{
+			name: "dependencies schema",
+			schema: `{
+				"type": "object",
+				"properties": {"credit_card": {"type": "string"}},
+				"required": ["credit_card"],
+				"dependencies": {
+					"credit_card": {
+						"properties": {"billing_address": {"type": "string", "minLength": 5}},
+						"required": ["billing_address"]
+					}
+				}
+			}`,
+		},
+		{
+			name: "dependentRequired",
+			schema: `{
+				"type": "object",
+				"properties": {
+					"credit_card": {"type": "string"},
+					"billing_address": {"type": "string"}
+				},
+				"required": ["credit_card"],
+				"dependentRequired": {"credit_card": ["billing_address"]}
+			}`,
+		},
+		{
+			name: "dependentSchemas with chained dependency",
+			schema: `{
+				"type": "object",
+				"properties": {
+					"credit_card": {"type": "string"},
+					"billing_address": {"type": "string"},
+					"country": {"type": "string", "enum": ["DE", "FR"]}
+				},
+				"required": ["credit_card"],
+				"dependentSchemas": {"credit_card": {"required": ["billing_address"]}},
+				"dependentRequired": {"billing_address": ["country"]}
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			result, err := gen.Generate([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			obj := result.(map[string]interface{})
+			if _, exists := obj["billing_address"]; !exists {
+				t.Errorf("Expected billing_address required by credit_card, got %v", obj)
+			}
+
+			schema, _ := ParseSchema([]byte(tt.schema))
+			plan, err := compile(schema)
+			if err != nil {
+				t.Fatalf("compile() error = %v", err)
+			}
+			if errs := validateInstance(plan, result, ""); len(errs) > 0 {
+				t.Errorf("Generated value does not validate: %v", errs)
+			}
+		})
+	}
+}
+
+// Test that a false dependency schema keeps the property out
+func TestGenerateDependenciesForbidden(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"legacy": {"type": "string"},
+			"name": {"type": "string"}
+		},
+		"dependencies": {"legacy": false}
+	}`
+
+	gen := NewGenerator().SetSeed(42).SetGenerateAllFields(true)
+	result, err := gen.Generate([]byte(schema))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	obj := result.(map[string]interface{})
+	if _, exists := obj["legacy"]; exists {
+		t.Error("Expected legacy to be left out")
+	}
+	if _, exists := obj["name"]; !exists {
+		t.Error("Expected name to be generated")
+	}
+}
+
+// Test invalid dependencies values
+func TestInvalidDependencies(t *testing.T) {
+	tests := []string{
+		`{"type": "object", "dependencies": {"a": 1}}`,
+		`{"type": "object", "dependencies": {"a": ["b", 2]}}`,
+	}
+
+	for _, schema := range tests {
+		if _, err := NewGenerator().Generate([]byte(schema)); err == nil {
+			t.Errorf("Expected error for %s", schema)
+		}
+	}
+}
+
+// Test that required names without a property schema are generated
+func TestGenerateRequiredWithoutProperty(t *testing.T) {
+	gen := NewGenerator().SetSeed(42)
+	result, err := gen.Generate([]byte(`{"type": "object", "required": ["id"]}`))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if _, exists := result.(map[string]interface{})["id"]; !exists {
+		t.Error("Required field 'id' is missing")
+	}
+}
+
+// shapeOf replaces every scalar in a document with its JSON type
+func shapeOf(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		shape := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			shape[k] = shapeOf(item)
+		}
+		return shape
+	case []interface{}:
+		shape := make([]interface{}, len(v))
+		for i, item := range v {
+			shape[i] = shapeOf(item)
+		}
+		return shape
+	default:
+		return jsonTypeOf(v)
+	}
+}
+
+// Test structure and value seeds vary independently
+func TestStructureAndValueSeeds(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"id": {"type": ["string", "integer"]},
+			"contact": {"oneOf": [
+				{"type": "object", "properties": {"email": {"type": "string", "format": "email"}}, "required": ["email"]},
+				{"type": "object", "properties": {"phone": {"type": "string"}}, "required": ["phone"]}
+			]}
+		},
+		"required": ["tags", "id", "contact"]
+	}`)
+
+	generate := func(structureSeed, valueSeed int64) interface{} {
+		result, err := NewGenerator().SetStructureSeed(structureSeed).SetValueSeed(valueSeed).Generate(schema)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		return result
+	}
+
+	base := generate(1, 1)
+
+	// Same structure seed: same shape, different values
+	other := generate(1, 2)
+	if !reflect.DeepEqual(shapeOf(base), shapeOf(other)) {
+		t.Errorf("Expected the same shape.\nGot:\n%v\n%v", shapeOf(base), shapeOf(other))
+	}
+	if reflect.DeepEqual(base, other) {
+		t.Error("Expected different values for a different value seed")
+	}
+
+	// Same value seed: some structure seed changes the shape
+	changed := false
+	for seed := int64(2); seed < 10 && !changed; seed++ {
+		changed = !reflect.DeepEqual(shapeOf(base), shapeOf(generate(seed, 1)))
+	}
+	if !changed {
+		t.Error("Expected a different structure seed to change the shape")
+	}
+
+	// SetSeed sets both
+	if !reflect.DeepEqual(base, generate(1, 1)) {
+		t.Error("Expected identical documents for identical seeds")
+	}
+	gen := NewGenerator().SetSeed(5)
+	if gen.Seed != 5 || gen.StructureSeed != 5 {
+		t.Errorf("Expected SetSeed to set both seeds, got %d and %d", gen.Seed, gen.StructureSeed)
+	}
+}
+
+// Test wide objects are sampled down to the cap while a batch covers them
+func TestSetMaxPropertiesPerObject(t *testing.T) {
+	properties := make(map[string]interface{})
+	for i := 0; i < 200; i++ {
+		properties[fmt.Sprintf("field%03d", i)] = map[string]interface{}{"type": "integer"}
+	}
+	schemaJSON, _ := json.Marshal(map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"field000", "field199"},
+	})
+
+	batch := func() []map[string]interface{} {
+		gen := NewGenerator().SetSeed(42).SetGenerateAllFields(true).SetMaxPropertiesPerObject(10)
+		var docs []map[string]interface{}
+		for i := 0; i < 50; i++ {
+			doc, err := gen.GenerateMap(schemaJSON)
+			if err != nil {
+				t.Fatalf("GenerateMap() error = %v", err)
+			}
+			docs = append(docs, doc)
+		}
+		return docs
+	}
+
+	docs := batch()
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		if len(doc) != 10 {
+			t.Fatalf("Expected 10 properties, got %d", len(doc))
+		}
+		if _, ok := doc["field000"]; !ok {
+			t.Fatal("Expected required property field000")
+		}
+		if _, ok := doc["field199"]; !ok {
+			t.Fatal("Expected required property field199")
+		}
+		for key := range doc {
+			seen[key] = true
+		}
+	}
+	if len(seen) < 150 {
+		t.Errorf("Expected the batch to cover most properties, got %d", len(seen))
+	}
+	if !reflect.DeepEqual(docs, batch()) {
+		t.Error("Expected the same sample for the same seed")
+	}
+}
+
+// Test the schema's maxProperties bounds the sample, alone and under a
+// smaller or larger cap
+func TestSampleOptionalMaxProperties(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {"a": {"type": "integer"}, "b": {"type": "integer"}, "c": {"type": "integer"}, "d": {"type": "integer"}, "e": {"type": "integer"}},
+		"required": ["a"],
+		"maxProperties": 2
+	}`)
+	tests := []struct {
+		name string
+		cap  int
+	}{
+		{"maxProperties only", 0},
+		{"larger cap", 3},
+		{"smaller cap", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42).SetGenerateAllFields(true).SetMaxPropertiesPerObject(tt.cap)
+			want := 2
+			if tt.cap == 1 {
+				want = 1
+			}
+			for i := 0; i < 20; i++ {
+				data, err := gen.GenerateBytes(schema)
+				if err != nil {
+					t.Fatalf("GenerateBytes() error = %v", err)
+				}
+				errs, err := ValidateInstance(schema, data)
+				if err != nil || len(errs) > 0 {
+					t.Fatalf("ValidateInstance(%s) = %v, %v", data, errs, err)
+				}
+				var doc map[string]interface{}
+				json.Unmarshal(data, &doc)
+				if len(doc) != want {
+					t.Fatalf("Expected %d properties, got %s", want, data)
+				}
+			}
+		})
+	}
+}
+
+// Test enum labels are written to their sibling field and agree with the code
+func TestEnumLabels(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"status": {"enum": ["A", "I", "P"], "x-enum-varnames": ["Active", "Inactive", "Pending"], "x-enum-label-field": "statusLabel"},
+			"statusLabel": {"type": "string"},
+			"level": {"enum": [1, 2], "x-enum-varnames": ["Low", "High"], "x-enum-label-field": "levelName"}
+		},
+		"required": ["status", "statusLabel"]
+	}`)
+	labels := map[interface{}]string{"A": "Active", "I": "Inactive", "P": "Pending"}
+
+	gen := NewGenerator().SetSeed(42)
+	for i := 0; i < 30; i++ {
+		doc, err := gen.GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		if doc["statusLabel"] != labels[doc["status"]] {
+			t.Fatalf("Label %v does not match status %v", doc["statusLabel"], doc["status"])
+		}
+		if _, ok := doc["levelName"]; ok {
+			t.Fatal("Expected no label for an absent property")
+		}
+	}
+
+	doc, err := NewGenerator().SetSeed(42).SetGenerateAllFields(true).GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if want := map[bool]string{true: "Low", false: "High"}[jsonEqual(doc["level"], 1)]; doc["levelName"] != want {
+		t.Errorf("Expected level name %q, got %v", want, doc["levelName"])
+	}
+
+	for _, invalid := range []string{
+		`{"enum": ["A", "B"], "x-enum-varnames": ["Active"]}`,
+		`{"enum": ["A"], "x-enum-label-field": "label"}`,
+	} {
+		if _, err := NewGenerator().Generate([]byte(invalid)); err == nil {
+			t.Errorf("Expected error for %s", invalid)
+		}
+	}
+}
+
+// Test non-ASCII text keeping lengths in code points
+func TestUnicodeText(t *testing.T) {
+	schema := []byte(`{"type": "string", "minLength": 5, "maxLength": 12}`)
+	gen := NewGenerator().SetSeed(42).SetUnicodeText(true)
+
+	multiByte := 0
+	for i := 0; i < 50; i++ {
+		result, err := gen.Generate(schema)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		s := result.(string)
+		if !utf8.ValidString(s) {
+			t.Fatalf("invalid UTF-8: %q", s)
+		}
+		if n := utf8.RuneCountInString(s); n < 5 || n > 12 {
+			t.Errorf("Expected 5 to 12 code points, got %d in %q", n, s)
+		}
+		if len(s) > utf8.RuneCountInString(s) {
+			multiByte++
+		}
+	}
+	if multiByte == 0 {
+		t.Error("Expected strings with characters beyond ASCII")
+	}
+
+	result, _ := NewGenerator().SetSeed(42).Generate(schema)
+	if s := result.(string); len(s) != utf8.RuneCountInString(s) {
+		t.Errorf("Expected ASCII text by default, got %q", s)
+	}
+}
+
+// Test enum values that break the rest of the schema are never picked
+func TestEnumFilteredBySchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		allowed []interface{}
+	}{
+		{"pattern", `{"type": "string", "pattern": "^[a-z]+$", "enum": ["A1", "a", "b2"]}`, []interface{}{"a"}},
+		{"minimum", `{"type": "integer", "minimum": 4, "enum": [1, 5, 10]}`, []interface{}{float64(5), float64(10)}},
+		{"maxLength", `{"type": "string", "maxLength": 2, "enum": ["ab", "abc", "x"]}`, []interface{}{"ab", "x"}},
+		{"unique items", `{"type": "array", "uniqueItems": true, "minItems": 1, "maxItems": 5, "items": {"type": "string", "maxLength": 1, "enum": ["abc", "a", "b"]}}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			for i := 0; i < 30; i++ {
+				result, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if items, ok := result.([]interface{}); ok {
+					if len(items) > 2 {
+						t.Fatalf("Expected at most 2 unique items, got %v", items)
+					}
+					continue
+				}
+				found := false
+				for _, v := range tt.allowed {
+					found = found || jsonEqual(v, result)
+				}
+				if !found {
+					t.Fatalf("Expected one of %v, got %v", tt.allowed, result)
+				}
+			}
+		})
+	}
+
+	_, err := NewGenerator().SetSeed(42).Generate([]byte(`{"type": "integer", "minimum": 10, "enum": [1, 2]}`))
+	if err == nil || !strings.Contains(err.Error(), "no enum value satisfies the schema") {
+		t.Errorf("Expected an error when no enum value fits, got %v", err)
+	}
+}
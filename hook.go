@@ -0,0 +1,76 @@
+package schemagen
+
+import "errors"
+
+// ErrVeto is returned by a hook's AfterGenerate to reject a value, which is
+// then generated again within the attempt budget, see SetMaxAttempts
+var ErrVeto = errors.New("value vetoed by a hook")
+
+// HookNode describes a value being generated
+type HookNode struct {
+	Schema *Schema
+	Path   string // JSON Pointer of the value, "" for the document root
+	Depth  int
+}
+
+// Hook is called around the generation of every value, to log, change or
+// veto values without changing the generator. An error other than ErrVeto
+// stops generation and is reported at the value's path.
+type Hook interface {
+	// BeforeGenerate is called before the value is generated
+	BeforeGenerate(node HookNode) error
+
+	// AfterGenerate returns the value to use in place of the generated one
+	AfterGenerate(node HookNode, value interface{}) (interface{}, error)
+}
+
+// AfterHookFunc is a Hook that only changes or vetoes generated values
+type AfterHookFunc func(node HookNode, value interface{}) (interface{}, error)
+
+// BeforeGenerate does nothing
+func (f AfterHookFunc) BeforeGenerate(HookNode) error { return nil }
+
+// AfterGenerate calls f(node, value)
+func (f AfterHookFunc) AfterGenerate(node HookNode, value interface{}) (interface{}, error) {
+	return f(node, value)
+}
+
+// AddHook adds a hook to the pipeline. Hooks are called in the order they
+// were added, each AfterGenerate getting the value the previous one
+// returned. Values set by overrides pass through the hooks too. A value
+// with composition keywords such as oneOf is seen twice at the same path:
+// for its schema and for the branch chosen.
+func (g *Generator) AddHook(h Hook) *Generator {
+	g.hooks = append(g.hooks, h)
+	return g
+}
+
+// hooked runs gen, which generates the value of a node, within the hook
+// pipeline
+func (g *Generator) hooked(n *node, path string, depth int, gen func() (interface{}, error)) (interface{}, error) {
+	info := HookNode{Schema: n.schema, Path: path, Depth: depth}
+	vetoed := false
+	return g.retry("hook", path, func() (interface{}, error) {
+		vetoed = false
+		for _, h := range g.hooks {
+			if err := h.BeforeGenerate(info); err != nil {
+				return nil, err
+			}
+		}
+		value, err := gen()
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range g.hooks {
+			value, err = h.AfterGenerate(info, value)
+			if errors.Is(err, ErrVeto) {
+				vetoed = true
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}, func(interface{}) bool { return !vetoed })
+}
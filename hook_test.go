@@ -0,0 +1,85 @@
+package schemagen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recordingHook records the paths it sees
+type recordingHook struct {
+	before, after []string
+}
+
+func (h *recordingHook) BeforeGenerate(node HookNode) error {
+	h.before = append(h.before, node.Path)
+	return nil
+}
+
+func (h *recordingHook) AfterGenerate(node HookNode, value interface{}) (interface{}, error) {
+	h.after = append(h.after, node.Path)
+	return value, nil
+}
+
+// Test hooks see, change and veto values
+func TestAddHook(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 3},
+			"counts": {"type": "array", "minItems": 5, "items": {"type": "integer", "minimum": 0, "maximum": 100}}
+		},
+		"required": ["name", "counts"]
+	}`)
+
+	rec := &recordingHook{}
+	gen := NewGenerator().SetSeed(42).
+		AddHook(rec).
+		AddHook(AfterHookFunc(func(node HookNode, value interface{}) (interface{}, error) {
+			if s, ok := value.(string); ok {
+				return strings.ToUpper(s), nil
+			}
+			if n, ok := value.(int64); ok && n%2 != 0 {
+				return nil, ErrVeto
+			}
+			return value, nil
+		}))
+
+	result, err := gen.GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if name := result["name"].(string); name != strings.ToUpper(name) {
+		t.Errorf("expected the name changed by the hook, got %q", name)
+	}
+	for _, n := range result["counts"].([]interface{}) {
+		if n.(int64)%2 != 0 {
+			t.Errorf("expected vetoed odd counts to be generated again, got %v", n)
+		}
+	}
+	if rec.before[0] != "" || rec.after[len(rec.after)-1] != "" || !strings.Contains(strings.Join(rec.after, ","), "/counts/4") {
+		t.Errorf("expected hooks around every value, got %v", rec.after)
+	}
+
+	// Errors stop generation at the value's path
+	failure := errors.New("audit failed")
+	gen = NewGenerator().SetSeed(42).AddHook(AfterHookFunc(func(node HookNode, value interface{}) (interface{}, error) {
+		if node.Path == "/name" {
+			return nil, failure
+		}
+		return value, nil
+	}))
+	_, err = gen.Generate(schema)
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Path != "/name" || !errors.Is(err, failure) {
+		t.Errorf("expected the hook error at /name, got %v", err)
+	}
+
+	// Vetoing every value spends the attempt budget
+	gen = NewGenerator().SetSeed(42).SetMaxAttempts(3).AddHook(AfterHookFunc(func(HookNode, interface{}) (interface{}, error) {
+		return nil, ErrVeto
+	}))
+	if _, err := gen.Generate([]byte(`{"type": "string"}`)); err == nil || gen.retries.ByKeyword["hook"] != 2 {
+		t.Errorf("expected the veto to exhaust the budget, got %v after %v", err, gen.retries)
+	}
+}
@@ -0,0 +1,266 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// integerBounds holds the exact values of integer bounds that float64 cannot
+// represent, such as 9007199254740993. The float fields of the Schema hold
+// the nearest float64.
+type integerBounds struct {
+	minimum, maximum, exclusiveMinimum, exclusiveMaximum *int64
+}
+
+// unmarshalSchema decodes a schema, keeping the exact value of integer
+// bounds beyond 2^53. Sources without such long integers are decoded once.
+func unmarshalSchema(data []byte, s *Schema) error {
+	if err := json.Unmarshal(data, s); err != nil {
+		return err
+	}
+	if !hasLongInteger(data) {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	s.setIntegerBounds(value)
+	return nil
+}
+
+// hasLongInteger reports whether data holds a run of 16 or more digits, as
+// every integer beyond 2^53 does
+func hasLongInteger(data []byte) bool {
+	run := 0
+	for _, b := range data {
+		if b < '0' || b > '9' {
+			run = 0
+			continue
+		}
+		if run++; run >= 16 {
+			return true
+		}
+	}
+	return false
+}
+
+// setIntegerBounds records the exact integer bounds of a schema and its
+// subschemas from the schema decoded with json.Number. Subschemas held as
+// generic values keep their json.Number values, so they are exact too when
+// compiled.
+func (s *Schema) setIntegerBounds(value interface{}) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || s == nil {
+		return
+	}
+
+	exact := func(key string) *int64 {
+		n, ok := obj[key].(json.Number)
+		if !ok {
+			return nil
+		}
+		i, err := n.Int64()
+		if err != nil || (i >= -maxSafeNumber && i <= maxSafeNumber) {
+			return nil
+		}
+		return &i
+	}
+	bounds := integerBounds{
+		minimum:          exact("minimum"),
+		maximum:          exact("maximum"),
+		exclusiveMinimum: exact("exclusiveMinimum"),
+		exclusiveMaximum: exact("exclusiveMaximum"),
+	}
+	if bounds != (integerBounds{}) {
+		s.ints = &bounds
+	}
+
+	for key, schemas := range map[string]map[string]*Schema{
+		"properties":       s.Properties,
+		"dependentSchemas": s.DependentSchemas,
+		"definitions":      s.Definitions,
+		"$defs":            s.Defs,
+	} {
+		members := asObject(obj[key])
+		for name, sub := range schemas {
+			sub.setIntegerBounds(members[name])
+		}
+	}
+	for key, schemas := range map[string][]Schema{"allOf": s.AllOf, "anyOf": s.AnyOf, "oneOf": s.OneOf} {
+		items, _ := obj[key].([]interface{})
+		for i := range schemas {
+			if i < len(items) {
+				schemas[i].setIntegerBounds(items[i])
+			}
+		}
+	}
+	s.PropertyNames.setIntegerBounds(obj["propertyNames"])
+	s.Not.setIntegerBounds(obj["not"])
+
+	if s.Items != nil {
+		s.Items = obj["items"]
+	}
+	if s.PrefixItems != nil {
+		s.PrefixItems, _ = obj["prefixItems"].([]interface{})
+	}
+	if s.AdditionalItems != nil {
+		s.AdditionalItems = obj["additionalItems"]
+	}
+	if s.AdditionalProperties != nil {
+		s.AdditionalProperties = obj["additionalProperties"]
+	}
+	if s.Dependencies != nil {
+		s.Dependencies = asObject(obj["dependencies"])
+	}
+}
+
+// integerRange returns the integers a schema's bounds allow as [lo, hi],
+// using the exact value of bounds beyond 2^53. Ends without a bound are
+// math.MinInt64 and math.MaxInt64; ok is false when no integer fits.
+func (s *Schema) integerRange() (lo, hi int64, ok bool) {
+	b := s.ints
+	if b == nil {
+		b = &integerBounds{}
+	}
+	lo, hi = math.MinInt64, math.MaxInt64
+
+	if s.Minimum != nil {
+		lo = exactOr(b.minimum, math.Ceil(*s.Minimum))
+	}
+	if s.ExclusiveMinimum != nil {
+		v := exactOr(b.exclusiveMinimum, math.Floor(*s.ExclusiveMinimum))
+		if v == math.MaxInt64 {
+			return 0, 0, false
+		}
+		lo = max(lo, v+1)
+	}
+	if s.Maximum != nil {
+		hi = exactOr(b.maximum, math.Floor(*s.Maximum))
+	}
+	if s.ExclusiveMaximum != nil {
+		v := exactOr(b.exclusiveMaximum, math.Ceil(*s.ExclusiveMaximum))
+		if v == math.MinInt64 {
+			return 0, 0, false
+		}
+		hi = min(hi, v-1)
+	}
+	return lo, hi, lo <= hi
+}
+
+// mergeIntegerBounds returns the exact bounds of a schema that satisfies
+// both a and b, which has the tighter of each of their bounds
+func mergeIntegerBounds(a, b *Schema) *integerBounds {
+	if a.ints == nil && b.ints == nil {
+		return nil
+	}
+	ea, eb := integerBounds{}, integerBounds{}
+	if a.ints != nil {
+		ea = *a.ints
+	}
+	if b.ints != nil {
+		eb = *b.ints
+	}
+
+	tighter := func(xa, xb *int64, fa, fb *float64, larger bool) *int64 {
+		switch {
+		case fa == nil:
+			return xb
+		case fb == nil:
+			return xa
+		}
+		va, vb := exactOr(xa, *fa), exactOr(xb, *fb)
+		if va != vb && (va > vb) == larger || va == vb && xa != nil {
+			return xa
+		}
+		return xb
+	}
+	merged := integerBounds{
+		minimum:          tighter(ea.minimum, eb.minimum, a.Minimum, b.Minimum, true),
+		maximum:          tighter(ea.maximum, eb.maximum, a.Maximum, b.Maximum, false),
+		exclusiveMinimum: tighter(ea.exclusiveMinimum, eb.exclusiveMinimum, a.ExclusiveMinimum, b.ExclusiveMinimum, true),
+		exclusiveMaximum: tighter(ea.exclusiveMaximum, eb.exclusiveMaximum, a.ExclusiveMaximum, b.ExclusiveMaximum, false),
+	}
+	if merged == (integerBounds{}) {
+		return nil
+	}
+	return &merged
+}
+
+// exactOr returns the exact bound when there is one, and f otherwise
+func exactOr(exact *int64, f float64) int64 {
+	if exact != nil {
+		return *exact
+	}
+	return clampInt64(f)
+}
+
+// clampInt64 converts a whole float to int64, saturating outside its range
+func clampInt64(f float64) int64 {
+	switch {
+	case f >= math.MaxInt64: // 2^63, the float nearest to math.MaxInt64
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// generateInteger generates an integer with int64 arithmetic, so values and
+// bounds beyond 2^53 are exact. low and high are the range numberBounds gives,
+// which fills ends the schema leaves open.
+func (g *Generator) generateInteger(schema *Schema, low, high float64, openMin, openMax bool) (interface{}, error) {
+	lo, hi, ok := schema.integerRange()
+	if !ok {
+		return nil, fmt.Errorf("no integer lies within the bounds")
+	}
+	if openMin {
+		lo = clampInt64(low)
+	}
+	if openMax {
+		hi = clampInt64(high)
+	}
+	if lo > hi {
+		return nil, fmt.Errorf("minimum (%d) is greater than maximum (%d)", lo, hi)
+	}
+
+	var result int64
+	if (openMin || openMax) && g.OpenRange.Mode == OpenRangeExponential {
+		result = clampInt64(g.sampleOpenRange(low, high, openMin, openMax, true))
+	} else {
+		result = lo + int64(g.uint64n(uint64(hi)-uint64(lo)))
+	}
+	result = min(max(result, lo), hi)
+
+	// Handle multipleOf constraint
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		// The smallest whole multiple of p/q is p
+		step := new(big.Rat).SetInt(decimalRat(*schema.MultipleOf).Num())
+		multiple, ok := multipleBetween(new(big.Rat).SetInt64(result), step,
+			new(big.Rat).SetInt64(lo), new(big.Rat).SetInt64(hi))
+		if !ok {
+			return nil, keywordErrorf("multipleOf", "no multiple of %v between %d and %d", *schema.MultipleOf, lo, hi)
+		}
+		result = multiple.Num().Int64()
+	}
+	return result, nil
+}
+
+// uint64n returns a value in [0, n], drawn from the value seed
+func (g *Generator) uint64n(n uint64) uint64 {
+	if n < math.MaxInt64 {
+		return uint64(g.rand.Int63n(int64(n) + 1))
+	}
+	// Ranges wider than int63: reject the rare draws beyond n
+	for {
+		if v := g.rand.Uint64(); v <= n {
+			return v
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package schemagen
+
+import (
+	"math"
+	"testing"
+)
+
+// Test integer bounds beyond 2^53 and near the int64 limits are met exactly
+func TestGenerateIntegerExactBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		lo, hi int64
+	}{
+		{"int64 limits", `{"type": "integer", "minimum": 9223372036854775800, "maximum": 9223372036854775807}`, math.MaxInt64 - 7, math.MaxInt64},
+		{"negative limit", `{"type": "integer", "minimum": -9223372036854775808, "maximum": -9223372036854775806}`, math.MinInt64, math.MinInt64 + 2},
+		{"full range", `{"type": "integer", "minimum": -9223372036854775808, "maximum": 9223372036854775807}`, math.MinInt64, math.MaxInt64},
+		{"beyond 2^53", `{"type": "integer", "minimum": 9007199254740993, "maximum": 9007199254740995}`, 9007199254740993, 9007199254740995},
+		{"exclusive", `{"type": "integer", "exclusiveMinimum": 9007199254740992, "exclusiveMaximum": 9007199254740994}`, 9007199254740993, 9007199254740993},
+		{"allOf", `{"allOf": [{"type": "integer", "minimum": 9007199254740993}, {"maximum": 9007199254740993}]}`, 9007199254740993, 9007199254740993},
+		{"multipleOf", `{"type": "integer", "minimum": 9007199254740993, "maximum": 9007199254741003, "multipleOf": 5}`, 9007199254740995, 9007199254741000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			for i := 0; i < 50; i++ {
+				result, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				v, ok := result.(int64)
+				if !ok {
+					t.Fatalf("Expected int64, got %T", result)
+				}
+				if v < tt.lo || v > tt.hi {
+					t.Fatalf("Expected value in [%d, %d], got %d", tt.lo, tt.hi, v)
+				}
+			}
+		})
+	}
+}
+
+// Test exact bounds reach subschemas held as generic values and references
+func TestGenerateIntegerExactSubschemas(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"ids": {"type": "array", "minItems": 2, "items": {"type": "integer", "minimum": 9007199254740993, "maximum": 9007199254740993}},
+			"ref": {"$ref": "#/$defs/big"},
+			"tags": {"type": "object", "minProperties": 1, "additionalProperties": {"type": "integer", "minimum": 9007199254740993, "maximum": 9007199254740993}}
+		},
+		"required": ["ids", "ref", "tags"],
+		"$defs": {"big": {"type": "integer", "minimum": 9007199254740993, "maximum": 9007199254740993}}
+	}`)
+
+	result, err := NewGenerator().SetSeed(42).GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	const want = int64(9007199254740993)
+	for _, id := range result["ids"].([]interface{}) {
+		if id != want {
+			t.Errorf("items: expected %d, got %v", want, id)
+		}
+	}
+	if result["ref"] != want {
+		t.Errorf("$ref: expected %d, got %v", want, result["ref"])
+	}
+	for key, v := range result["tags"].(map[string]interface{}) {
+		if v != want {
+			t.Errorf("additionalProperties %s: expected %d, got %v", key, want, v)
+		}
+	}
+}
+
+// Test x-sequence starting beyond 2^53
+func TestSequenceExactStart(t *testing.T) {
+	schema := []byte(`{"type": "integer", "x-sequence": true, "minimum": 9007199254740993, "maximum": 9007199254740994}`)
+	values, err := NewGenerator().SetSeed(42).GenerateRelatedN(schema, 2)
+	if err != nil {
+		t.Fatalf("GenerateRelatedN() error = %v", err)
+	}
+	if values[0] != int64(9007199254740993) || values[1] != int64(9007199254740994) {
+		t.Errorf("Expected the sequence to start at the exact minimum, got %v", values)
+	}
+	if _, err := NewGenerator().SetSeed(42).GenerateRelatedN(schema, 3); err == nil {
+		t.Error("Expected the sequence to be exhausted after the exact maximum")
+	}
+}
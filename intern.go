@@ -0,0 +1,50 @@
+package schemagen
+
+import "unique"
+
+// internPlan makes the property names and the const, enum and label strings
+// of a compiled plan share storage with equal strings of earlier plans.
+// Generated documents hold these strings, and every Generate call parses
+// its schema anew, so without interning a batch of a million documents holds
+// a million copies of every enum value and property name. Interned strings
+// are released when no document uses them any more.
+func internPlan(plan *node) {
+	for _, n := range planNodes(plan) {
+		for i := range n.properties {
+			n.properties[i].name = intern(n.properties[i].name)
+		}
+		s := n.schema
+		s.Const = internValue(s.Const)
+		for i, v := range s.Enum {
+			s.Enum[i] = internValue(v)
+		}
+		for i, v := range n.enum {
+			n.enum[i] = internValue(v)
+		}
+		for i, label := range s.EnumVarNames {
+			s.EnumVarNames[i] = intern(label)
+		}
+	}
+}
+
+// intern returns the canonical copy of a string
+func intern(s string) string {
+	return unique.Make(s).Value()
+}
+
+// internValue interns the strings in a JSON value; object keys are kept
+func internValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return intern(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = internValue(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = internValue(item)
+		}
+	}
+	return v
+}
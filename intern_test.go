@@ -0,0 +1,56 @@
+package schemagen
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// Test documents of separate calls share the storage of enum values and
+// property names
+func TestInternStrings(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"status": {"enum": ["draft", "published"]},
+			"kind": {"const": "article"},
+			"tags": {"const": ["news", {"lang": "en"}]}
+		},
+		"required": ["status", "kind", "tags"]
+	}`)
+
+	gen := NewGenerator().SetSeed(42)
+	first, err := gen.GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	second, err := gen.GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+
+	same := func(a, b interface{}) bool {
+		sa, ok := a.(string)
+		sb, _ := b.(string)
+		return ok && sa == sb && unsafe.StringData(sa) == unsafe.StringData(sb)
+	}
+	if !same(first["kind"], second["kind"]) {
+		t.Errorf("Expected const strings to share storage")
+	}
+	if status := first["status"].(string); !same(status, intern(status)) {
+		t.Errorf("Expected enum strings to share storage")
+	}
+	firstTags, secondTags := first["tags"].([]interface{}), second["tags"].([]interface{})
+	if !same(firstTags[0], secondTags[0]) {
+		t.Errorf("Expected strings inside const arrays to share storage")
+	}
+	lang := func(tags []interface{}) interface{} { return tags[1].(map[string]interface{})["lang"] }
+	if !same(lang(firstTags), lang(secondTags)) {
+		t.Errorf("Expected strings inside const objects to share storage")
+	}
+
+	for key := range first {
+		if !same(key, intern(key)) {
+			t.Errorf("Expected property name %q to be interned", key)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// LintSeverity tells how serious a lint finding is
+type LintSeverity string
+
+const (
+	// LintWarning marks mistakes that skew generation or leave parts of the
+	// schema unused
+	LintWarning LintSeverity = "warning"
+	// LintError marks mistakes that make generation fail
+	LintError LintSeverity = "error"
+)
+
+// LintFinding is a likely authoring mistake in a schema
+type LintFinding struct {
+	Severity LintSeverity `json:"severity"`
+	Path     string       `json:"path"` // JSON Pointer of the offending value
+	Message  string       `json:"message"`
+	Line     int          `json:"line"`
+	Column   int          `json:"column"`
+}
+
+// String formats a finding as "/properties/id: message (line 3, column 5)"
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s (line %d, column %d)", f.Path, f.Message, f.Line, f.Column)
+}
+
+// Lint reports likely authoring mistakes in a schema document, in document
+// order.
+//
+// Warnings are keys that appear twice in an object, of which JSON decoding
+// silently keeps the last, enum values listed more than once, which makes
+// them more likely to be generated, enum values the rest of their schema
+// rejects, oneOf branches no value of the parent schema can match, and
+// formats without a built-in generator.
+//
+// Errors are the mistakes Generate fails on: constraints that contradict
+// each other, allOf subschemas that cannot be satisfied together and
+// patterns or formats that cannot meet the length bounds. A schema without
+// errors compiles.
+func Lint(schemaJSON []byte) ([]LintFinding, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	l := &linter{data: schemaJSON, dec: json.NewDecoder(bytes.NewReader(schemaJSON))}
+	if err := l.value("", false); err != nil {
+		return nil, fmt.Errorf("failed to lint schema: %w", err)
+	}
+
+	for _, ve := range schema.ValidateSource(schemaJSON) {
+		l.findings = append(l.findings, LintFinding{Severity: LintError, Path: ve.pointer, Message: ve.Message, Line: ve.Line, Column: ve.Column})
+	}
+	l.schema(schema, "")
+
+	// Report compile errors the checks above do not locate at the document
+	// root. References to other documents are not followed.
+	if _, err := compile(schema); err != nil && !errors.Is(err, errNoRefResolver) && !hasLintErrors(l.findings) {
+		l.at("", LintError, "%v", err)
+	}
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return l.findings, nil
+}
+
+// hasLintErrors reports whether any finding is an error
+func hasLintErrors(findings []LintFinding) bool {
+	for _, f := range findings {
+		if f.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// linter walks the tokens of a schema document
+type linter struct {
+	data     []byte
+	dec      *json.Decoder
+	findings []LintFinding
+}
+
+func (l *linter) report(pointer string, offset int64, format string, args ...interface{}) {
+	line, column := sourcePosition(l.data, offset)
+	l.findings = append(l.findings, LintFinding{Severity: LintWarning, Path: pointer, Message: fmt.Sprintf(format, args...), Line: line, Column: column})
+}
+
+// at reports a finding about the schema value at pointer
+func (l *linter) at(pointer string, severity LintSeverity, format string, args ...interface{}) {
+	f := LintFinding{Severity: severity, Path: pointer, Message: fmt.Sprintf(format, args...)}
+	if offset, ok := sourceOffset(l.data, pointer); ok {
+		f.Line, f.Column = sourcePosition(l.data, offset)
+	}
+	l.findings = append(l.findings, f)
+}
+
+// value checks the next value, at pointer; data values such as defaults
+// are checked for duplicate keys only
+func (l *linter) value(pointer string, data bool) error {
+	tok, err := l.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for l.dec.More() {
+			tok, err := l.dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			child := childPath(pointer, key)
+			if seen[key] {
+				l.report(child, scalarStart(l.data, l.dec.InputOffset()), "duplicate key %q, only the last value is used", key)
+			}
+			seen[key] = true
+
+			if !data && key == "enum" {
+				err = l.enum(child)
+			} else {
+				err = l.value(child, data || dataKeywords[key])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		_, err = l.dec.Token()
+		return err
+	case json.Delim('['):
+		for i := 0; l.dec.More(); i++ {
+			if err := l.value(childPath(pointer, strconv.Itoa(i)), data); err != nil {
+				return err
+			}
+		}
+		_, err = l.dec.Token()
+		return err
+	}
+	return nil
+}
+
+// enum checks the values of an enum for repeats
+func (l *linter) enum(pointer string) error {
+	start := valueStart(l.data, l.dec.InputOffset())
+	if start >= int64(len(l.data)) || l.data[start] != '[' {
+		return l.value(pointer, true)
+	}
+	if _, err := l.dec.Token(); err != nil {
+		return err
+	}
+
+	var values []interface{}
+	for i := 0; l.dec.More(); i++ {
+		offset := valueStart(l.data, l.dec.InputOffset())
+		var v interface{}
+		if err := l.dec.Decode(&v); err != nil {
+			return err
+		}
+		for _, prev := range values {
+			if jsonEqual(prev, v) {
+				data, _ := json.Marshal(v)
+				l.report(childPath(pointer, strconv.Itoa(i)), offset, "enum value %s is listed more than once", data)
+				break
+			}
+		}
+		values = append(values, v)
+	}
+	_, err := l.dec.Token()
+	return err
+}
+
+// schema checks a parsed schema at pointer and its subschemas for mistakes
+// that only show in their meaning
+func (l *linter) schema(s *Schema, pointer string) {
+	if s == nil {
+		return
+	}
+	l.enumValues(s, pointer)
+	l.lengths(s, pointer)
+	if _, known := formatLengths[s.Format]; s.Format != "" && !known {
+		l.at(childPath(pointer, "format"), LintWarning, "format %q has no built-in generator, values are random words", s.Format)
+	}
+	l.allOf(s, pointer)
+	l.oneOf(s, pointer)
+
+	for key, schemas := range map[string]map[string]*Schema{
+		"properties":       s.Properties,
+		"dependentSchemas": s.DependentSchemas,
+		"definitions":      s.Definitions,
+		"$defs":            s.Defs,
+	} {
+		for _, name := range slices.Sorted(maps.Keys(schemas)) {
+			l.schema(schemas[name], childPath(childPath(pointer, key), name))
+		}
+	}
+	for key, schemas := range map[string][]Schema{"oneOf": s.OneOf, "anyOf": s.AnyOf, "allOf": s.AllOf} {
+		for i := range schemas {
+			l.schema(&schemas[i], childPath(childPath(pointer, key), strconv.Itoa(i)))
+		}
+	}
+	l.schema(s.PropertyNames, childPath(pointer, "propertyNames"))
+	l.schema(s.Not, childPath(pointer, "not"))
+
+	// Subschemas held as generic values
+	l.generic(s.Items, childPath(pointer, "items"))
+	for i, item := range s.PrefixItems {
+		l.generic(item, childPath(childPath(pointer, "prefixItems"), strconv.Itoa(i)))
+	}
+	l.generic(s.AdditionalItems, childPath(pointer, "additionalItems"))
+	l.generic(s.AdditionalProperties, childPath(pointer, "additionalProperties"))
+	for _, name := range slices.Sorted(maps.Keys(s.Dependencies)) {
+		l.generic(s.Dependencies[name], childPath(childPath(pointer, "dependencies"), name))
+	}
+}
+
+// generic checks a subschema held as a generic value, or each of a list of
+// them
+func (l *linter) generic(v interface{}, pointer string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		sub, err := parseSubschema(v)
+		if err != nil {
+			return
+		}
+		l.schema(sub, pointer)
+	case []interface{}:
+		for i, item := range v {
+			if _, ok := item.(map[string]interface{}); ok {
+				l.generic(item, childPath(pointer, strconv.Itoa(i)))
+			}
+		}
+	}
+}
+
+// enumValues warns about enum values the rest of their schema rejects,
+// which are never generated. Validation fails only when no value is of a
+// declared type.
+func (l *linter) enumValues(s *Schema, pointer string) {
+	types := s.Type.GetTypes()
+	for i, v := range s.Enum {
+		if len(types) > 0 && !matchesAnyType(v, types) {
+			data, _ := json.Marshal(v)
+			l.at(childPath(childPath(pointer, "enum"), strconv.Itoa(i)), LintWarning, "enum value %s is never generated: it is of type %s, but the schema allows %v", data, jsonTypeOf(v), types)
+			continue
+		}
+		var errs []ValidationError
+		if text, ok := v.(string); ok {
+			errs = validateString(s, text, "")
+		} else if num, ok := toFloat(v); ok {
+			errs = validateNumber(s, num, v, "")
+		}
+		if len(errs) > 0 {
+			data, _ := json.Marshal(v)
+			l.at(childPath(childPath(pointer, "enum"), strconv.Itoa(i)), LintWarning, "enum value %s is never generated: %s", data, errs[0].Message)
+		}
+	}
+}
+
+// lengths reports patterns and formats that cannot meet the length bounds
+func (l *linter) lengths(s *Schema, pointer string) {
+	if s.MinLength != nil && s.MaxLength != nil && *s.MinLength > *s.MaxLength {
+		return // reported by validation
+	}
+	if s.Pattern == "" && s.Format == "" {
+		return
+	}
+	if err := (&compiler{}).compileString(&node{schema: s}); err != nil {
+		keyword := "pattern"
+		if s.Pattern == "" {
+			keyword = "format"
+		}
+		l.at(childPath(pointer, keyword), LintError, "%v", err)
+	}
+}
+
+// allOf reports allOf subschemas that cannot be satisfied together with
+// their schema. Referenced subschemas are left to the compiler.
+func (l *linter) allOf(s *Schema, pointer string) {
+	if len(s.AllOf) == 0 || len(s.ValidateWithDetails("")) > 0 {
+		return
+	}
+	merged := *s
+	merged.AllOf = nil
+	for i := range s.AllOf {
+		sub := &s.AllOf[i]
+		if sub.Ref != "" || len(sub.AllOf) > 0 {
+			continue
+		}
+		if err := mergeSchema(&merged, sub); err != nil {
+			l.at(childPath(childPath(pointer, "allOf"), strconv.Itoa(i)), LintError, "allOf[%d] cannot be satisfied together with the rest of the schema: %v", i, err)
+			return
+		}
+	}
+	if errs := merged.ValidateWithDetails(""); len(errs) > 0 {
+		l.at(childPath(pointer, "allOf"), LintError, "allOf subschemas cannot be satisfied together: %s", errs[0].Message)
+	}
+}
+
+// oneOf warns about oneOf branches that no value of the rest of the schema
+// can match, and about identical branches, of which values always match
+// more than one. It is an error when no branch is left.
+func (l *linter) oneOf(s *Schema, pointer string) {
+	if len(s.OneOf) == 0 {
+		return
+	}
+	parent := *s
+	parent.OneOf, parent.AnyOf, parent.AllOf, parent.Not = nil, nil, nil, nil
+	parentValid := len(parent.ValidateWithDetails("")) == 0
+
+	encoded := make([][]byte, len(s.OneOf))
+	reachable := 0
+	for i := range s.OneOf {
+		branch := &s.OneOf[i]
+		at := childPath(childPath(pointer, "oneOf"), strconv.Itoa(i))
+		encoded[i], _ = json.Marshal(branch)
+		if j := slices.IndexFunc(encoded[:i], func(prev []byte) bool { return bytes.Equal(prev, encoded[i]) }); j >= 0 {
+			l.at(at, LintWarning, "oneOf branch %d is identical to branch %d, so no value matches exactly one of them", i, j)
+			continue
+		}
+		if branch.Ref != "" {
+			reachable++
+			continue
+		}
+
+		merged := parent
+		err := mergeSchema(&merged, branch)
+		if err == nil && parentValid && len(branch.ValidateWithDetails("")) == 0 {
+			if errs := merged.ValidateWithDetails(""); len(errs) > 0 {
+				err = fmt.Errorf("%s", errs[0].Message)
+			}
+		}
+		if err != nil {
+			l.at(at, LintWarning, "oneOf branch %d can never match: %v", i, err)
+			continue
+		}
+		reachable++
+	}
+	if reachable == 0 {
+		l.at(childPath(pointer, "oneOf"), LintError, "no oneOf branch can match")
+	}
+}
@@ -0,0 +1,103 @@
+package schemagen
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	schema := []byte(`{
+  "type": "object",
+  "properties": {
+    "status": {"type": "string", "enum": ["open", "closed", "open"]},
+    "count": {"type": "integer", "minimum": 0},
+    "count": {"type": "integer", "minimum": 1},
+    "size": {"enum": [1, 2, 1.0]},
+    "meta": {"default": {"enum": ["a", "a"]}, "const": {"k": 1, "k": 2}}
+  },
+  "type": "object"
+}`)
+
+	findings, err := Lint(schema)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+
+	want := []string{
+		`/properties/status/enum/2: enum value "open" is listed more than once (line 4, column 61)`,
+		`/properties/count: duplicate key "count", only the last value is used (line 6, column 5)`,
+		`/properties/size/enum/2: enum value 1 is listed more than once (line 7, column 29)`,
+		`/properties/meta/const/k: duplicate key "k", only the last value is used (line 8, column 65)`,
+		`/type: duplicate key "type", only the last value is used (line 10, column 3)`,
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Lint() = %v, want %d findings", findings, len(want))
+	}
+	for i, f := range findings {
+		if f.String() != want[i] {
+			t.Errorf("finding %d = %q, want %q", i, f.String(), want[i])
+		}
+	}
+}
+
+// Test schemas without mistakes have no findings and invalid JSON fails
+func TestLintClean(t *testing.T) {
+	findings, err := Lint([]byte(`{"type": "object", "properties": {"a": {"enum": [1, "1", [1], {"a": 1}]}}}`))
+	if err != nil || len(findings) != 0 {
+		t.Errorf("Lint() = %v, %v, want no findings", findings, err)
+	}
+	if _, err := Lint([]byte(`{"type": `)); err == nil {
+		t.Error("Lint() of invalid JSON succeeded")
+	}
+}
+
+// Test findings about the meaning of a schema and their severity
+func TestLintSeverity(t *testing.T) {
+	schema := []byte(`{
+  "type": "object",
+  "properties": {
+    "code": {"type": "string", "pattern": "^[A-Z]{3}$", "minLength": 5},
+    "size": {"type": "integer", "enum": [1, 5, 10], "maximum": 6},
+    "sku": {"type": "string", "format": "sku"},
+    "id": {"allOf": [{"type": "integer"}, {"type": "string"}]},
+    "pet": {"type": "object", "oneOf": [{"type": "array"}, {"required": ["name"]}, {"required": ["name"]}]},
+    "tags": {"type": "array", "items": {"type": "string", "minLength": 4, "maxLength": 2}}
+  }
+}`)
+
+	findings, err := Lint(schema)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+
+	want := []struct {
+		severity LintSeverity
+		text     string
+	}{
+		{LintError, `/properties/code/pattern: pattern "^[A-Z]{3}$" cannot produce strings of 5 to 20 characters (line 4, column 43)`},
+		{LintWarning, `/properties/size/enum/2: enum value 10 is never generated: 10 is greater than maximum 6 (line 5, column 48)`},
+		{LintWarning, `/properties/sku/format: format "sku" has no built-in generator, values are random words (line 6, column 41)`},
+		{LintError, `/properties/id/allOf/1: allOf[1] cannot be satisfied together with the rest of the schema: types [integer] and [string] have nothing in common (line 7, column 43)`},
+		{LintWarning, `/properties/pet/oneOf/0: oneOf branch 0 can never match: types [object] and [array] have nothing in common (line 8, column 41)`},
+		{LintWarning, `/properties/pet/oneOf/2: oneOf branch 2 is identical to branch 1, so no value matches exactly one of them (line 8, column 84)`},
+		{LintError, `/properties/tags/items: minLength (4) cannot be greater than maxLength (2) (line 9, column 40)`},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Lint() = %v, want %d findings", findings, len(want))
+	}
+	for i, f := range findings {
+		if f.Severity != want[i].severity || f.String() != want[i].text {
+			t.Errorf("finding %d = %s %q, want %s %q", i, f.Severity, f.String(), want[i].severity, want[i].text)
+		}
+	}
+}
+
+// Test schemas Generate fails on have an error finding
+func TestLintCompileError(t *testing.T) {
+	findings, err := Lint([]byte(`{"type": "object", "properties": {"a": {"$ref": "#/$defs/missing"}}}`))
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Severity != LintError || findings[0].Path != "" {
+		t.Errorf("Lint() = %v, want one error at the root", findings)
+	}
+}
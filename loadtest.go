@@ -0,0 +1,177 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LoadTarget is a generated HTTP request for a load-testing tool
+type LoadTarget struct {
+	OperationID string
+	Method      string
+	Path        string      // the path with parameters and query string, e.g. "/users/42?limit=10"
+	Header      http.Header // header parameters and the body's Content-Type
+	Body        []byte      // nil for operations without a request body
+}
+
+// LoadTargets returns an endless iterator over generated requests for the
+// operations of an OpenAPI 3 spec in JSON, taking the operations in turn by
+// path and method. Path, query and header parameters and the request body
+// are generated from their schemas; bodies are JSON when the operation
+// allows it, otherwise form or multipart bodies. The spec is read before
+// LoadTargets returns; errors generating a request are yielded with it, and
+// iteration goes on if the loop continues.
+//
+//	targets, err := gen.LoadTargets(spec)
+//	for target, err := range targets {
+//		...
+//	}
+//
+// Use iter.Pull for tools that ask for the next request, like vegeta's
+// Targeter.
+func (g *Generator) LoadTargets(spec []byte) (iter.Seq2[LoadTarget, error], error) {
+	s, err := parseOpenAPI(spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.operations) == 0 {
+		return nil, fmt.Errorf("the spec has no operations")
+	}
+
+	return func(yield func(LoadTarget, error) bool) {
+		for i := 0; ; i++ {
+			op := s.operations[i%len(s.operations)]
+			target, err := g.generateRequest(s, op)
+			if err != nil {
+				err = fmt.Errorf("%s %s: %w", op.method, op.path, err)
+			}
+			if !yield(target, err) {
+				return
+			}
+		}
+	}, nil
+}
+
+// generateRequest generates a request for an operation
+func (g *Generator) generateRequest(s *openAPISpec, op openAPIOperation) (LoadTarget, error) {
+	target := LoadTarget{OperationID: op.id, Method: op.method, Path: op.path, Header: make(http.Header)}
+
+	groups := map[string][]openAPIParameter{}
+	for _, p := range op.parameters {
+		groups[p.In] = append(groups[p.In], p)
+	}
+
+	if params := groups["path"]; len(params) > 0 {
+		values, err := g.generateFlat(s.parameterSchema(params))
+		if err != nil {
+			return LoadTarget{}, fmt.Errorf("path parameters: %w", err)
+		}
+		for _, p := range params {
+			target.Path = strings.ReplaceAll(target.Path, "{"+p.Name+"}", pathText(p, values[p.Name]))
+		}
+	}
+
+	if params := groups["query"]; len(params) > 0 {
+		query, err := g.GenerateQuery(s.parameterSchema(params), parameterEncodings(params))
+		if err != nil {
+			return LoadTarget{}, fmt.Errorf("query parameters: %w", err)
+		}
+		if len(query) > 0 {
+			target.Path += "?" + query.Encode()
+		}
+	}
+
+	var headers []openAPIParameter
+	for _, p := range groups["header"] {
+		// These headers are described by other fields of the spec
+		switch http.CanonicalHeaderKey(p.Name) {
+		case "Accept", "Content-Type", "Authorization":
+			continue
+		}
+		headers = append(headers, p)
+	}
+	if len(headers) > 0 {
+		header, err := g.GenerateHeader(s.parameterSchema(headers), parameterEncodings(headers))
+		if err != nil {
+			return LoadTarget{}, fmt.Errorf("header parameters: %w", err)
+		}
+		target.Header = header
+	}
+
+	if op.body != nil {
+		body, contentType, err := g.generateBody(op.body)
+		if err != nil {
+			return LoadTarget{}, fmt.Errorf("request body: %w", err)
+		}
+		target.Body = body
+		target.Header.Set("Content-Type", contentType)
+	}
+	return target, nil
+}
+
+// generateBody generates a body in its media type and returns it with its
+// Content-Type
+func (g *Generator) generateBody(content *openAPIContent) ([]byte, string, error) {
+	var r io.Reader
+	var contentType string
+	var err error
+	switch {
+	case strings.HasPrefix(content.mediaType, "application/x-www-form-urlencoded"):
+		r, contentType, err = g.GenerateForm(content.schema, nil)
+	case strings.HasPrefix(content.mediaType, "multipart/form-data"):
+		r, contentType, err = g.GenerateMultipart(content.schema, content.encoding)
+	default:
+		body, err := g.GenerateBytes(content.schema)
+		return body, content.mediaType, err
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	body, err := io.ReadAll(r)
+	return body, contentType, err
+}
+
+// parameterSchema returns an object schema with a property per parameter
+func (s *openAPISpec) parameterSchema(params []openAPIParameter) []byte {
+	properties := make(map[string]json.RawMessage)
+	required := []string{}
+	for _, p := range params {
+		properties[p.Name] = p.Schema
+		if len(p.Schema) == 0 {
+			properties[p.Name] = json.RawMessage(`{"type": "string"}`)
+		}
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	schema, _ := json.Marshal(map[string]interface{}{"type": "object", "properties": properties, "required": required})
+	return s.specSchema(schema)
+}
+
+// parameterEncodings returns the encoding of each parameter by name
+func parameterEncodings(params []openAPIParameter) map[string]FormEncoding {
+	encoding := make(map[string]FormEncoding)
+	for _, p := range params {
+		encoding[p.Name] = p.encoding()
+	}
+	return encoding
+}
+
+// pathText formats a path parameter in its style: "simple" (the default),
+// "label" or "matrix"
+func pathText(p openAPIParameter, value interface{}) string {
+	explode := p.Explode != nil && *p.Explode
+	text := url.PathEscape(headerText(value, explode))
+	switch p.Style {
+	case "label":
+		return "." + text
+	case "matrix":
+		return ";" + p.Name + "=" + text
+	}
+	return text
+}
@@ -0,0 +1,124 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"iter"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// testSpec is an OpenAPI spec with parameters, references and bodies in
+// several media types
+const testSpec = `{
+	"openapi": "3.1.0",
+	"paths": {
+		"/users": {
+			"get": {
+				"operationId": "listUsers",
+				"parameters": [
+					{"name": "limit", "in": "query", "required": true, "schema": {"type": "integer", "minimum": 1, "maximum": 50}},
+					{"$ref": "#/components/parameters/RequestID"}
+				]
+			},
+			"post": {
+				"operationId": "createUser",
+				"requestBody": {"$ref": "#/components/requestBodies/NewUser"}
+			}
+		},
+		"/users/{id}": {
+			"parameters": [{"name": "id", "in": "path", "schema": {"type": "integer", "minimum": 1, "maximum": 9}}],
+			"put": {
+				"operationId": "updateUser",
+				"requestBody": {"content": {"application/x-www-form-urlencoded": {"schema": {"$ref": "#/components/schemas/User"}}}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"User": {
+				"type": "object",
+				"properties": {"name": {"type": "string", "minLength": 1}, "email": {"type": "string", "format": "email"}},
+				"required": ["name", "email"]
+			}
+		},
+		"parameters": {
+			"RequestID": {"name": "X-Request-ID", "in": "header", "required": true, "schema": {"type": "string", "format": "uuid"}}
+		},
+		"requestBodies": {
+			"NewUser": {"content": {"text/plain": {}, "application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}
+		}
+	}
+}`
+
+// Test requests generated for the operations of a spec, in turn
+func TestLoadTargets(t *testing.T) {
+	targets, err := NewGenerator().SetSeed(42).LoadTargets([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("LoadTargets() error = %v", err)
+	}
+
+	next, stop := iter.Pull2(targets)
+	defer stop()
+	var got []LoadTarget
+	for i := 0; i < 6; i++ {
+		target, err, _ := next()
+		if err != nil {
+			t.Fatalf("target %d: error = %v", i, err)
+		}
+		got = append(got, target)
+	}
+
+	list, create, update := got[0], got[1], got[2]
+	if list.OperationID != "listUsers" || got[3].OperationID != "listUsers" {
+		t.Errorf("expected operations in turn, got %s and %s", list.OperationID, got[3].OperationID)
+	}
+	u, _ := url.Parse(list.Path)
+	if u.Path != "/users" || u.Query().Get("limit") == "" || list.Method != "GET" {
+		t.Errorf("unexpected list request %s %s", list.Method, list.Path)
+	}
+	if !regexp.MustCompile(`^[0-9a-f-]{36}$`).MatchString(list.Header.Get("X-Request-ID")) || list.Body != nil {
+		t.Errorf("expected a uuid header and no body, got %v and %q", list.Header, list.Body)
+	}
+
+	var user map[string]interface{}
+	if err := json.Unmarshal(create.Body, &user); err != nil || !strings.Contains(user["email"].(string), "@") {
+		t.Errorf("expected a JSON user body, got %q (%v)", create.Body, err)
+	}
+	if create.Method != "POST" || create.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected create request %s with %v", create.Method, create.Header)
+	}
+
+	if !regexp.MustCompile(`^/users/[1-9]$`).MatchString(update.Path) {
+		t.Errorf("expected the path parameter filled in, got %s", update.Path)
+	}
+	form, err := url.ParseQuery(string(update.Body))
+	if err != nil || form.Get("name") == "" || update.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("expected a form body, got %q with %v", update.Body, update.Header)
+	}
+}
+
+// Test specs that cannot be read
+func TestLoadTargetsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"not JSON", `openapi: 3.0.0`, "invalid OpenAPI spec"},
+		{"swagger", `{"swagger": "2.0", "paths": {}}`, "unsupported OpenAPI version"},
+		{"no operations", `{"openapi": "3.0.3", "paths": {}}`, "no operations"},
+		{"unresolved", `{"openapi": "3.0.3", "paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/X"}]}}}}`, "unresolved reference"},
+		{"media type", `{"openapi": "3.0.3", "paths": {"/a": {"post": {"requestBody": {"content": {"image/png": {}}}}}}}`, "no supported media type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator().LoadTargets([]byte(tt.spec))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadTargets() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package schemagen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale used when none is configured. Its text comes
+// straight from gofakeit.
+const DefaultLocale = "en_US"
+
+// Locale provides localized source data for generated text. Empty fields
+// fall back to gofakeit's English data.
+type Locale struct {
+	Code  string   // e.g. "de_DE"
+	Words []string // words used to build free-form strings
+
+	// Names and addresses, used for the values SetSmartNames infers
+	FirstNames       []string
+	LastNames        []string
+	FamilyNameFirst  bool // full names are written family name first
+	Cities           []string
+	Streets          []string // street names, written with a house number
+	HouseNumberFirst bool     // house numbers go before the street name
+	PhoneFormat      string   // phone numbers, with # for each digit, e.g. "+49 30 #######"
+	PostcodeFormat   string   // postcodes, with # for each digit
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]*Locale{
+		DefaultLocale: {Code: DefaultLocale},
+		"de_DE": {Code: "de_DE", Words: []string{
+			"haus", "garten", "straße", "apfel", "zeit", "arbeit", "wasser", "stadt", "freund", "schule",
+			"tisch", "fenster", "himmel", "brot", "buch", "wagen", "licht", "wald", "berg", "fluss",
+			"sommer", "winter", "morgen", "abend", "kaffee", "bahnhof", "markt", "reise", "farbe", "musik",
+		},
+			FirstNames:     []string{"Lukas", "Leon", "Finn", "Jonas", "Paul", "Felix", "Anna", "Lena", "Marie", "Sophie", "Hannah", "Emma"},
+			LastNames:      []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann"},
+			Cities:         []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt am Main", "Stuttgart", "Düsseldorf", "Leipzig", "Dresden", "Bremen"},
+			Streets:        []string{"Hauptstraße", "Schulstraße", "Gartenstraße", "Bahnhofstraße", "Dorfstraße", "Bergstraße", "Lindenstraße", "Kirchstraße"},
+			PhoneFormat:    "+49 30 #######",
+			PostcodeFormat: "#####",
+		},
+		"fr_FR": {Code: "fr_FR", Words: []string{
+			"maison", "jardin", "rue", "pomme", "temps", "travail", "eau", "ville", "ami", "école",
+			"table", "fenêtre", "ciel", "pain", "livre", "voiture", "lumière", "forêt", "montagne", "rivière",
+			"été", "hiver", "matin", "soir", "café", "gare", "marché", "voyage", "couleur", "musique",
+		},
+			FirstNames:       []string{"Gabriel", "Léo", "Raphaël", "Louis", "Jules", "Arthur", "Jade", "Louise", "Emma", "Alice", "Chloé", "Inès"},
+			LastNames:        []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy", "Moreau"},
+			Cities:           []string{"Paris", "Marseille", "Lyon", "Toulouse", "Nice", "Nantes", "Strasbourg", "Montpellier", "Bordeaux", "Lille"},
+			Streets:          []string{"rue de la Paix", "rue Victor Hugo", "avenue Jean Jaurès", "boulevard Saint-Michel", "rue du Moulin", "place de la République"},
+			HouseNumberFirst: true,
+			PhoneFormat:      "+33 6 ## ## ## ##",
+			PostcodeFormat:   "#####",
+		},
+		"es_ES": {Code: "es_ES", Words: []string{
+			"casa", "jardín", "calle", "manzana", "tiempo", "trabajo", "agua", "ciudad", "amigo", "escuela",
+			"mesa", "ventana", "cielo", "pan", "libro", "coche", "luz", "bosque", "montaña", "río",
+			"verano", "invierno", "mañana", "noche", "café", "estación", "mercado", "viaje", "color", "música",
+		},
+			FirstNames:     []string{"Hugo", "Martín", "Lucas", "Mateo", "Leo", "Daniel", "Lucía", "Sofía", "Martina", "María", "Julia", "Paula"},
+			LastNames:      []string{"García", "Rodríguez", "González", "Fernández", "López", "Martínez", "Sánchez", "Pérez", "Gómez", "Ruiz"},
+			Cities:         []string{"Madrid", "Barcelona", "Valencia", "Sevilla", "Zaragoza", "Málaga", "Murcia", "Palma", "Bilbao", "Alicante"},
+			Streets:        []string{"Calle Mayor", "Calle Real", "Gran Vía", "Calle de Alcalá", "Avenida de la Constitución", "Paseo del Prado"},
+			PhoneFormat:    "+34 6## ### ###",
+			PostcodeFormat: "#####",
+		},
+		"ja_JP": {Code: "ja_JP", Words: []string{
+			"家", "庭", "通り", "りんご", "時間", "仕事", "水", "町", "友達", "学校",
+			"机", "窓", "空", "パン", "本", "車", "光", "森", "山", "川",
+			"夏", "冬", "朝", "夜", "コーヒー", "駅", "市場", "旅行", "色", "音楽",
+		},
+			FirstNames:      []string{"蓮", "陽翔", "湊", "樹", "悠真", "大和", "陽葵", "凛", "詩", "結菜", "芽依", "葵"},
+			LastNames:       []string{"佐藤", "鈴木", "高橋", "田中", "伊藤", "渡辺", "山本", "中村", "小林", "加藤"},
+			FamilyNameFirst: true,
+			Cities:          []string{"東京", "横浜", "大阪", "名古屋", "札幌", "福岡", "神戸", "京都", "川崎", "さいたま"},
+			Streets:         []string{"銀座", "本町", "栄町", "中央", "桜木町", "元町"},
+			PhoneFormat:     "090-####-####",
+			PostcodeFormat:  "###-####",
+		},
+	}
+)
+
+// RegisterLocale adds or replaces a locale so it can be selected by code
+func RegisterLocale(locale *Locale) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[locale.Code] = locale
+}
+
+// LookupLocale returns the registered locale for code
+func LookupLocale(code string) (*Locale, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	locale, ok := locales[code]
+	return locale, ok
+}
+
+// Locales returns the codes of all registered locales, sorted
+func Locales() []string {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// SetLocale selects the locale of generated text by code, e.g. "de_DE":
+// the words of free-form strings and, with SetSmartNames, names, cities,
+// streets, phone numbers and postcodes. x-locale in a schema and WithLocale
+// for a single call take precedence. "" selects DefaultLocale; unknown
+// codes are reported when a schema is prepared.
+func (g *Generator) SetLocale(code string) *Generator {
+	g.Locale = code
+	return g
+}
+
+// localeKey is the context key of the locale of a call
+type localeKey struct{}
+
+// WithLocale returns a context that selects a locale for the generation
+// calls it is passed to, such as GenerateWithContext, overriding SetLocale.
+// An unknown code fails the first value that needs the locale.
+func WithLocale(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, localeKey{}, code)
+}
+
+// localeOf returns the locale of the value being generated: the nearest
+// x-locale, the call's locale, then the generator's; nil for the default
+func (g *Generator) localeOf(st *genState) (*Locale, error) {
+	if st.locale != nil {
+		return st.locale, nil
+	}
+	code := g.Locale
+	if c, ok := st.ctx.Value(localeKey{}).(string); ok {
+		code = c
+	}
+	if code == "" {
+		return nil, nil
+	}
+	return resolveLocale(code)
+}
+
+// localized generates a value of a smart name kind from a locale's data
+func (g *Generator) localized(locale *Locale, kind string) (string, bool) {
+	if locale == nil {
+		return "", false
+	}
+	pick := func(list []string) string { return list[g.rand.Intn(len(list))] }
+	switch {
+	case kind == "first-name" && len(locale.FirstNames) > 0:
+		return pick(locale.FirstNames), true
+	case kind == "last-name" && len(locale.LastNames) > 0:
+		return pick(locale.LastNames), true
+	case kind == "full-name" && len(locale.FirstNames) > 0 && len(locale.LastNames) > 0:
+		first, last := pick(locale.FirstNames), pick(locale.LastNames)
+		if locale.FamilyNameFirst {
+			return last + " " + first, true
+		}
+		return first + " " + last, true
+	case kind == "city" && len(locale.Cities) > 0:
+		return pick(locale.Cities), true
+	case kind == "street" && len(locale.Streets) > 0:
+		number := strconv.Itoa(1 + g.rand.Intn(199))
+		if locale.HouseNumberFirst {
+			return number + " " + pick(locale.Streets), true
+		}
+		return pick(locale.Streets) + " " + number, true
+	case kind == "phone" && locale.PhoneFormat != "":
+		return g.digits(locale.PhoneFormat), true
+	case kind == "postcode" && locale.PostcodeFormat != "":
+		return g.digits(locale.PostcodeFormat), true
+	}
+	return "", false
+}
+
+// digits replaces each # of a format with a random digit
+func (g *Generator) digits(format string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '#' {
+			return rune('0' + g.rand.Intn(10))
+		}
+		return r
+	}, format)
+}
+
+// resolveLocale looks up a locale by code, returning an error for unknown codes
+func resolveLocale(code string) (*Locale, error) {
+	locale, ok := LookupLocale(code)
+	if !ok {
+		return nil, fmt.Errorf("unknown locale %q", code)
+	}
+	return locale, nil
+}
@@ -0,0 +1,158 @@
+package schemagen
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPerPropertyLocale(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"productName": {"type": "string", "minLength": 8, "maxLength": 12},
+			"shipping": {
+				"type": "object",
+				"x-locale": "ja_JP",
+				"properties": {
+					"city": {"type": "string", "minLength": 4, "maxLength": 8}
+				},
+				"required": ["city"]
+			},
+			"note": {"type": "string", "minLength": 5, "maxLength": 5, "x-locale": "de_DE"}
+		},
+		"required": ["productName", "shipping", "note"]
+	}`
+
+	gen := NewGenerator().SetSeed(42)
+	result, err := gen.Generate([]byte(schema))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	obj := result.(map[string]interface{})
+
+	productName := obj["productName"].(string)
+	if !isASCII(productName) {
+		t.Errorf("Expected default locale text, got %q", productName)
+	}
+
+	city := obj["shipping"].(map[string]interface{})["city"].(string)
+	if !utf8.ValidString(city) {
+		t.Fatalf("Generated invalid UTF-8: %q", city)
+	}
+	if isASCII(city) {
+		t.Errorf("Expected Japanese text inherited from parent x-locale, got %q", city)
+	}
+	if n := utf8.RuneCountInString(city); n < 4 || n > 8 {
+		t.Errorf("Expected 4-8 characters, got %d (%q)", n, city)
+	}
+
+	if n := utf8.RuneCountInString(obj["note"].(string)); n != 5 {
+		t.Errorf("Expected exactly 5 characters, got %d", n)
+	}
+}
+
+func TestUnknownLocale(t *testing.T) {
+	schema := `{"type": "string", "x-locale": "xx_XX"}`
+
+	_, err := NewGenerator().Generate([]byte(schema))
+	if err == nil {
+		t.Fatal("Expected error for unknown locale")
+	}
+	if !strings.Contains(err.Error(), "xx_XX") {
+		t.Errorf("Expected error to mention the locale, got %v", err)
+	}
+}
+
+func TestRegisterLocale(t *testing.T) {
+	RegisterLocale(&Locale{Code: "test_ZZ", Words: []string{"zz"}})
+
+	if _, ok := LookupLocale("test_ZZ"); !ok {
+		t.Fatal("Expected registered locale to be found")
+	}
+
+	result, err := NewGenerator().SetSeed(1).Generate([]byte(`{"type": "string", "minLength": 6, "maxLength": 6, "x-locale": "test_ZZ"}`))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result != "zzzzzz" {
+		t.Errorf("Expected text built from registered words, got %q", result)
+	}
+}
+
+// Test the generator's locale, the call's locale and x-locale, in order of
+// precedence
+func TestSetLocale(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"firstName": {"type": "string"},
+			"city": {"type": "string"},
+			"phone": {"type": "string"},
+			"street": {"type": "string"},
+			"note": {"type": "string", "minLength": 20, "maxLength": 30},
+			"office": {
+				"type": "object",
+				"x-locale": "ja_JP",
+				"properties": {"city": {"type": "string"}},
+				"required": ["city"]
+			}
+		},
+		"required": ["firstName", "city", "phone", "street", "note", "office"]
+	}`)
+	de, _ := LookupLocale("de_DE")
+	fr, _ := LookupLocale("fr_FR")
+	ja, _ := LookupLocale("ja_JP")
+
+	gen := NewGenerator().SetSeed(42).SetSmartNames(true).SetLocale("de_DE")
+	result, err := gen.GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if !slices.Contains(de.FirstNames, result["firstName"].(string)) || !slices.Contains(de.Cities, result["city"].(string)) {
+		t.Errorf("expected German names and cities, got %v", result)
+	}
+	if !regexp.MustCompile(`^\+49 30 \d{7}$`).MatchString(result["phone"].(string)) {
+		t.Errorf("expected a German phone number, got %v", result["phone"])
+	}
+	if !regexp.MustCompile(`^\D+ \d+$`).MatchString(result["street"].(string)) {
+		t.Errorf("expected the house number after the street, got %v", result["street"])
+	}
+	if note := result["note"].(string); !slices.ContainsFunc(de.Words, func(w string) bool { return strings.HasPrefix(note, w) }) {
+		t.Errorf("expected text of German words, got %q", note)
+	}
+	if city := result["office"].(map[string]interface{})["city"].(string); !slices.Contains(ja.Cities, city) {
+		t.Errorf("expected x-locale to win, got %v", city)
+	}
+
+	// A call's locale overrides the generator's
+	value, err := gen.GenerateWithContext(WithLocale(context.Background(), "fr_FR"), schema)
+	if err != nil {
+		t.Fatalf("GenerateWithContext() error = %v", err)
+	}
+	result = value.(map[string]interface{})
+	if !slices.Contains(fr.FirstNames, result["firstName"].(string)) || !regexp.MustCompile(`^\d+ `).MatchString(result["street"].(string)) {
+		t.Errorf("expected French values, got %v", result)
+	}
+
+	// Unknown locales
+	if _, err := NewGenerator().SetLocale("xx_XX").Generate(schema); err == nil || !strings.Contains(err.Error(), "xx_XX") {
+		t.Errorf("expected an unknown locale error, got %v", err)
+	}
+	if _, err := gen.GenerateWithContext(WithLocale(context.Background(), "xx_XX"), schema); err == nil {
+		t.Error("expected an unknown call locale to fail")
+	}
+}
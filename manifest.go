@@ -0,0 +1,221 @@
+package schemagen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"maps"
+	"reflect"
+	"slices"
+	"unicode/utf8"
+)
+
+// Manifest describes a generated dataset so it can be traced in data
+// catalogs: the schema and seeds it was generated from, and the files,
+// documents and fields it holds. Build it by writing the dataset's NDJSON
+// files through File, then encode it with json.Marshal. A Manifest is not
+// safe for concurrent use.
+type Manifest struct {
+	SchemaSHA256  string                 `json:"schemaSha256"`
+	Seed          int64                  `json:"seed"`
+	StructureSeed int64                  `json:"structureSeed"`
+	OptionsSHA256 string                 `json:"optionsSha256"` // of the generator's other settings
+	Version       string                 `json:"generatorVersion"`
+	Documents     int                    `json:"documents"`
+	Files         []*ManifestFile        `json:"files"`
+	Fields        map[string]*FieldStats `json:"fields"` // by JSON Pointer, with "*" for array items
+}
+
+// ManifestFile describes a file of a dataset
+type ManifestFile struct {
+	Name      string `json:"name"`
+	Documents int    `json:"documents"`
+	Bytes     int64  `json:"bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// FieldStats summarizes the values of a field across a dataset
+type FieldStats struct {
+	Count     int            `json:"count"`               // values present
+	Types     map[string]int `json:"types"`               // values by JSON type
+	Min       *float64       `json:"min,omitempty"`       // of numbers
+	Max       *float64       `json:"max,omitempty"`       // of numbers
+	MinLength *int           `json:"minLength,omitempty"` // of strings, in characters
+	MaxLength *int           `json:"maxLength,omitempty"` // of strings, in characters
+}
+
+// NewManifest starts the manifest of a dataset that g generates from a
+// schema, recording the schema's hash and g's current seeds
+func NewManifest(g *Generator, schemaJSON []byte) *Manifest {
+	sum := sha256.Sum256(schemaJSON)
+	return &Manifest{
+		SchemaSHA256:  hex.EncodeToString(sum[:]),
+		Seed:          g.Seed,
+		StructureSeed: g.StructureSeed,
+		OptionsSHA256: g.optionsHash(),
+		Version:       Version(),
+		Files:         []*ManifestFile{},
+		Fields:        make(map[string]*FieldStats),
+	}
+}
+
+// UpToDate reports whether the dataset a manifest describes is what g
+// would generate from a schema again, so a fixture build can skip it: the
+// schema hash, seeds, settings and generator version are unchanged, and
+// every file in fsys still has its recorded size and checksum. Compare
+// Documents too when the number of documents may change.
+//
+//	if old.UpToDate(gen, schema, os.DirFS(dir)) {
+//		return nil // nothing to regenerate
+//	}
+func (m *Manifest) UpToDate(g *Generator, schemaJSON []byte, fsys fs.FS) bool {
+	current := NewManifest(g, schemaJSON)
+	if m.SchemaSHA256 != current.SchemaSHA256 || m.Seed != current.Seed || m.StructureSeed != current.StructureSeed ||
+		m.OptionsSHA256 != current.OptionsSHA256 || m.Version != current.Version {
+		return false
+	}
+	for _, file := range m.Files {
+		f, err := fsys.Open(file.Name)
+		if err != nil {
+			return false
+		}
+		sum := sha256.New()
+		size, err := io.Copy(sum, f)
+		f.Close()
+		if err != nil || size != file.Bytes || hex.EncodeToString(sum.Sum(nil)) != file.SHA256 {
+			return false
+		}
+	}
+	return true
+}
+
+// optionsHash returns the SHA-256 of the settings of g that shape the
+// documents, other than the seeds. Code is recorded by name only: format
+// providers by format, overrides by glob, text corpora by name and bundles
+// by name, so bundles should be renamed when their behavior changes.
+func (g *Generator) optionsHash() string {
+	settings := make(map[string]interface{})
+	v := reflect.ValueOf(g).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		switch field.Type.Kind() {
+		case reflect.Func, reflect.Interface, reflect.Map:
+			continue
+		}
+		if field.IsExported() && field.Name != "Seed" && field.Name != "StructureSeed" {
+			settings[field.Name] = v.Field(i).Interface()
+		}
+	}
+	globs := make([]string, len(g.overrides))
+	for i, o := range g.overrides {
+		globs[i] = o.glob
+	}
+	settings["formatProviders"] = slices.Sorted(maps.Keys(g.FormatProviders))
+	settings["overrides"] = globs
+	settings["hooks"] = len(g.hooks)
+	settings["corpora"] = slices.Sorted(maps.Keys(g.corpora))
+	settings["bundles"] = g.bundles
+	settings["smartNames"] = g.smartNames
+
+	data, _ := json.Marshal(settings)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// File returns a writer that passes NDJSON through to w and records it in
+// the manifest as the file name: its size and checksum, and the documents
+// of its lines in the counts and field stats.
+//
+//	m := schemagen.NewManifest(gen, schema)
+//	err := gen.GenerateNDJSON(ctx, m.File("users.ndjson", f), schema, 1000)
+func (m *Manifest) File(name string, w io.Writer) io.Writer {
+	file := &ManifestFile{Name: name}
+	m.Files = append(m.Files, file)
+	return &manifestWriter{m: m, file: file, w: w, sum: sha256.New()}
+}
+
+// manifestWriter records the NDJSON written to a file
+type manifestWriter struct {
+	m       *Manifest
+	file    *ManifestFile
+	w       io.Writer
+	sum     hash.Hash
+	partial []byte // the start of a line not yet ended
+}
+
+func (mw *manifestWriter) Write(p []byte) (int, error) {
+	n, err := mw.w.Write(p)
+	mw.sum.Write(p[:n])
+	mw.file.Bytes += int64(n)
+	mw.file.SHA256 = hex.EncodeToString(mw.sum.Sum(nil))
+
+	data := append(mw.partial, p[:n]...)
+	for {
+		line, rest, ok := bytes.Cut(data, []byte("\n"))
+		if !ok {
+			break
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			if recordErr := mw.record(line); recordErr != nil && err == nil {
+				err = recordErr
+			}
+		}
+		data = rest
+	}
+	mw.partial = append([]byte(nil), data...)
+	return n, err
+}
+
+// record adds a document to the counts and field stats
+func (mw *manifestWriter) record(line []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(line, &doc); err != nil {
+		return fmt.Errorf("%s: document %d is not JSON: %w", mw.file.Name, mw.file.Documents, err)
+	}
+	mw.file.Documents++
+	mw.m.Documents++
+	mw.m.addField("", doc)
+	return nil
+}
+
+// addField adds a value and its members to the field stats
+func (m *Manifest) addField(path string, v interface{}) {
+	stats := m.Fields[path]
+	if stats == nil {
+		stats = &FieldStats{Types: make(map[string]int)}
+		m.Fields[path] = stats
+	}
+	stats.Count++
+	stats.Types[jsonTypeOf(v)]++
+
+	switch v := v.(type) {
+	case float64:
+		if stats.Min == nil || v < *stats.Min {
+			stats.Min = &v
+		}
+		if stats.Max == nil || v > *stats.Max {
+			stats.Max = &v
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if stats.MinLength == nil || length < *stats.MinLength {
+			stats.MinLength = &length
+		}
+		if stats.MaxLength == nil || length > *stats.MaxLength {
+			stats.MaxLength = &length
+		}
+	case map[string]interface{}:
+		for key, member := range v {
+			m.addField(childPath(path, key), member)
+		}
+	case []interface{}:
+		for _, item := range v {
+			m.addField(path+"/*", item)
+		}
+	}
+}
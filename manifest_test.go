@@ -0,0 +1,127 @@
+package schemagen
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+)
+
+// Test manifests of datasets written as NDJSON files
+func TestManifest(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1, "maximum": 100},
+			"name": {"type": "string", "minLength": 3, "maxLength": 8},
+			"tags": {"type": "array", "minItems": 1, "maxItems": 3, "items": {"type": "string"}},
+			"note": {"type": ["string", "null"]}
+		},
+		"required": ["id", "name", "tags", "note"]
+	}`)
+	gen := NewGenerator().SetSeed(42)
+	m := NewManifest(gen, schema)
+
+	var first, second bytes.Buffer
+	if err := gen.GenerateNDJSON(context.Background(), m.File("part-0.ndjson", &first), schema, 300); err != nil {
+		t.Fatalf("GenerateNDJSON() error = %v", err)
+	}
+	if err := gen.GenerateNDJSON(context.Background(), m.File("part-1.ndjson", &second), schema, 20); err != nil {
+		t.Fatalf("GenerateNDJSON() error = %v", err)
+	}
+
+	sum := sha256.Sum256(schema)
+	if m.SchemaSHA256 != hex.EncodeToString(sum[:]) || m.Seed != 42 || m.Documents != 320 {
+		t.Errorf("unexpected manifest %+v", m)
+	}
+	for i, buf := range []*bytes.Buffer{&first, &second} {
+		file := m.Files[i]
+		sum := sha256.Sum256(buf.Bytes())
+		if file.Bytes != int64(buf.Len()) || file.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("file %s: expected %d bytes with checksum %x, got %+v", file.Name, buf.Len(), sum, file)
+		}
+	}
+	if m.Files[0].Documents != 300 || m.Files[1].Documents != 20 {
+		t.Errorf("expected 300 and 20 documents, got %d and %d", m.Files[0].Documents, m.Files[1].Documents)
+	}
+
+	id := m.Fields["/id"]
+	if id.Count != 320 || id.Types["integer"] != 320 || *id.Min < 1 || *id.Max > 100 {
+		t.Errorf("unexpected id stats %+v", id)
+	}
+	name := m.Fields["/name"]
+	if *name.MinLength < 3 || *name.MaxLength > 8 {
+		t.Errorf("unexpected name lengths %d to %d", *name.MinLength, *name.MaxLength)
+	}
+	if tags := m.Fields["/tags/*"]; tags.Count < 320 || tags.Types["string"] != tags.Count {
+		t.Errorf("unexpected tag stats %+v", tags)
+	}
+	if note := m.Fields["/note"]; note.Types["null"] == 0 || note.Types["string"] == 0 {
+		t.Errorf("expected null and string notes, got %v", note.Types)
+	}
+
+	if _, err := json.Marshal(m); err != nil {
+		t.Errorf("Marshal() error = %v", err)
+	}
+}
+
+// Test lines that are not JSON are reported
+func TestManifestInvalidLine(t *testing.T) {
+	m := NewManifest(NewGenerator(), []byte(`{}`))
+	w := m.File("bad.ndjson", &bytes.Buffer{})
+	if _, err := w.Write([]byte("{\"a\": 1}\n{oops")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := w.Write([]byte("}\n")); err == nil {
+		t.Error("expected an error for a line that is not JSON")
+	}
+	if m.Documents != 1 {
+		t.Errorf("expected 1 document, got %d", m.Documents)
+	}
+}
+
+// Test manifests tell whether a dataset needs generating again
+func TestManifestUpToDate(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`)
+	var data bytes.Buffer
+	m := NewManifest(NewGenerator().SetSeed(7), schema)
+	if err := NewGenerator().SetSeed(7).GenerateNDJSON(context.Background(), m.File("ids.ndjson", &data), schema, 10); err != nil {
+		t.Fatalf("GenerateNDJSON() error = %v", err)
+	}
+
+	// The manifest is read back as a build would
+	encoded, _ := json.Marshal(m)
+	var saved Manifest
+	if err := json.Unmarshal(encoded, &saved); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	files := fstest.MapFS{"ids.ndjson": {Data: data.Bytes()}}
+	changed := fstest.MapFS{"ids.ndjson": {Data: append(bytes.Clone(data.Bytes()), '\n')}}
+
+	tests := []struct {
+		name   string
+		gen    *Generator
+		schema []byte
+		files  fstest.MapFS
+		want   bool
+	}{
+		{"unchanged", NewGenerator().SetSeed(7), schema, files, true},
+		{"schema", NewGenerator().SetSeed(7), []byte(`{"type": "object"}`), files, false},
+		{"seed", NewGenerator().SetSeed(8), schema, files, false},
+		{"option", NewGenerator().SetSeed(7).SetGenerateAllFields(true), schema, files, false},
+		{"bundle", NewGenerator().SetSeed(7).Use(Bundle{Name: "acme"}), schema, files, false},
+		{"file changed", NewGenerator().SetSeed(7), schema, changed, false},
+		{"file missing", NewGenerator().SetSeed(7), schema, fstest.MapFS{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := saved.UpToDate(tt.gen, tt.schema, tt.files); got != tt.want {
+				t.Errorf("UpToDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
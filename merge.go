@@ -0,0 +1,382 @@
+package schemagen
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// mergeAllOf combines a schema and its allOf subschemas into one schema that
+// generates values satisfying all of them: properties and required are
+// united, bounds take the tightest value and enums are intersected.
+// Subschemas that are themselves the same property are merged the same way.
+//
+// Constraints that cannot be combined keyword by keyword, such as two
+// different patterns or oneOf in several subschemas, keep the first one;
+// generated values are checked against every subschema, so the rest is
+// handled by retrying.
+func (c *compiler) mergeAllOf(schema *Schema, merging map[*Schema]bool) (*Schema, error) {
+	merged := *schema
+	merged.AllOf = nil
+	merging[schema] = true
+	defer delete(merging, schema)
+
+	for i := range schema.AllOf {
+		sub := &schema.AllOf[i]
+		if sub.Ref != "" {
+			target, err := c.resolve(sub.Ref)
+			if err != nil {
+				return nil, err
+			}
+			// Schemas of other documents are only checked, as their own
+			// references would resolve against the wrong document
+			if target.doc != c.doc {
+				continue
+			}
+			sub = target.schema
+		}
+		if merging[sub] {
+			continue
+		}
+		if len(sub.AllOf) > 0 {
+			var err error
+			if sub, err = c.mergeAllOf(sub, merging); err != nil {
+				return nil, err
+			}
+		}
+		if err := mergeSchema(&merged, sub); err != nil {
+			return nil, fmt.Errorf("allOf[%d]: %w", i, err)
+		}
+	}
+
+	if errs := merged.ValidateWithDetails(""); len(errs) > 0 {
+		return nil, fmt.Errorf("allOf subschemas cannot be satisfied together: %s", errs[0].Message)
+	}
+	return &merged, nil
+}
+
+// mergeSchema narrows dst to the values that also satisfy src. Maps and
+// slices of dst are replaced rather than changed, as they may be shared with
+// the schema dst was copied from.
+func mergeSchema(dst, src *Schema) error {
+	// Type and values
+	if !src.Type.IsEmpty() {
+		if dst.Type.IsEmpty() {
+			dst.Type = src.Type
+		} else {
+			types := intersectTypes(dst.Type.GetTypes(), src.Type.GetTypes())
+			if len(types) == 0 {
+				return fmt.Errorf("types %v and %v have nothing in common", dst.Type.GetTypes(), src.Type.GetTypes())
+			}
+			dst.Type = StringOrArray{Multiple: types, IsArray: true}
+			if len(types) == 1 {
+				dst.Type = StringOrArray{Single: types[0]}
+			}
+		}
+	}
+	if src.Const != nil {
+		if dst.Const != nil && !jsonEqual(dst.Const, src.Const) {
+			return fmt.Errorf("const %v conflicts with const %v", src.Const, dst.Const)
+		}
+		dst.Const = src.Const
+	}
+	if len(src.Enum) > 0 {
+		dstEnum, dstLabels := dst.Enum, dst.EnumVarNames
+		if len(dst.Enum) == 0 {
+			dst.Enum = src.Enum
+		} else {
+			var common []interface{}
+			for _, v := range dst.Enum {
+				if slices.ContainsFunc(src.Enum, func(w interface{}) bool { return jsonEqual(v, w) }) {
+					common = append(common, v)
+				}
+			}
+			if len(common) == 0 {
+				return fmt.Errorf("enums %v and %v have no value in common", dst.Enum, src.Enum)
+			}
+			dst.Enum = common
+		}
+		// Labels follow their values through the intersection
+		dst.EnumVarNames = mergeEnumLabels(dst.Enum, dstEnum, dstLabels, src.Enum, src.EnumVarNames)
+	}
+
+	// String
+	dst.MinLength = tighterInt(dst.MinLength, src.MinLength, maxInt)
+	dst.MaxLength = tighterInt(dst.MaxLength, src.MaxLength, minInt)
+	dst.Pattern = firstNonEmpty(dst.Pattern, src.Pattern)
+	dst.Format = firstNonEmpty(dst.Format, src.Format)
+	dst.ContentMediaType = firstNonEmpty(dst.ContentMediaType, src.ContentMediaType)
+
+	// Number
+	dst.ints = mergeIntegerBounds(dst, src)
+	dst.Minimum = tighterFloat(dst.Minimum, src.Minimum, math.Max)
+	dst.Maximum = tighterFloat(dst.Maximum, src.Maximum, math.Min)
+	dst.ExclusiveMinimum = tighterFloat(dst.ExclusiveMinimum, src.ExclusiveMinimum, math.Max)
+	dst.ExclusiveMaximum = tighterFloat(dst.ExclusiveMaximum, src.ExclusiveMaximum, math.Min)
+	dst.MultipleOf = tighterFloat(dst.MultipleOf, src.MultipleOf, commonMultiple)
+
+	// Object
+	if len(src.Properties) > 0 {
+		properties := make(map[string]*Schema, len(dst.Properties)+len(src.Properties))
+		for name, prop := range dst.Properties {
+			properties[name] = prop
+		}
+		for name, prop := range src.Properties {
+			properties[name] = allOfSchema(properties[name], prop)
+		}
+		dst.Properties = properties
+	}
+	for _, name := range src.Required {
+		if !slices.Contains(dst.Required, name) {
+			dst.Required = append(slices.Clip(dst.Required), name)
+		}
+	}
+	dst.AdditionalProperties = mergeAdditional(dst.AdditionalProperties, src.AdditionalProperties)
+	dst.MinProperties = tighterInt(dst.MinProperties, src.MinProperties, maxInt)
+	dst.MaxProperties = tighterInt(dst.MaxProperties, src.MaxProperties, minInt)
+	if src.PropertyNames != nil {
+		dst.PropertyNames = allOfSchema(dst.PropertyNames, src.PropertyNames)
+	}
+	if len(src.DependentRequired) > 0 {
+		deps := make(map[string][]string, len(dst.DependentRequired)+len(src.DependentRequired))
+		for name, required := range dst.DependentRequired {
+			deps[name] = required
+		}
+		for name, required := range src.DependentRequired {
+			for _, r := range required {
+				if !slices.Contains(deps[name], r) {
+					deps[name] = append(slices.Clip(deps[name]), r)
+				}
+			}
+		}
+		dst.DependentRequired = deps
+	}
+	if len(src.DependentSchemas) > 0 {
+		deps := make(map[string]*Schema, len(dst.DependentSchemas)+len(src.DependentSchemas))
+		for name, dep := range dst.DependentSchemas {
+			deps[name] = dep
+		}
+		for name, dep := range src.DependentSchemas {
+			deps[name] = allOfSchema(deps[name], dep)
+		}
+		dst.DependentSchemas = deps
+	}
+	if len(src.Dependencies) > 0 {
+		deps := make(map[string]interface{}, len(dst.Dependencies)+len(src.Dependencies))
+		for name, dep := range src.Dependencies {
+			deps[name] = dep
+		}
+		for name, dep := range dst.Dependencies {
+			deps[name] = dep
+		}
+		dst.Dependencies = deps
+	}
+
+	// Array
+	if dst.PrefixItems == nil {
+		dst.PrefixItems = src.PrefixItems
+	}
+	dst.Items = mergeItems(dst.Items, src.Items)
+	dst.MinItems = tighterInt(dst.MinItems, src.MinItems, maxInt)
+	dst.MaxItems = tighterInt(dst.MaxItems, src.MaxItems, minInt)
+	dst.UniqueItems = dst.UniqueItems || src.UniqueItems
+	dst.AdditionalItems = mergeAdditional(dst.AdditionalItems, src.AdditionalItems)
+
+	// Composition
+	if len(dst.OneOf) == 0 {
+		dst.OneOf = src.OneOf
+	}
+	if len(dst.AnyOf) == 0 {
+		dst.AnyOf = src.AnyOf
+	}
+	if src.Not != nil {
+		if dst.Not == nil {
+			dst.Not = src.Not
+		} else {
+			// Matching neither is not matching either one
+			dst.Not = &Schema{AnyOf: []Schema{*dst.Not, *src.Not}}
+		}
+	}
+
+	// Annotations and extensions
+	dst.Title = firstNonEmpty(dst.Title, src.Title)
+	dst.Description = firstNonEmpty(dst.Description, src.Description)
+	if dst.Default == nil {
+		dst.Default = src.Default
+	}
+	if len(dst.Examples) == 0 {
+		dst.Examples = src.Examples
+	}
+	if dst.Example == nil {
+		dst.Example = src.Example
+	}
+	dst.Locale = firstNonEmpty(dst.Locale, src.Locale)
+	dst.Sequence = dst.Sequence || src.Sequence
+	dst.Unique = dst.Unique || src.Unique
+	dst.Volatile = dst.Volatile || src.Volatile
+	dst.PII = dst.PII || src.PII
+	dst.Deprecated = dst.Deprecated || src.Deprecated
+	dst.Rectangular = dst.Rectangular || src.Rectangular
+	dst.Pool = firstNonEmpty(dst.Pool, src.Pool)
+	dst.PoolRef = firstNonEmpty(dst.PoolRef, src.PoolRef)
+	if dst.NullableRate == 0 {
+		dst.NullableRate = src.NullableRate
+	}
+	if dst.MissingRate == 0 {
+		dst.MissingRate = src.MissingRate
+	}
+	if dst.IncludeRate == 0 {
+		dst.IncludeRate = src.IncludeRate
+	}
+	dst.EnumLabelField = firstNonEmpty(dst.EnumLabelField, src.EnumLabelField)
+	return nil
+}
+
+// allOfSchema returns a schema requiring both a and b; a may be nil
+func allOfSchema(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+	return &Schema{AllOf: []Schema{*a, *b}}
+}
+
+// mergeEnumLabels returns the x-enum-varnames of merged enum values, taking
+// each label from whichever subschema has one
+func mergeEnumLabels(enum, dstEnum []interface{}, dstLabels []string, srcEnum []interface{}, srcLabels []string) []string {
+	if len(dstLabels) == 0 && len(srcLabels) == 0 {
+		return nil
+	}
+	label := func(v interface{}, values []interface{}, labels []string) (string, bool) {
+		if len(labels) != len(values) {
+			return "", false
+		}
+		i := slices.IndexFunc(values, func(w interface{}) bool { return jsonEqual(v, w) })
+		if i < 0 {
+			return "", false
+		}
+		return labels[i], true
+	}
+
+	labels := make([]string, len(enum))
+	for i, v := range enum {
+		var ok bool
+		if labels[i], ok = label(v, dstEnum, dstLabels); !ok {
+			if labels[i], ok = label(v, srcEnum, srcLabels); !ok {
+				return nil
+			}
+		}
+	}
+	return labels
+}
+
+// mergeAdditional combines two additionalProperties or additionalItems
+// values: false wins, and two schemas must both be satisfied
+func mergeAdditional(dst, src interface{}) interface{} {
+	switch {
+	case src == nil || src == true:
+		return dst
+	case dst == nil || dst == true || src == false:
+		return src
+	case dst == false:
+		return dst
+	}
+	return map[string]interface{}{"allOf": []interface{}{dst, src}}
+}
+
+// mergeItems combines two items values. Single schemas must both be
+// satisfied; tuples keep the first, which is checked against the other.
+func mergeItems(dst, src interface{}) interface{} {
+	if dst == nil {
+		return src
+	}
+	if src == nil {
+		return dst
+	}
+	_, dstTuple := dst.([]interface{})
+	_, srcTuple := src.([]interface{})
+	if dstTuple || srcTuple {
+		return dst
+	}
+	return map[string]interface{}{"allOf": []interface{}{dst, src}}
+}
+
+// intersectTypes returns the types allowed by both lists, where an integer
+// is also a number
+func intersectTypes(a, b []string) []string {
+	var types []string
+	add := func(t string) {
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	for _, t := range a {
+		switch {
+		case slices.Contains(b, t):
+			add(t)
+		case t == "integer" && slices.Contains(b, "number"):
+			add(t)
+		case t == "number" && slices.Contains(b, "integer"):
+			add("integer")
+		}
+	}
+	return types
+}
+
+// tighterInt combines two optional bounds with pick
+func tighterInt(a, b *int, pick func(x, y int) int) *int {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	v := pick(*a, *b)
+	return &v
+}
+
+func maxInt(x, y int) int { return max(x, y) }
+func minInt(x, y int) int { return min(x, y) }
+
+// tighterFloat combines two optional bounds with pick
+func tighterFloat(a, b *float64, pick func(x, y float64) float64) *float64 {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	v := pick(*a, *b)
+	return &v
+}
+
+// commonMultiple returns a number that is a multiple of both a and b when
+// one can be found, and a otherwise
+func commonMultiple(a, b float64) float64 {
+	switch {
+	case isMultiple(a, b):
+		return a
+	case isMultiple(b, a):
+		return b
+	case a == math.Trunc(a) && b == math.Trunc(b):
+		x, y := int64(a), int64(b)
+		for y != 0 {
+			x, y = y, x%y
+		}
+		return a / float64(x) * b
+	}
+	return a
+}
+
+// isMultiple reports whether a is a whole multiple of b
+func isMultiple(a, b float64) bool {
+	q := a / b
+	return math.Abs(q-math.Round(q)) < 1e-9
+}
+
+// firstNonEmpty returns a, or b when a is empty
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,108 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test generated values satisfy every allOf subschema
+func TestGenerateAllOfMerged(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{
+			name: "properties and required",
+			schema: `{"allOf": [
+				{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]},
+				{"type": "object", "properties": {"age": {"type": "integer"}}, "required": ["age"]}
+			]}`,
+		},
+		{
+			name:   "tightest bounds",
+			schema: `{"allOf": [{"type": "number", "minimum": 10, "maximum": 100}, {"type": "integer", "maximum": 12}, {"multipleOf": 4}]}`,
+		},
+		{
+			name:   "intersected enums",
+			schema: `{"allOf": [{"enum": ["a", "b", "c"]}, {"enum": ["c", "b", "d"]}, {"not": {"const": "b"}}]}`,
+		},
+		{
+			name:   "string lengths",
+			schema: `{"type": "string", "minLength": 3, "allOf": [{"maxLength": 4}, {"minLength": 4}]}`,
+		},
+		{
+			name: "same property in several subschemas",
+			schema: `{"allOf": [
+				{"properties": {"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 5}}, "required": ["tags"]},
+				{"properties": {"tags": {"minItems": 2, "items": {"minLength": 2}}}}
+			]}`,
+		},
+		{
+			name: "references and nested allOf",
+			schema: `{
+				"allOf": [{"$ref": "#/$defs/named"}, {"allOf": [{"$ref": "#/$defs/dated"}]}],
+				"$defs": {
+					"named": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]},
+					"dated": {"type": "object", "properties": {"at": {"type": "string", "format": "date"}}, "required": ["at"]}
+				}
+			}`,
+		},
+		{
+			name:   "patterns that cannot be merged",
+			schema: `{"allOf": [{"type": "string", "pattern": "^[ab]{4}$"}, {"pattern": "a"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			plan, err := gen.prepare([]byte(tt.schema), "")
+			if err != nil {
+				t.Fatalf("prepare() error = %v", err)
+			}
+			for i := 0; i < 20; i++ {
+				result, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if errs := validateInstance(plan, result, ""); len(errs) > 0 {
+					t.Fatalf("Generated %v violates the schema: %v", result, errs[0])
+				}
+			}
+		})
+	}
+}
+
+// Test enum labels stay with their values when enums are intersected
+func TestMergeEnumLabels(t *testing.T) {
+	dst := &Schema{Enum: []interface{}{"a", "b", "c"}, EnumVarNames: []string{"A", "B", "C"}}
+	if err := mergeSchema(dst, &Schema{Enum: []interface{}{"c", "a"}}); err != nil {
+		t.Fatalf("mergeSchema() error = %v", err)
+	}
+	if !reflect.DeepEqual(dst.EnumVarNames, []string{"A", "C"}) {
+		t.Errorf("Expected labels [A C], got %v", dst.EnumVarNames)
+	}
+}
+
+func TestGenerateAllOfContradictions(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"types", `{"allOf": [{"type": "string"}, {"type": "integer"}]}`, "nothing in common"},
+		{"enums", `{"allOf": [{"enum": [1, 2]}, {"enum": [3]}]}`, "no value in common"},
+		{"consts", `{"allOf": [{"const": 1}, {"const": 2}]}`, "conflicts"},
+		{"bounds", `{"allOf": [{"type": "integer", "minimum": 10}, {"maximum": 5}]}`, "cannot be satisfied together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator().Generate([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package schemagen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+)
+
+// GenerateMultipart generates a multipart/form-data request body for an
+// object schema, as used by OpenAPI operations with multipart request
+// bodies. It returns the body and the matching Content-Type header.
+//
+// Each property becomes a part. Binary properties (format "binary" or a
+// contentMediaType) become file parts with random content; objects are sent
+// as application/json; arrays of scalars or files repeat the part once per
+// item; everything else is a text field. encoding maps property names to a
+// part Content-Type, like the OpenAPI encoding object, and may be nil.
+func (g *Generator) GenerateMultipart(schemaJSON []byte, encoding map[string]string) (io.Reader, string, error) {
+	plan, err := g.prepare(schemaJSON, "object")
+	if err != nil {
+		return nil, "", err
+	}
+
+	value, err := g.generate(newGenState(context.Background()), plan, "", 0)
+	if err != nil {
+		return nil, "", err
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("expected object at root, got %T", value)
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	// A boundary from the generator keeps bodies reproducible from the seed
+	if err := w.SetBoundary(fmt.Sprintf("schemagen%016x", g.rand.Uint64())); err != nil {
+		return nil, "", err
+	}
+
+	for _, name := range sortedKeys(obj) {
+		if err := g.writeParts(w, name, plan.property(name), obj[name], encoding[name]); err != nil {
+			return nil, "", fmt.Errorf("failed to write part %s: %w", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return &body, w.FormDataContentType(), nil
+}
+
+// writeParts writes the parts for one property
+func (g *Generator) writeParts(w *multipart.Writer, name string, n *node, value interface{}, contentType string) error {
+	if n != nil && n.binary() {
+		return g.writeFile(w, name, n, contentType)
+	}
+
+	switch value := value.(type) {
+	case []interface{}:
+		var items *node
+		if n != nil {
+			items = n.items
+		}
+		if items != nil && items.binary() {
+			for range value {
+				if err := g.writeFile(w, name, items, contentType); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if isScalarList(value) && contentType == "" {
+			for _, item := range value {
+				if err := w.WriteField(name, scalarText(item)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return writeJSONPart(w, name, value, contentType)
+	case map[string]interface{}:
+		return writeJSONPart(w, name, value, contentType)
+	default:
+		if contentType == "" {
+			return w.WriteField(name, scalarText(value))
+		}
+		part, err := w.CreatePart(partHeader(name, "", contentType))
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(part, scalarText(value))
+		return err
+	}
+}
+
+// writeFile writes a file part with random content
+func (g *Generator) writeFile(w *multipart.Writer, name string, n *node, contentType string) error {
+	if contentType == "" {
+		contentType = n.schema.ContentMediaType
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	ext := ".bin"
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		ext = exts[0]
+	}
+
+	part, err := w.CreatePart(partHeader(name, name+ext, contentType))
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(g.randomBytes(n))
+	return err
+}
+
+// randomBytes returns file content within the schema's length limits,
+// counted in bytes
+func (g *Generator) randomBytes(n *node) []byte {
+	length := n.minLength
+	if n.maxLength > n.minLength {
+		length = n.minLength + g.rand.Intn(n.maxLength-n.minLength+1)
+	}
+	data := make([]byte, length)
+	g.rand.Read(data)
+	return data
+}
+
+// writeJSONPart writes a value as a JSON part
+func writeJSONPart(w *multipart.Writer, name string, value interface{}, contentType string) error {
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	part, err := w.CreatePart(partHeader(name, "", contentType))
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
+
+// partHeader builds the headers of a form-data part
+func partHeader(name, filename, contentType string) textproto.MIMEHeader {
+	disposition := fmt.Sprintf(`form-data; name=%q`, name)
+	if filename != "" {
+		disposition += fmt.Sprintf(`; filename=%q`, filename)
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", disposition)
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+// binary reports whether a schema describes file content rather than text
+func (n *node) binary() bool {
+	return n.schema.Format == "binary" || n.schema.ContentMediaType != ""
+}
+
+// isScalarList reports whether every item of a list is a scalar
+func isScalarList(items []interface{}) bool {
+	for _, item := range items {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// scalarText formats a scalar the way it is sent in a form field
+func scalarText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,105 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"testing"
+)
+
+func TestGenerateMultipart(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"title": {"type": "string", "minLength": 1},
+			"count": {"type": "integer", "minimum": 1, "maximum": 9},
+			"avatar": {"type": "string", "contentMediaType": "image/png", "minLength": 8, "maxLength": 8},
+			"attachments": {"type": "array", "minItems": 2, "maxItems": 2, "items": {"type": "string", "format": "binary"}},
+			"meta": {"type": "object", "properties": {"source": {"type": "string"}}, "required": ["source"]},
+			"labels": {"type": "array", "minItems": 2, "maxItems": 2, "items": {"type": "string"}},
+			"notes": {"type": "string"}
+		},
+		"required": ["title", "count", "avatar", "attachments", "meta", "labels", "notes"]
+	}`)
+
+	gen := NewGenerator().SetSeed(42)
+	body, contentType, err := gen.GenerateMultipart(schema, map[string]string{"notes": "text/markdown"})
+	if err != nil {
+		t.Fatalf("GenerateMultipart() error = %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Unexpected content type %q: %v", contentType, err)
+	}
+
+	type part struct {
+		filename    string
+		contentType string
+		data        []byte
+	}
+	parts := make(map[string][]part)
+	r := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart() error = %v", err)
+		}
+		data, _ := io.ReadAll(p)
+		parts[p.FormName()] = append(parts[p.FormName()], part{p.FileName(), p.Header.Get("Content-Type"), data})
+	}
+
+	if avatar := parts["avatar"]; len(avatar) != 1 || avatar[0].contentType != "image/png" || avatar[0].filename != "avatar.png" || len(avatar[0].data) != 8 {
+		t.Errorf("Unexpected avatar part %+v", avatar)
+	}
+	if attachments := parts["attachments"]; len(attachments) != 2 || attachments[0].contentType != "application/octet-stream" || attachments[0].filename == "" {
+		t.Errorf("Unexpected attachment parts %+v", attachments)
+	}
+	if labels := parts["labels"]; len(labels) != 2 || labels[0].filename != "" {
+		t.Errorf("Expected one text part per label, got %+v", labels)
+	}
+	if notes := parts["notes"]; len(notes) != 1 || notes[0].contentType != "text/markdown" {
+		t.Errorf("Expected encoding to set the notes content type, got %+v", notes)
+	}
+
+	meta := parts["meta"]
+	if len(meta) != 1 || meta[0].contentType != "application/json" {
+		t.Fatalf("Unexpected meta part %+v", meta)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(meta[0].data, &obj); err != nil || obj["source"] == nil {
+		t.Errorf("Expected JSON object in meta part, got %s", meta[0].data)
+	}
+
+	if len(parts["title"]) != 1 || len(parts["count"]) != 1 {
+		t.Errorf("Expected title and count fields, got %v", parts)
+	}
+}
+
+// Test multipart bodies are reproducible from the seed
+func TestGenerateMultipartDeterministic(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"file": {"type": "string", "format": "binary"}}, "required": ["file"]}`)
+
+	read := func() (string, string) {
+		body, contentType, err := NewGenerator().SetSeed(7).GenerateMultipart(schema, nil)
+		if err != nil {
+			t.Fatalf("GenerateMultipart() error = %v", err)
+		}
+		data, _ := io.ReadAll(body)
+		return string(data), contentType
+	}
+
+	body1, ct1 := read()
+	body2, ct2 := read()
+	if body1 != body2 || ct1 != ct2 {
+		t.Error("Expected identical bodies for the same seed")
+	}
+
+	if _, _, err := NewGenerator().GenerateMultipart([]byte(`{"type": "string"}`), nil); err == nil {
+		t.Error("Expected error for a non-object schema")
+	}
+}
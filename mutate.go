@@ -0,0 +1,164 @@
+package schemagen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// mutation is one possible change to a document
+type mutation struct {
+	kind  string // "change", "add" or "remove"
+	path  string
+	apply func() error
+}
+
+// Mutate applies n random schema-respecting mutations to an existing JSON
+// document and returns the result. A mutation either changes a scalar to
+// another valid value (a number within its bounds, a different enum value,
+// a new string), adds a missing optional property or removes a present one.
+// Properties involved in dependencies are never added or removed, and values
+// using x-sequence, x-unique or pools are never changed. Every mutated
+// document is checked against the schema, so keywords that relate values,
+// such as minProperties or uniqueItems, hold too, and x-enum-label-field
+// labels follow their enum values.
+//
+// This is useful for differential testing: a valid document stays valid, but
+// differs from the original in n places.
+func (g *Generator) Mutate(doc []byte, schemaJSON []byte, n int) ([]byte, error) {
+	plan, err := g.prepare(schemaJSON, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(doc, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	st := newGenState(context.Background())
+	for i := 0; i < n; i++ {
+		mutated, err := g.mutateOnce(st, plan, value)
+		if err != nil {
+			return nil, err
+		}
+		if mutated == nil {
+			g.warnf("document has nothing left to mutate after %d mutations", i)
+			break
+		}
+		value = mutated
+	}
+
+	return json.Marshal(value)
+}
+
+// mutateOnce applies one random mutation to a copy of doc and returns it,
+// trying the mutations in random order until one gives a document that
+// differs from doc and has no more validation errors. It returns nil when
+// none does.
+func (g *Generator) mutateOnce(st *genState, plan *node, doc interface{}) (interface{}, error) {
+	before := len(validateInstance(plan, doc, ""))
+	for _, i := range g.rand.Perm(len(g.mutations(st, plan, doc))) {
+		candidate := deepCopy(doc)
+		m := g.mutations(st, plan, candidate)[i]
+		if err := m.apply(); err != nil {
+			return nil, fmt.Errorf("failed to %s %s: %w", m.kind, m.path, err)
+		}
+		walkDocument(plan, candidate, "", nil, func(n *node, v interface{}, path string, set func(interface{})) {
+			if obj, ok := v.(map[string]interface{}); ok {
+				if n = matching(n, obj); n != nil {
+					applyEnumLabels(n, obj)
+				}
+			}
+		})
+		if !jsonEqual(candidate, doc) && len(validateInstance(plan, candidate, "")) <= before {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// mutations lists the changes that can be made to a document
+func (g *Generator) mutations(st *genState, plan *node, doc interface{}) []mutation {
+	var mutations []mutation
+
+	walkDocument(plan, doc, "", nil, func(n *node, v interface{}, path string, set func(interface{})) {
+		obj, isObject := v.(map[string]interface{})
+		switch {
+		case isObject:
+			if n = matching(n, obj); n != nil {
+				mutations = append(mutations, g.propertyMutations(st, n, obj, path)...)
+			}
+		case set == nil, n.related():
+		default:
+			if size, ok := n.domainSize(); ok && size < 2 {
+				return
+			}
+			if _, isArray := v.([]interface{}); isArray {
+				return
+			}
+			mutations = append(mutations, mutation{kind: "change", path: path, apply: func() error {
+				value, err := g.retry("mutation", path, func() (interface{}, error) {
+					return g.generate(st, n, path, 0)
+				}, func(x interface{}) bool {
+					return !jsonEqual(x, v)
+				})
+				if err != nil {
+					return err
+				}
+				set(value)
+				return nil
+			}})
+		}
+	})
+
+	return mutations
+}
+
+// propertyMutations lists the optional properties of an object that can be
+// added or removed
+func (g *Generator) propertyMutations(st *genState, n *node, obj map[string]interface{}, path string) []mutation {
+	var mutations []mutation
+
+	for _, prop := range n.properties {
+		if prop.required || n.dependsOn(prop.name) {
+			continue
+		}
+
+		name, propNode := prop.name, prop.node
+		propPath := childPath(path, name)
+		if _, present := obj[name]; present {
+			mutations = append(mutations, mutation{kind: "remove", path: propPath, apply: func() error {
+				delete(obj, name)
+				return nil
+			}})
+			continue
+		}
+
+		mutations = append(mutations, mutation{kind: "add", path: propPath, apply: func() error {
+			value, err := g.generate(st, propNode, propPath, 0)
+			if err != nil {
+				return err
+			}
+			obj[name] = value
+			return nil
+		}})
+	}
+
+	return mutations
+}
+
+// dependsOn reports whether a property triggers or is required by a dependency
+func (n *node) dependsOn(name string) bool {
+	for _, dep := range n.dependencies {
+		if dep.name == name {
+			return true
+		}
+		for _, required := range dep.required {
+			if required == name {
+				return true
+			}
+		}
+	}
+	return false
+}
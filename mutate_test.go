@@ -0,0 +1,159 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMutate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		n      int
+		want   string
+	}{
+		{
+			name:   "swap enum value",
+			schema: `{"type": "object", "properties": {"status": {"enum": ["open", "closed"]}}, "required": ["status"]}`,
+			doc:    `{"status": "open"}`,
+			n:      1,
+			want:   `{"status": "closed"}`,
+		},
+		{
+			name:   "add optional field",
+			schema: `{"type": "object", "properties": {"x": {"const": 1}}}`,
+			doc:    `{}`,
+			n:      1,
+			want:   `{"x": 1}`,
+		},
+		{
+			name:   "remove optional field",
+			schema: `{"type": "object", "properties": {"x": {"const": 1}}}`,
+			doc:    `{"x": 1}`,
+			n:      1,
+			want:   `{}`,
+		},
+		{
+			name:   "nothing to mutate",
+			schema: `{"type": "object", "properties": {"id": {"const": 1}}, "required": ["id"]}`,
+			doc:    `{"id": 1}`,
+			n:      3,
+			want:   `{"id": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			result, err := gen.Mutate([]byte(tt.doc), []byte(tt.schema), tt.n)
+			if err != nil {
+				t.Fatalf("Mutate() error = %v", err)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(result, &got); err != nil {
+				t.Fatalf("Mutate() returned invalid JSON: %v", err)
+			}
+			json.Unmarshal([]byte(tt.want), &want)
+			if !jsonEqual(got, want) {
+				t.Errorf("Expected %s, got %s", tt.want, result)
+			}
+		})
+	}
+}
+
+// Test mutated documents stay valid and differ from the original
+func TestMutateKeepsDocumentValid(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1, "maximum": 1000},
+			"price": {"type": "number", "minimum": 0, "exclusiveMaximum": 10},
+			"status": {"enum": ["new", "paid", "shipped"]},
+			"note": {"type": "string", "maxLength": 5},
+			"items": {"type": "array", "minItems": 1, "items": {
+				"type": "object",
+				"properties": {"sku": {"type": "string", "pattern": "^[A-Z]{3}$"}, "qty": {"type": "integer", "minimum": 1, "maximum": 9}},
+				"required": ["sku", "qty"]
+			}}
+		},
+		"required": ["id", "price", "status", "items"]
+	}`)
+
+	parsed, _ := ParseSchema(schema)
+	plan, _ := compile(parsed)
+
+	gen := NewGenerator().SetSeed(42).SetGenerateAllFields(true)
+	for i := 0; i < 20; i++ {
+		doc, err := gen.GenerateBytes(schema)
+		if err != nil {
+			t.Fatalf("GenerateBytes() error = %v", err)
+		}
+
+		mutated, err := gen.Mutate(doc, schema, 3)
+		if err != nil {
+			t.Fatalf("Mutate() error = %v", err)
+		}
+
+		var before, after interface{}
+		json.Unmarshal(doc, &before)
+		json.Unmarshal(mutated, &after)
+		if errs := validateInstance(plan, after, ""); len(errs) > 0 {
+			t.Fatalf("Mutated document is invalid: %v\n%s", errs, mutated)
+		}
+		if jsonEqual(before, after) {
+			t.Errorf("Expected mutated document to differ from %s", doc)
+		}
+	}
+}
+
+// Test mutations respect keywords that relate values to each other
+func TestMutateRelatedKeywords(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+	}{
+		{"minProperties", `{"type": "object", "properties": {"a": {"type": "integer"}, "b": {"type": "integer"}}, "minProperties": 2}`, `{"a": 1, "b": 2}`},
+		{"maxProperties", `{"type": "object", "properties": {"a": {"type": "integer"}, "b": {"type": "integer"}}, "maxProperties": 1}`, `{"a": 1}`},
+		{"uniqueItems", `{"type": "array", "uniqueItems": true, "items": {"type": "integer", "minimum": 1, "maximum": 3}}`, `[1, 2, 3]`},
+		{"enum label", `{"type": "object", "properties": {"s": {"enum": ["A", "I"], "x-enum-varnames": ["Active", "Inactive"], "x-enum-label-field": "label"}, "label": {"type": "string"}}, "required": ["s", "label"]}`, `{"s": "A", "label": "Active"}`},
+		{"allOf root", `{"allOf": [{"type": "object", "properties": {"n": {"type": "integer", "minimum": 0, "maximum": 1000}}, "required": ["n"]}, {"required": ["n"]}]}`, `{"n": 5}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, _ := ParseSchema([]byte(tt.schema))
+			plan, _ := compile(parsed)
+			var original interface{}
+			json.Unmarshal([]byte(tt.doc), &original)
+
+			for seed := int64(0); seed < 10; seed++ {
+				result, err := NewGenerator().SetSeed(seed).Mutate([]byte(tt.doc), []byte(tt.schema), 1)
+				if err != nil {
+					t.Fatalf("Mutate() error = %v", err)
+				}
+				var got interface{}
+				json.Unmarshal(result, &got)
+				if errs := validateInstance(plan, got, ""); len(errs) > 0 {
+					t.Fatalf("seed %d: mutated document %s is invalid: %v", seed, result, errs)
+				}
+				if obj, ok := got.(map[string]interface{}); ok && obj["label"] != nil {
+					if want := map[string]string{"A": "Active", "I": "Inactive"}[obj["s"].(string)]; obj["label"] != want {
+						t.Errorf("seed %d: expected label %s, got %s", seed, want, result)
+					}
+				}
+				if tt.name == "allOf root" && jsonEqual(got, original) {
+					t.Errorf("seed %d: expected the document under allOf to change", seed)
+				}
+			}
+		})
+	}
+}
+
+func TestMutateInvalidDocument(t *testing.T) {
+	if _, err := NewGenerator().Mutate([]byte(`{`), []byte(`{"type": "object"}`), 1); err == nil {
+		t.Error("Expected error for invalid document")
+	}
+}
@@ -0,0 +1,225 @@
+package schemagen
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// NearMiss is a document that violates exactly one constraint of its schema
+// by the smallest possible amount
+type NearMiss struct {
+	Value   interface{} `json:"value"`
+	Path    string      `json:"path"`    // JSON Pointer of the violating value
+	Keyword string      `json:"keyword"` // violated keyword, e.g. "maxLength"
+}
+
+// nearMissCandidate is one way to break a constraint of a document
+type nearMissCandidate struct {
+	keyword string
+	path    string
+	apply   func()
+}
+
+// GenerateNearMiss generates a valid document and breaks one random
+// constraint by the smallest possible amount: strings one character too
+// short or too long, numbers just past their bounds, one required property
+// missing, one item too few or too many. It is meant for testing that
+// validators enforce exact boundaries.
+func (g *Generator) GenerateNearMiss(schemaJSON []byte) (*NearMiss, error) {
+	misses, err := g.GenerateNearMisses(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	return &misses[g.rand.Intn(len(misses))], nil
+}
+
+// GenerateNearMisses generates a valid document and returns one near miss
+// for every constraint of the document that can be broken on its own
+func (g *Generator) GenerateNearMisses(schemaJSON []byte) ([]NearMiss, error) {
+	plan, err := g.prepare(schemaJSON, "")
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := g.generate(newGenState(context.Background()), plan, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if errs := validateInstance(plan, base, ""); len(errs) > 0 {
+		return nil, fmt.Errorf("generated document is not valid: %w", errs[0])
+	}
+
+	var misses []NearMiss
+	copied := deepCopy(base)
+	for i := range nearMissCandidates(plan, &copied) {
+		// Break candidate i of a fresh copy, so candidates never add up
+		doc := deepCopy(base)
+		candidate := nearMissCandidates(plan, &doc)[i]
+		candidate.apply()
+
+		// Keep only changes that break exactly the intended constraint
+		if errs := validateInstance(plan, doc, ""); len(errs) == 1 {
+			misses = append(misses, NearMiss{Value: doc, Path: candidate.path, Keyword: candidate.keyword})
+		}
+	}
+
+	if len(misses) == 0 {
+		return nil, fmt.Errorf("schema has no constraint that can be broken on its own")
+	}
+	return misses, nil
+}
+
+// nearMissCandidates lists the ways to break the constraints of a document,
+// in a stable order
+func nearMissCandidates(plan *node, doc *interface{}) []nearMissCandidate {
+	var candidates []nearMissCandidate
+	setRoot := func(v interface{}) { *doc = v }
+	walkDocument(plan, *doc, "", setRoot, func(n *node, v interface{}, path string, set func(interface{})) {
+		candidates = append(candidates, valueCandidates(n, v, path, set)...)
+	})
+	return candidates
+}
+
+// valueCandidates lists the ways to break the constraints on one value
+func valueCandidates(n *node, v interface{}, path string, set func(interface{})) []nearMissCandidate {
+	var candidates []nearMissCandidate
+	add := func(keyword string, apply func()) {
+		candidates = append(candidates, nearMissCandidate{keyword: keyword, path: path, apply: apply})
+	}
+	schema := n.schema
+
+	switch v := v.(type) {
+	case string:
+		runes := []rune(v)
+		if schema.MinLength != nil && *schema.MinLength > 0 {
+			add("minLength", func() { set(string(runes[:*schema.MinLength-1])) })
+		}
+		if schema.MaxLength != nil {
+			add("maxLength", func() { set(padRunes(runes, *schema.MaxLength+1)) })
+		}
+		if len(schema.Enum) > 0 || schema.Const != nil {
+			add(enumKeyword(schema), func() { set(v + "_") })
+		}
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, present := v[name]; present {
+				add("required", func() { delete(v, name) })
+			}
+		}
+		if schema.AdditionalProperties == false {
+			add("additionalProperties", func() { v[unusedKey(v)] = true })
+		}
+	case []interface{}:
+		if schema.MinItems != nil && *schema.MinItems > 0 && len(v) >= *schema.MinItems {
+			add("minItems", func() { set(v[:*schema.MinItems-1]) })
+		}
+		if schema.MaxItems != nil && len(v) > 0 && !schema.UniqueItems {
+			add("maxItems", func() {
+				grown := append([]interface{}{}, v...)
+				for len(grown) <= *schema.MaxItems {
+					grown = append(grown, deepCopy(v[len(v)-1]))
+				}
+				set(grown)
+			})
+		}
+	default:
+		num, ok := toFloat(v)
+		if !ok {
+			return candidates
+		}
+		integer := matchesAnyType(int64(0), n.types) && !matchesAnyType(0.5, n.types)
+		below := func(bound float64) float64 {
+			if integer {
+				return math.Ceil(bound) - 1
+			}
+			return math.Nextafter(bound, math.Inf(-1))
+		}
+		above := func(bound float64) float64 {
+			if integer {
+				return math.Floor(bound) + 1
+			}
+			return math.Nextafter(bound, math.Inf(1))
+		}
+		number := func(f float64) interface{} {
+			if integer {
+				return int64(f)
+			}
+			return f
+		}
+
+		if schema.Minimum != nil {
+			add("minimum", func() { set(number(below(*schema.Minimum))) })
+		}
+		if schema.Maximum != nil {
+			add("maximum", func() { set(number(above(*schema.Maximum))) })
+		}
+		// The exclusive bound itself is the closest number outside it; an
+		// integer has to be rounded away from the range
+		onOrBelow := func(bound float64) float64 {
+			if integer {
+				return math.Floor(bound)
+			}
+			return bound
+		}
+		onOrAbove := func(bound float64) float64 {
+			if integer {
+				return math.Ceil(bound)
+			}
+			return bound
+		}
+		if schema.ExclusiveMinimum != nil {
+			add("exclusiveMinimum", func() { set(number(onOrBelow(*schema.ExclusiveMinimum))) })
+		}
+		if schema.ExclusiveMaximum != nil {
+			add("exclusiveMaximum", func() { set(number(onOrAbove(*schema.ExclusiveMaximum))) })
+		}
+		if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+			step := *schema.MultipleOf / 2
+			if integer {
+				step = 1
+			}
+			add("multipleOf", func() { set(number(num + step)) })
+		}
+		if integer {
+			add("type", func() { set(num + 0.5) })
+		}
+		if len(schema.Enum) > 0 || schema.Const != nil {
+			add(enumKeyword(schema), func() { set(num + 1) })
+		}
+	}
+
+	return candidates
+}
+
+// enumKeyword returns the keyword restricting a value to fixed values
+func enumKeyword(schema *Schema) string {
+	if schema.Const != nil {
+		return "const"
+	}
+	return "enum"
+}
+
+// padRunes extends a string to length characters by repeating its last character
+func padRunes(runes []rune, length int) string {
+	pad := 'a'
+	if len(runes) > 0 {
+		pad = runes[len(runes)-1]
+	}
+	padded := append([]rune{}, runes...)
+	for len(padded) < length {
+		padded = append(padded, pad)
+	}
+	return string(padded)
+}
+
+// unusedKey returns a property name that is not in the object
+func unusedKey(obj map[string]interface{}) string {
+	key := "unexpected"
+	for i := 1; ; i++ {
+		if _, taken := obj[key]; !taken {
+			return key
+		}
+		key = fmt.Sprintf("unexpected%d", i)
+	}
+}
@@ -0,0 +1,109 @@
+package schemagen
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGenerateNearMisses(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"code": {"type": "string", "minLength": 3, "maxLength": 5},
+			"age": {"type": "integer", "minimum": 18, "maximum": 65},
+			"ratio": {"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1},
+			"tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}}
+		},
+		"required": ["code", "age", "ratio", "tags"],
+		"additionalProperties": false
+	}`)
+
+	gen := NewGenerator().SetSeed(42)
+	misses, err := gen.GenerateNearMisses(schema)
+	if err != nil {
+		t.Fatalf("GenerateNearMisses() error = %v", err)
+	}
+
+	parsed, _ := ParseSchema(schema)
+	plan, _ := compile(parsed)
+
+	found := make(map[string]NearMiss)
+	for _, miss := range misses {
+		found[miss.Path+" "+miss.Keyword] = miss
+		if errs := validateInstance(plan, miss.Value, ""); len(errs) != 1 {
+			t.Errorf("%s %s: expected exactly one violation, got %v", miss.Path, miss.Keyword, errs)
+		}
+	}
+
+	tests := []struct {
+		key   string
+		check func(doc map[string]interface{}) bool
+	}{
+		{"/code minLength", func(doc map[string]interface{}) bool { return len(doc["code"].(string)) == 2 }},
+		{"/code maxLength", func(doc map[string]interface{}) bool { return len(doc["code"].(string)) == 6 }},
+		{"/age minimum", func(doc map[string]interface{}) bool { return doc["age"] == int64(17) }},
+		{"/age maximum", func(doc map[string]interface{}) bool { return doc["age"] == int64(66) }},
+		{"/age type", func(doc map[string]interface{}) bool { return doc["age"].(float64) != math.Trunc(doc["age"].(float64)) }},
+		{"/ratio exclusiveMinimum", func(doc map[string]interface{}) bool { return doc["ratio"] == 0.0 }},
+		{"/ratio exclusiveMaximum", func(doc map[string]interface{}) bool { return doc["ratio"] == 1.0 }},
+		{"/tags minItems", func(doc map[string]interface{}) bool { return len(doc["tags"].([]interface{})) == 0 }},
+		{"/tags maxItems", func(doc map[string]interface{}) bool { return len(doc["tags"].([]interface{})) == 3 }},
+		{" required", func(doc map[string]interface{}) bool { return len(doc) == 3 }},
+		{" additionalProperties", func(doc map[string]interface{}) bool { return len(doc) == 5 }},
+	}
+
+	for _, tt := range tests {
+		miss, ok := found[tt.key]
+		if !ok {
+			t.Errorf("Missing near miss %q", tt.key)
+			continue
+		}
+		if !tt.check(miss.Value.(map[string]interface{})) {
+			t.Errorf("%s: unexpected document %v", tt.key, miss.Value)
+		}
+	}
+}
+
+// Test near misses on a scalar root and on a schema without constraints
+func TestGenerateNearMiss(t *testing.T) {
+	miss, err := NewGenerator().SetSeed(42).GenerateNearMiss([]byte(`{"type": "number", "minimum": 1.5, "maximum": 1.5}`))
+	if err != nil {
+		t.Fatalf("GenerateNearMiss() error = %v", err)
+	}
+	v := miss.Value.(float64)
+	if v != math.Nextafter(1.5, 0) && v != math.Nextafter(1.5, 2) {
+		t.Errorf("Expected the closest float to 1.5, got %v (%s)", v, miss.Keyword)
+	}
+
+	// A number misses an exclusive bound by hitting it; an integer by the
+	// closest integer on or past it
+	tests := []struct {
+		schema string
+		want   map[string]interface{}
+	}{
+		{`{"type": "number", "exclusiveMinimum": 0.5, "exclusiveMaximum": 2.5}`, map[string]interface{}{"exclusiveMinimum": 0.5, "exclusiveMaximum": 2.5}},
+		{`{"type": "integer", "exclusiveMinimum": 0.5, "exclusiveMaximum": 2.5}`, map[string]interface{}{"exclusiveMinimum": int64(0), "exclusiveMaximum": int64(3)}},
+	}
+	for _, tt := range tests {
+		misses, err := NewGenerator().SetSeed(42).GenerateNearMisses([]byte(tt.schema))
+		if err != nil {
+			t.Fatalf("GenerateNearMisses() error = %v", err)
+		}
+		seen := 0
+		for _, miss := range misses {
+			if want, ok := tt.want[miss.Keyword]; ok {
+				seen++
+				if miss.Value != want {
+					t.Errorf("%s %s: expected %v, got %v", tt.schema, miss.Keyword, want, miss.Value)
+				}
+			}
+		}
+		if seen != len(tt.want) {
+			t.Errorf("%s: expected near misses for %d bounds, got %v", tt.schema, len(tt.want), misses)
+		}
+	}
+
+	if _, err := NewGenerator().GenerateNearMiss([]byte(`{"type": "string"}`)); err == nil {
+		t.Error("Expected error for a schema without constraints")
+	}
+}
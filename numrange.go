@@ -0,0 +1,211 @@
+package schemagen
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// maxSafeNumber is the largest integer a float64 (and so every JSON parser
+// using doubles) represents exactly, 2^53. Exponential sampling stays within
+// ±maxSafeNumber.
+const maxSafeNumber = 1 << 53
+
+// OpenRangeMode selects how numbers are sampled when a schema leaves one or
+// both ends of the range open
+type OpenRangeMode int
+
+const (
+	// OpenRangeWindow fills open ends from a default window. With only a
+	// minimum, the window is slid up so it starts at the minimum when needed;
+	// with only a maximum, it is slid down. This is the default.
+	OpenRangeWindow OpenRangeMode = iota
+
+	// OpenRangeExponential samples the distance from the given bound
+	// log-uniformly over the whole safe range, so small values stay common
+	// while very large ones (timestamps, large IDs) still show up
+	OpenRangeExponential
+)
+
+// Window is a default numeric range used for open ends
+type Window struct {
+	Min float64
+	Max float64
+}
+
+// span returns the size of the window
+func (w Window) span() float64 {
+	return w.Max - w.Min
+}
+
+// OpenRange configures number generation for schemas without both bounds
+type OpenRange struct {
+	Mode    OpenRangeMode
+	Integer Window // default window for "integer"
+	Number  Window // default window for "number"
+}
+
+// DefaultOpenRange returns the open-range behavior of new generators:
+//
+//   - no bounds: integers and numbers are drawn from [0, 1000]
+//   - minimum only: [minimum, 1000], or [minimum, minimum+1000] when minimum
+//     is 1000 or more, e.g. minimum 1700000000 gives [1700000000, 1700001000]
+//   - maximum only: [0, maximum], or [maximum-1000, maximum] when maximum is
+//     0 or less
+func DefaultOpenRange() OpenRange {
+	return OpenRange{
+		Mode:    OpenRangeWindow,
+		Integer: Window{Min: 0, Max: 1000},
+		Number:  Window{Min: 0, Max: 1000},
+	}
+}
+
+// SetOpenRange configures how numbers are sampled when a schema does not
+// bound both ends of the range
+func (g *Generator) SetOpenRange(r OpenRange) *Generator {
+	g.OpenRange = r
+	return g
+}
+
+// numberBounds works out the closed range a number must be drawn from.
+// Exclusive bounds are turned into inclusive ones (the next integer, or the
+// next representable float). Open ends are reported separately and filled
+// according to the open-range mode.
+func (g *Generator) numberBounds(schema *Schema, isInteger bool) (min, max float64, openMin, openMax bool) {
+	openMin, openMax = true, true
+	min, max = math.Inf(-1), math.Inf(1)
+
+	if schema.Minimum != nil {
+		min, openMin = *schema.Minimum, false
+	}
+	if schema.ExclusiveMinimum != nil {
+		lo := math.Nextafter(*schema.ExclusiveMinimum, math.Inf(1))
+		if isInteger {
+			lo = math.Floor(*schema.ExclusiveMinimum) + 1
+		}
+		min, openMin = math.Max(min, lo), false
+	}
+	if schema.Maximum != nil {
+		max, openMax = *schema.Maximum, false
+	}
+	if schema.ExclusiveMaximum != nil {
+		hi := math.Nextafter(*schema.ExclusiveMaximum, math.Inf(-1))
+		if isInteger {
+			hi = math.Ceil(*schema.ExclusiveMaximum) - 1
+		}
+		max, openMax = math.Min(max, hi), false
+	}
+
+	window := g.OpenRange.Number
+	if isInteger {
+		window = g.OpenRange.Integer
+	}
+
+	switch {
+	case g.OpenRange.Mode == OpenRangeExponential:
+		if openMin {
+			min = math.Min(-maxSafeNumber, max)
+		}
+		if openMax {
+			max = math.Max(maxSafeNumber, min)
+		}
+	case openMin && openMax:
+		min, max = window.Min, window.Max
+	case openMax:
+		max = window.Max
+		if min >= window.Max {
+			max = min + window.span()
+		}
+	case openMin:
+		min = window.Min
+		if max <= window.Min {
+			min = max - window.span()
+		}
+	}
+
+	if isInteger {
+		min, max = math.Ceil(min), math.Floor(max)
+	}
+	return min, max, openMin, openMax
+}
+
+// sampleOpenRange draws a number whose distance from the closed end of the
+// range (or from zero, when both ends are open) is log-uniform
+func (g *Generator) sampleOpenRange(min, max float64, openMin, openMax, isInteger bool) float64 {
+	logUniform := func(span float64) float64 {
+		if span <= 0 {
+			return 0
+		}
+		offset := math.Expm1(g.rand.Float64() * math.Log1p(span))
+		if isInteger {
+			offset = math.Floor(offset)
+		}
+		return math.Min(offset, span)
+	}
+
+	switch {
+	case openMin && openMax:
+		if g.rand.Intn(2) == 0 {
+			return -logUniform(maxSafeNumber)
+		}
+		return logUniform(maxSafeNumber)
+	case openMax:
+		return min + logUniform(max-min)
+	default:
+		return max - logUniform(max-min)
+	}
+}
+
+// nearestMultiple returns the multiple of m in [min, max] nearest to v.
+// Multiples are worked out on the decimal values of the numbers, so that
+// multipleOf 0.1 gives 0.3 rather than 0.30000000000000004.
+func nearestMultiple(v, m, min, max float64) (float64, error) {
+	multiple, ok := multipleBetween(decimalRat(v), decimalRat(m), decimalRat(min), decimalRat(max))
+	if !ok {
+		return 0, keywordErrorf("multipleOf", "no multiple of %v between %v and %v", m, min, max)
+	}
+	result, _ := multiple.Float64()
+	return result, nil
+}
+
+// multipleBetween returns the multiple of step in [min, max] nearest to v,
+// or false when the range holds none
+func multipleBetween(v, step, min, max *big.Rat) (*big.Rat, bool) {
+	kLo := ratCeil(new(big.Rat).Quo(min, step))
+	kHi := ratFloor(new(big.Rat).Quo(max, step))
+	if kLo.Cmp(kHi) > 0 {
+		return nil, false
+	}
+
+	// Round v/step half up, then clamp the factor to the range
+	k := ratFloor(new(big.Rat).Add(new(big.Rat).Quo(v, step), big.NewRat(1, 2)))
+	if k.Cmp(kLo) < 0 {
+		k = kLo
+	}
+	if k.Cmp(kHi) > 0 {
+		k = kHi
+	}
+	return new(big.Rat).Mul(new(big.Rat).SetInt(k), step), true
+}
+
+// decimalRat returns the exact value of the shortest decimal that reads back
+// as f
+func decimalRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// ratFloor returns the largest integer not above r
+func ratFloor(r *big.Rat) *big.Int {
+	// Euclidean division rounds down as the denominator is positive
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
+
+// ratCeil returns the smallest integer not below r
+func ratCeil(r *big.Rat) *big.Int {
+	q := ratFloor(r)
+	if !r.IsInt() {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
@@ -0,0 +1,153 @@
+package schemagen
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// Test open ranges and exclusive bounds in the default window mode
+func TestGenerateNumberOpenRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		min, max float64
+	}{
+		{"no bounds", `{"type": "integer"}`, 0, 1000},
+		{"small minimum", `{"type": "integer", "minimum": 10}`, 10, 1000},
+		{"large minimum", `{"type": "integer", "minimum": 1700000000}`, 1700000000, 1700001000},
+		{"large number minimum", `{"type": "number", "minimum": 5000.5}`, 5000.5, 6000.5},
+		{"small maximum", `{"type": "integer", "maximum": 100}`, 0, 100},
+		{"negative maximum", `{"type": "integer", "maximum": -5}`, -1005, -5},
+		{"integer exclusive minimum", `{"type": "integer", "exclusiveMinimum": 5, "maximum": 6}`, 6, 6},
+		{"integer exclusive maximum", `{"type": "integer", "minimum": 4, "exclusiveMaximum": 5.5}`, 4, 5},
+		{"fractional exclusive minimum", `{"type": "integer", "exclusiveMinimum": 4.5, "maximum": 5}`, 5, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			for i := 0; i < 50; i++ {
+				result, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				v, _ := toFloat(result)
+				if v < tt.min || v > tt.max {
+					t.Fatalf("Expected value in [%v, %v], got %v", tt.min, tt.max, v)
+				}
+			}
+		})
+	}
+}
+
+// Test exclusive bounds on numbers are never reached, even at float extremes
+func TestGenerateNumberStrictBounds(t *testing.T) {
+	tests := []struct {
+		name        string
+		schema      string
+		exponential bool
+	}{
+		{"unit range", `{"type": "number", "exclusiveMinimum": 0, "exclusiveMaximum": 1}`, false},
+		{"one float between", `{"type": "number", "exclusiveMinimum": 1, "exclusiveMaximum": 1.0000000000000004}`, false},
+		{"widest range", `{"type": "number", "exclusiveMinimum": -1.7e308, "exclusiveMaximum": 1.7e308}`, false},
+		{"with multipleOf", `{"type": "number", "exclusiveMinimum": 0.3, "exclusiveMaximum": 0.5, "multipleOf": 0.1}`, false},
+		{"window above minimum", `{"type": "number", "exclusiveMinimum": 5000}`, false},
+		{"exponential maximum", `{"type": "number", "exclusiveMaximum": -1}`, true},
+		{"exponential both", `{"type": "number", "exclusiveMinimum": -1e-300, "exclusiveMaximum": 1e-300}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, _ := ParseSchema([]byte(tt.schema))
+			gen := NewGenerator().SetSeed(42)
+			if tt.exponential {
+				r := DefaultOpenRange()
+				r.Mode = OpenRangeExponential
+				gen.SetOpenRange(r)
+			}
+			for i := 0; i < 200; i++ {
+				result, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				v := result.(float64)
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					t.Fatalf("got %v", v)
+				}
+				if schema.ExclusiveMinimum != nil && v <= *schema.ExclusiveMinimum {
+					t.Fatalf("%v is not above exclusiveMinimum %v", v, *schema.ExclusiveMinimum)
+				}
+				if schema.ExclusiveMaximum != nil && v >= *schema.ExclusiveMaximum {
+					t.Fatalf("%v is not below exclusiveMaximum %v", v, *schema.ExclusiveMaximum)
+				}
+			}
+		})
+	}
+
+	_, err := NewGenerator().SetSeed(42).Generate([]byte(`{"type": "number", "exclusiveMinimum": 1, "exclusiveMaximum": 1.0000000000000002}`))
+	if err == nil || !strings.Contains(err.Error(), "greater than maximum") {
+		t.Errorf("expected an error when no number lies between the bounds, got %v", err)
+	}
+}
+
+// Test exponential sampling reaches large magnitudes while honoring bounds
+func TestGenerateNumberOpenRangeExponential(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		isInteger bool
+		check     func(v float64) bool
+	}{
+		{"minimum only", `{"type": "integer", "minimum": 100}`, true, func(v float64) bool { return v >= 100 }},
+		{"maximum only", `{"type": "number", "maximum": -1}`, false, func(v float64) bool { return v <= -1 }},
+		{"exclusive minimum", `{"type": "number", "exclusiveMinimum": 0}`, false, func(v float64) bool { return v > 0 }},
+		{"no bounds", `{"type": "integer"}`, true, func(v float64) bool { return math.Abs(v) <= maxSafeNumber }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := DefaultOpenRange()
+			r.Mode = OpenRangeExponential
+			gen := NewGenerator().SetSeed(42).SetOpenRange(r)
+
+			large := false
+			for i := 0; i < 200; i++ {
+				result, err := gen.Generate([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if _, ok := result.(int64); ok != tt.isInteger {
+					t.Fatalf("Unexpected result type %T", result)
+				}
+				v, _ := toFloat(result)
+				if !tt.check(v) {
+					t.Fatalf("Value %v is out of bounds", v)
+				}
+				if math.Abs(v) > 1e6 {
+					large = true
+				}
+			}
+			if !large {
+				t.Error("Expected exponential sampling to produce values beyond 1e6")
+			}
+		})
+	}
+}
+
+// Test custom default windows
+func TestGenerateNumberCustomWindow(t *testing.T) {
+	r := DefaultOpenRange()
+	r.Number = Window{Min: -1, Max: 1}
+	gen := NewGenerator().SetSeed(42).SetOpenRange(r)
+
+	for i := 0; i < 50; i++ {
+		result, err := gen.Generate([]byte(`{"type": "number"}`))
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if v := result.(float64); v < -1 || v > 1 {
+			t.Fatalf("Expected value in [-1, 1], got %v", v)
+		}
+	}
+}
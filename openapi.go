@@ -0,0 +1,278 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// openAPIMethods are the operations of a path item, in the order they are
+// listed
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPISpec is an OpenAPI 3 document in JSON
+type openAPISpec struct {
+	data       []byte
+	operations []openAPIOperation // by path, then method
+}
+
+// openAPIOperation is an operation of a spec with its references resolved
+type openAPIOperation struct {
+	id         string
+	method     string // upper case
+	path       string // path template, e.g. "/users/{id}"
+	parameters []openAPIParameter
+	body       *openAPIContent // nil without a request body
+	responses  []openAPIResponse
+}
+
+// openAPIResponse is a documented response of an operation
+type openAPIResponse struct {
+	status  string // a status code, a range like "2XX" or "default"
+	headers []openAPIParameter
+	body    *openAPIContent // nil without a JSON body
+}
+
+// openAPIParameter is a path, query or header parameter; cookie parameters
+// are left out
+type openAPIParameter struct {
+	Ref      string          `json:"$ref"`
+	Name     string          `json:"name"`
+	In       string          `json:"in"`
+	Required bool            `json:"required"`
+	Style    string          `json:"style"`
+	Explode  *bool           `json:"explode"`
+	Schema   json.RawMessage `json:"schema"`
+}
+
+// encoding returns how the parameter is serialized
+func (p openAPIParameter) encoding() FormEncoding {
+	return FormEncoding{Style: p.Style, Explode: p.Explode}
+}
+
+// openAPIContent is the schema of a body in one media type
+type openAPIContent struct {
+	mediaType string
+	schema    []byte // the schema with the spec's components, see specSchema
+	encoding  map[string]string
+}
+
+// openAPIMediaType is an entry of a content map
+type openAPIMediaType struct {
+	Schema   json.RawMessage `json:"schema"`
+	Encoding map[string]struct {
+		ContentType string `json:"contentType"`
+	} `json:"encoding"`
+}
+
+// parseOpenAPI reads the operations of an OpenAPI 3 spec in JSON.
+// Parameters and request bodies may be references to components.
+func parseOpenAPI(data []byte) (*openAPISpec, error) {
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, expected 3.x", doc.OpenAPI)
+	}
+
+	spec := &openAPISpec{data: data}
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		item := doc.Paths[path]
+		var shared []openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return nil, fmt.Errorf("%s: invalid parameters: %w", path, err)
+			}
+		}
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			op, err := spec.operation(path, method, shared, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			spec.operations = append(spec.operations, op)
+		}
+	}
+	return spec, nil
+}
+
+// operation reads an operation. Its parameters replace path item parameters
+// with the same name and location.
+func (s *openAPISpec) operation(path, method string, shared []openAPIParameter, raw json.RawMessage) (openAPIOperation, error) {
+	var op struct {
+		OperationID string                     `json:"operationId"`
+		Parameters  []openAPIParameter         `json:"parameters"`
+		RequestBody json.RawMessage            `json:"requestBody"`
+		Responses   map[string]json.RawMessage `json:"responses"`
+	}
+	if err := json.Unmarshal(raw, &op); err != nil {
+		return openAPIOperation{}, err
+	}
+
+	result := openAPIOperation{id: op.OperationID, method: strings.ToUpper(method), path: path}
+	for _, p := range append(slices.Clone(shared), op.Parameters...) {
+		if err := s.resolve(p.Ref, &p); err != nil {
+			return openAPIOperation{}, err
+		}
+		if p.In == "cookie" {
+			continue
+		}
+		if p.In == "path" {
+			p.Required = true
+		}
+		i := slices.IndexFunc(result.parameters, func(q openAPIParameter) bool { return q.Name == p.Name && q.In == p.In })
+		if i >= 0 {
+			result.parameters[i] = p
+		} else {
+			result.parameters = append(result.parameters, p)
+		}
+	}
+
+	if op.RequestBody != nil {
+		var body struct {
+			Ref     string                      `json:"$ref"`
+			Content map[string]openAPIMediaType `json:"content"`
+		}
+		if err := json.Unmarshal(op.RequestBody, &body); err != nil {
+			return openAPIOperation{}, fmt.Errorf("invalid requestBody: %w", err)
+		}
+		if err := s.resolve(body.Ref, &body); err != nil {
+			return openAPIOperation{}, err
+		}
+		content, err := s.content(body.Content)
+		if err != nil {
+			return openAPIOperation{}, fmt.Errorf("requestBody: %w", err)
+		}
+		result.body = content
+	}
+
+	for _, status := range slices.Sorted(maps.Keys(op.Responses)) {
+		response, err := s.response(status, op.Responses[status])
+		if err != nil {
+			return openAPIOperation{}, fmt.Errorf("response %s: %w", status, err)
+		}
+		result.responses = append(result.responses, response)
+	}
+	return result, nil
+}
+
+// response reads a response. Headers keep their names in the parameters;
+// bodies in media types other than JSON are left out.
+func (s *openAPISpec) response(status string, raw json.RawMessage) (openAPIResponse, error) {
+	var r struct {
+		Ref     string                      `json:"$ref"`
+		Headers map[string]openAPIParameter `json:"headers"`
+		Content map[string]openAPIMediaType `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return openAPIResponse{}, err
+	}
+	if err := s.resolve(r.Ref, &r); err != nil {
+		return openAPIResponse{}, err
+	}
+
+	result := openAPIResponse{status: status}
+	for _, name := range slices.Sorted(maps.Keys(r.Headers)) {
+		h := r.Headers[name]
+		if err := s.resolve(h.Ref, &h); err != nil {
+			return openAPIResponse{}, err
+		}
+		if http.CanonicalHeaderKey(name) == "Content-Type" {
+			continue
+		}
+		h.Name, h.In = name, "header"
+		result.headers = append(result.headers, h)
+	}
+	if content, err := s.content(r.Content); err == nil && jsonMediaType(content.mediaType) {
+		result.body = content
+	}
+	return result, nil
+}
+
+// resolve decodes the component a local reference points to into v; an
+// empty reference leaves v as it is
+func (s *openAPISpec) resolve(ref string, v interface{}) error {
+	if ref == "" {
+		return nil
+	}
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return fmt.Errorf("unsupported reference %q, only references within the spec are", ref)
+	}
+	raw, ok := rawValue(s.data, pointer)
+	if !ok {
+		return fmt.Errorf("unresolved reference %q", ref)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// content picks the media type of a content map to generate: JSON first,
+// then form and multipart bodies
+func (s *openAPISpec) content(content map[string]openAPIMediaType) (*openAPIContent, error) {
+	types := slices.Sorted(maps.Keys(content))
+	rank := func(mediaType string) int {
+		base, _, _ := mime.ParseMediaType(mediaType)
+		switch {
+		case base == "application/json":
+			return 0
+		case jsonMediaType(base):
+			return 1
+		case base == "application/x-www-form-urlencoded":
+			return 2
+		case base == "multipart/form-data":
+			return 3
+		}
+		return -1
+	}
+	best := ""
+	for _, t := range types {
+		if rank(t) >= 0 && (best == "" || rank(t) < rank(best)) {
+			best = t
+		}
+	}
+	if best == "" {
+		return nil, fmt.Errorf("no supported media type in %v", types)
+	}
+
+	media := content[best]
+	encoding := make(map[string]string)
+	for name, enc := range media.Encoding {
+		encoding[name] = enc.ContentType
+	}
+	return &openAPIContent{mediaType: best, schema: s.specSchema(media.Schema), encoding: encoding}, nil
+}
+
+// jsonMediaType reports whether a media type is JSON, e.g.
+// "application/problem+json"
+func jsonMediaType(mediaType string) bool {
+	base, _, _ := mime.ParseMediaType(mediaType)
+	return base == "application/json" || strings.HasSuffix(base, "+json")
+}
+
+// specSchema returns a schema of the spec as a document of its own, with the
+// spec's components added, so references like "#/components/schemas/User"
+// resolve. A missing schema allows any value.
+func (s *openAPISpec) specSchema(schema json.RawMessage) []byte {
+	var obj map[string]json.RawMessage
+	if len(schema) == 0 {
+		obj = make(map[string]json.RawMessage)
+	} else if err := json.Unmarshal(schema, &obj); err != nil {
+		return schema // a boolean schema
+	}
+	if components, ok := rawValue(s.data, "/components"); ok && obj["components"] == nil {
+		obj["components"] = components
+	}
+	data, _ := json.Marshal(obj)
+	return data
+}
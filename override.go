@@ -0,0 +1,132 @@
+package schemagen
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// OverrideFunc computes a value in place of a generated one, from the schema
+// of the value
+type OverrideFunc func(schema *Schema) (interface{}, error)
+
+// pathOverride is an override of the values at the paths matching a glob
+type pathOverride struct {
+	glob     string
+	segments []string
+	fn       OverrideFunc
+}
+
+// SetOverrideGlob sets fn to compute the values at the JSON Pointer paths
+// matching a glob, so one rule covers a field wherever it appears, e.g.
+// "**/createdAt" or "/users/*/id". A glob matches path segments: "*", "?"
+// and character classes match within a segment as in path.Match, and "**"
+// matches any number of segments. The leading "/" is optional; "" matches
+// the document root. When several globs match, the one set last wins.
+// Values are used as returned, without checking them against the schema,
+// and optional properties that are left out stay out. A nil fn removes the
+// glob's override.
+func (g *Generator) SetOverrideGlob(glob string, fn OverrideFunc) *Generator {
+	g.overrides = slices.DeleteFunc(g.overrides, func(o pathOverride) bool { return o.glob == glob })
+	if fn != nil {
+		g.overrides = append(g.overrides, pathOverride{glob: glob, segments: pointerTokens(globPointer(glob)), fn: fn})
+	}
+	return g
+}
+
+// Override sets fn to compute the values at a path, so specific fields can
+// be pinned, such as known IDs, while the rest stays random. The path is a
+// JSON Pointer, "/user/email", a JSONPath, "$.user.email" or "$.users[*].id"
+// with ".." for any depth, or a dotted path without "$", "*.id", which
+// matches at any depth. Pointers and paths may hold the wildcards of
+// SetOverrideGlob, with which overrides share their precedence. A nil fn
+// removes the path's override.
+func (g *Generator) Override(path string, fn OverrideFunc) *Generator {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return g.SetOverrideGlob(path, fn)
+	}
+	return g.SetOverrideGlob(dottedGlob(path), fn)
+}
+
+// dottedGlob converts a JSONPath or dotted path to a glob. Members are
+// separated by "." or written in brackets, "['a.b']" or "[0]"; ".." stands
+// for any number of members. A dotted path starts at any depth, including
+// the root, so a leading "*." adds nothing to it.
+func dottedGlob(path string) string {
+	rest, rooted := strings.CutPrefix(path, "$")
+	var segments []string
+	if !rooted {
+		segments = append(segments, "**")
+		rest = "." + strings.TrimPrefix(rest, "*.")
+	}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segments = append(segments, "**")
+			rest = rest[1:]
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			segments = append(segments, rest[1:end+1])
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			token, after, _ := strings.Cut(rest[1:], "]")
+			segments = append(segments, strings.Trim(token, `'"`))
+			rest = after
+		default:
+			// A member without a separator, as in "$x"
+			rest = "." + rest
+		}
+	}
+	glob := ""
+	for _, segment := range segments {
+		glob += "/" + pointerEscaper.Replace(segment)
+	}
+	return glob
+}
+
+// globPointer returns a glob in the form of a JSON Pointer
+func globPointer(glob string) string {
+	if glob == "" || strings.HasPrefix(glob, "/") {
+		return glob
+	}
+	return "/" + glob
+}
+
+// override returns the override of the value at path, nil if there is none
+func (g *Generator) override(path string) OverrideFunc {
+	if len(g.overrides) == 0 {
+		return nil
+	}
+	tokens := pointerTokens(path)
+	for i := len(g.overrides) - 1; i >= 0; i-- {
+		if matchSegments(g.overrides[i].segments, tokens) {
+			return g.overrides[i].fn
+		}
+	}
+	return nil
+}
+
+// matchSegments reports whether the tokens of a path match glob segments
+func matchSegments(segments, tokens []string) bool {
+	for len(segments) > 0 {
+		if segments[0] == "**" {
+			for skip := 0; skip <= len(tokens); skip++ {
+				if matchSegments(segments[1:], tokens[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(tokens) == 0 {
+			return false
+		}
+		if ok, err := path.Match(segments[0], tokens[0]); !ok || err != nil {
+			return false
+		}
+		segments, tokens = segments[1:], tokens[1:]
+	}
+	return len(tokens) == 0
+}
@@ -0,0 +1,162 @@
+package schemagen
+
+import (
+	"errors"
+	"testing"
+)
+
+// Test glob matching of JSON Pointer paths
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		glob, path string
+		want       bool
+	}{
+		{"**/createdAt", "/createdAt", true},
+		{"**/createdAt", "/users/0/createdAt", true},
+		{"**/createdAt", "/users/0/updatedAt", false},
+		{"/users/*/id", "/users/3/id", true},
+		{"users/*/id", "/users/3/id", true},
+		{"/users/*/id", "/users/3/profile/id", false},
+		{"/users/**", "/users", true},
+		{"/users/**", "/users/3/id", true},
+		{"**/*_at", "/order/shipped_at", true},
+		{"/a~1b", "/a~1b", true},
+		{"", "", true},
+		{"", "/id", false},
+		{"/id", "", false},
+		{"/[", "/[", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.path, func(t *testing.T) {
+			got := matchSegments(pointerTokens(globPointer(tt.glob)), pointerTokens(tt.path))
+			if got != tt.want {
+				t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.glob, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test overrides replace the values at matching paths
+func TestSetOverrideGlob(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"createdAt": {"type": "string", "format": "date-time"},
+			"users": {"type": "array", "minItems": 2, "items": {
+				"type": "object",
+				"properties": {"id": {"type": "integer"}, "createdAt": {"type": "string", "format": "date-time"}},
+				"required": ["id", "createdAt"]
+			}}
+		},
+		"required": ["createdAt", "users"]
+	}`)
+	const fixed = "2024-01-01T00:00:00Z"
+	gen := NewGenerator().SetSeed(42).
+		SetOverrideGlob("**/createdAt", func(s *Schema) (interface{}, error) {
+			if s.Format != "date-time" {
+				t.Errorf("override got the schema with format %q", s.Format)
+			}
+			return fixed, nil
+		}).
+		SetOverrideGlob("/users/*/id", func(*Schema) (interface{}, error) { return 7, nil })
+
+	result, err := gen.GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if result["createdAt"] != fixed {
+		t.Errorf("expected createdAt %s, got %v", fixed, result["createdAt"])
+	}
+	for _, item := range result["users"].([]interface{}) {
+		user := item.(map[string]interface{})
+		if user["createdAt"] != fixed || user["id"] != 7 {
+			t.Errorf("expected overridden user values, got %v", user)
+		}
+	}
+
+	// The glob set last wins, and nil removes an override
+	gen.SetOverrideGlob("/createdAt", func(*Schema) (interface{}, error) { return "root", nil })
+	result, _ = gen.GenerateMap(schema)
+	if result["createdAt"] != "root" {
+		t.Errorf("expected the last glob to win, got %v", result["createdAt"])
+	}
+	gen.SetOverrideGlob("/createdAt", nil).SetOverrideGlob("**/createdAt", nil)
+	result, _ = gen.GenerateMap(schema)
+	if result["createdAt"] == fixed || result["createdAt"] == "root" {
+		t.Errorf("expected removed overrides to stop applying, got %v", result["createdAt"])
+	}
+
+	// Errors are located at the path
+	failure := errors.New("no id")
+	gen.SetOverrideGlob("/users/*/id", func(*Schema) (interface{}, error) { return nil, failure })
+	_, err = gen.Generate(schema)
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Path != "/users/0/id" || !errors.Is(err, failure) {
+		t.Errorf("expected the override error at /users/0/id, got %v", err)
+	}
+}
+
+// Test JSONPath and dotted paths converted to globs
+func TestDottedGlob(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"$.user.email", "/user/email"},
+		{"$.users[*].id", "/users/*/id"},
+		{"$.users[0]['first.name']", "/users/0/first.name"},
+		{"$..id", "/**/id"},
+		{"$", ""},
+		{"*.id", "/**/id"},
+		{"*.user.id", "/**/user/id"},
+		{"id", "/**/id"},
+		{"$.a/b", "/a~1b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := dottedGlob(tt.path); got != tt.want {
+				t.Errorf("dottedGlob(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test Override pins values by pointer, JSONPath and dotted path
+func TestOverride(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"user": {"type": "object", "properties": {"id": {"type": "integer"}, "email": {"type": "string", "format": "email"}}, "required": ["id", "email"]},
+			"tags": {"type": "array", "minItems": 1, "items": {"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}}
+		},
+		"required": ["id", "user", "tags"]
+	}`)
+	pin := func(v interface{}) OverrideFunc {
+		return func(*Schema) (interface{}, error) { return v, nil }
+	}
+	gen := NewGenerator().SetSeed(42).
+		Override("*.id", pin(1)).
+		Override("$.user.email", pin("known@example.com")).
+		Override("/id", pin(100))
+
+	result, err := gen.GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	user := result["user"].(map[string]interface{})
+	tag := result["tags"].([]interface{})[0].(map[string]interface{})
+	if result["id"] != 100 || user["id"] != 1 || tag["id"] != 1 || user["email"] != "known@example.com" {
+		t.Errorf("expected pinned values, got %v", result)
+	}
+
+	// Dotted paths match at the root too
+	result, err = NewGenerator().SetSeed(42).Override("*.id", pin(1)).GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if result["id"] != 1 || result["user"].(map[string]interface{})["id"] != 1 {
+		t.Errorf("expected root and nested ids pinned, got %v", result)
+	}
+}
@@ -0,0 +1,170 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Pact is a contract between a consumer and a provider in the format of the
+// Pact specification, version 3. Write it with json.MarshalIndent to a file
+// named like "consumer-provider.json".
+type Pact struct {
+	Consumer     PactParticipant        `json:"consumer"`
+	Provider     PactParticipant        `json:"provider"`
+	Interactions []PactInteraction      `json:"interactions"`
+	Metadata     map[string]interface{} `json:"metadata"`
+}
+
+// PactParticipant names a consumer or provider
+type PactParticipant struct {
+	Name string `json:"name"`
+}
+
+// PactInteraction is an expected request and the response to it
+type PactInteraction struct {
+	Description string       `json:"description"`
+	Request     PactRequest  `json:"request"`
+	Response    PactResponse `json:"response"`
+}
+
+// PactRequest is the request of an interaction
+type PactRequest struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"query,omitempty"`
+	Headers map[string]string   `json:"headers,omitempty"`
+	Body    interface{}         `json:"body,omitempty"` // JSON bodies as json.RawMessage, others as text
+}
+
+// PactResponse is the response of an interaction
+type PactResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// GeneratePact generates a Pact contract from an OpenAPI 3 spec in JSON,
+// with one interaction per operation: a generated request, as LoadTargets
+// makes, and a generated response of the operation's first success status.
+// Operations documenting only a default response answer 200, and those
+// without success responses their lowest status.
+func (g *Generator) GeneratePact(spec []byte, consumer, provider string) (*Pact, error) {
+	s, err := parseOpenAPI(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	pact := &Pact{
+		Consumer:     PactParticipant{Name: consumer},
+		Provider:     PactParticipant{Name: provider},
+		Interactions: []PactInteraction{},
+		Metadata:     map[string]interface{}{"pactSpecification": map[string]string{"version": "3.0.0"}},
+	}
+	for _, op := range s.operations {
+		interaction, err := g.pactInteraction(s, op)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.method, op.path, err)
+		}
+		pact.Interactions = append(pact.Interactions, interaction)
+	}
+	return pact, nil
+}
+
+// pactInteraction generates the interaction of an operation
+func (g *Generator) pactInteraction(s *openAPISpec, op openAPIOperation) (PactInteraction, error) {
+	target, err := g.generateRequest(s, op)
+	if err != nil {
+		return PactInteraction{}, err
+	}
+	path, query, _ := strings.Cut(target.Path, "?")
+	request := PactRequest{Method: target.Method, Path: path, Headers: pactHeaders(target.Header)}
+	if query != "" {
+		request.Query, _ = url.ParseQuery(query)
+	}
+	if target.Body != nil {
+		request.Body = string(target.Body)
+		if jsonMediaType(op.body.mediaType) {
+			request.Body = json.RawMessage(target.Body)
+		}
+	}
+
+	var response PactResponse
+	if r, status := pactResponse(op.responses); r != nil {
+		response.Status = status
+		if len(r.headers) > 0 {
+			header, err := g.GenerateHeader(s.parameterSchema(r.headers), parameterEncodings(r.headers))
+			if err != nil {
+				return PactInteraction{}, fmt.Errorf("response %s headers: %w", r.status, err)
+			}
+			response.Headers = pactHeaders(header)
+		}
+		if r.body != nil {
+			body, err := g.GenerateBytes(r.body.schema)
+			if err != nil {
+				return PactInteraction{}, fmt.Errorf("response %s body: %w", r.status, err)
+			}
+			response.Body = body
+			if response.Headers == nil {
+				response.Headers = make(map[string]string)
+			}
+			response.Headers["Content-Type"] = r.body.mediaType
+		}
+	} else {
+		response.Status = http.StatusOK
+	}
+
+	description := op.id
+	if description == "" {
+		description = op.method + " " + op.path
+	}
+	return PactInteraction{
+		Description: fmt.Sprintf("%s returns %d", description, response.Status),
+		Request:     request,
+		Response:    response,
+	}, nil
+}
+
+// pactResponse picks the response of an interaction and its status: the
+// lowest 2xx code or range, then default as 200, then the lowest status
+func pactResponse(responses []openAPIResponse) (*openAPIResponse, int) {
+	status := func(s string) int {
+		n, err := strconv.Atoi(strings.ReplaceAll(strings.ToUpper(s), "XX", "00"))
+		if err != nil {
+			return 0
+		}
+		return n
+	}
+	// responses are sorted by status, ranges after the codes they cover
+	for i, r := range responses {
+		if n := status(r.status); n >= 200 && n < 300 {
+			return &responses[i], n
+		}
+	}
+	for i, r := range responses {
+		if r.status == "default" {
+			return &responses[i], http.StatusOK
+		}
+	}
+	for i, r := range responses {
+		if n := status(r.status); n > 0 {
+			return &responses[i], n
+		}
+	}
+	return nil, 0
+}
+
+// pactHeaders joins header values as Pact expects them
+func pactHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
@@ -0,0 +1,80 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// Test contracts generated from the operations of a spec
+func TestGeneratePact(t *testing.T) {
+	spec := []byte(`{
+		"openapi": "3.0.3",
+		"paths": {
+			"/users/{id}": {
+				"get": {
+					"operationId": "getUser",
+					"parameters": [
+						{"name": "id", "in": "path", "schema": {"type": "integer", "minimum": 1, "maximum": 9}},
+						{"name": "fields", "in": "query", "required": true, "schema": {"type": "array", "minItems": 2, "maxItems": 2, "items": {"enum": ["name", "email"]}}}
+					],
+					"responses": {
+						"404": {"description": "missing", "content": {"application/problem+json": {"schema": {"type": "object"}}}},
+						"200": {
+							"description": "found",
+							"headers": {"ETag": {"required": true, "schema": {"type": "string", "pattern": "^W/[0-9a-f]{8}$"}}},
+							"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}
+						}
+					}
+				},
+				"delete": {"responses": {"default": {"description": "done"}}}
+			},
+			"/users": {
+				"post": {
+					"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+					"responses": {"4XX": {"description": "bad"}, "2XX": {"description": "created"}}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"User": {"type": "object", "properties": {"name": {"type": "string", "minLength": 1}}, "required": ["name"]}
+			}
+		}
+	}`)
+
+	pact, err := NewGenerator().SetSeed(42).GeneratePact(spec, "web", "users-api")
+	if err != nil {
+		t.Fatalf("GeneratePact() error = %v", err)
+	}
+	if pact.Consumer.Name != "web" || pact.Provider.Name != "users-api" || len(pact.Interactions) != 3 {
+		t.Fatalf("unexpected contract %+v", pact)
+	}
+
+	create, get, del := pact.Interactions[0], pact.Interactions[1], pact.Interactions[2]
+	if create.Description != "POST /users returns 200" || create.Response.Status != 200 {
+		t.Errorf("expected the 2XX range to answer 200, got %q", create.Description)
+	}
+	if body, ok := create.Request.Body.(json.RawMessage); !ok || !strings.Contains(string(body), `"name"`) {
+		t.Errorf("expected a JSON request body, got %v", create.Request.Body)
+	}
+
+	if get.Description != "getUser returns 200" || !strings.HasPrefix(get.Request.Path, "/users/") || len(get.Request.Query["fields"]) != 2 {
+		t.Errorf("unexpected request %+v", get.Request)
+	}
+	if !strings.HasPrefix(get.Response.Headers["Etag"], "W/") || get.Response.Headers["Content-Type"] != "application/json" {
+		t.Errorf("expected generated response headers, got %v", get.Response.Headers)
+	}
+	var user map[string]interface{}
+	if err := json.Unmarshal(get.Response.Body, &user); err != nil || user["name"] == "" {
+		t.Errorf("expected a user response body, got %s", get.Response.Body)
+	}
+
+	if del.Response.Status != 200 || del.Response.Body != nil {
+		t.Errorf("expected the default response to answer 200 without a body, got %+v", del.Response)
+	}
+
+	if _, err := json.Marshal(pact); err != nil {
+		t.Errorf("Marshal() error = %v", err)
+	}
+}
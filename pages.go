@@ -0,0 +1,65 @@
+package schemagen
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Page is one page of a cursor-paginated response
+type Page struct {
+	Cursor     string        `json:"cursor,omitempty"` // cursor that requests this page, empty for the first
+	Items      []interface{} `json:"items"`
+	NextCursor string        `json:"nextCursor,omitempty"` // cursor of the next page, empty on the last
+}
+
+// Pages is a sequence of pages where each page's NextCursor is the Cursor of
+// the page after it
+type Pages []Page
+
+// GeneratePages generates total items like GenerateRelatedN and splits them
+// into pages of pageSize items. Cursors are opaque tokens derived from the
+// seed and the page offset, so the same seed always produces the same
+// cursors. There is always at least one page, even when total is 0 or less.
+func (g *Generator) GeneratePages(schemaJSON []byte, total, pageSize int) (Pages, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	items, err := g.GenerateRelatedN(schemaJSON, total)
+	if err != nil {
+		return nil, err
+	}
+
+	// A random prefix keeps cursors of different calls apart
+	prefix := g.rand.Uint32()
+	cursor := func(offset int) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%08x:%d", prefix, offset)))
+	}
+
+	pages := Pages{{Items: []interface{}{}}}
+	for offset := 0; offset < total; offset += pageSize {
+		page := &pages[len(pages)-1]
+		if offset > 0 {
+			page.NextCursor = cursor(offset)
+			pages = append(pages, Page{Cursor: page.NextCursor})
+			page = &pages[len(pages)-1]
+		}
+		end := offset + pageSize
+		if end > total {
+			end = total
+		}
+		page.Items = items[offset:end]
+	}
+	return pages, nil
+}
+
+// Find returns the page requested by a cursor; the empty cursor returns the
+// first page
+func (p Pages) Find(cursor string) (Page, bool) {
+	for _, page := range p {
+		if page.Cursor == cursor {
+			return page, true
+		}
+	}
+	return Page{}, false
+}
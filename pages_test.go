@@ -0,0 +1,73 @@
+package schemagen
+
+import (
+	"testing"
+)
+
+func TestGeneratePages(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"id": {"type": "integer", "x-sequence": true}}, "required": ["id"]}`)
+
+	tests := []struct {
+		name     string
+		total    int
+		pageSize int
+		sizes    []int
+	}{
+		{"even pages", 6, 3, []int{3, 3}},
+		{"short last page", 7, 3, []int{3, 3, 1}},
+		{"single page", 2, 5, []int{2}},
+		{"empty", 0, 5, []int{0}},
+		{"negative total", -1, 5, []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := NewGenerator().SetSeed(42).GeneratePages(schema, tt.total, tt.pageSize)
+			if err != nil {
+				t.Fatalf("GeneratePages() error = %v", err)
+			}
+			if len(pages) != len(tt.sizes) {
+				t.Fatalf("Expected %d pages, got %d", len(tt.sizes), len(pages))
+			}
+
+			// Follow the cursors like a client would
+			cursor := ""
+			next := int64(1)
+			for i, want := range tt.sizes {
+				page, ok := pages.Find(cursor)
+				if !ok {
+					t.Fatalf("Page %d not found for cursor %q", i, cursor)
+				}
+				if len(page.Items) != want {
+					t.Errorf("Page %d: expected %d items, got %d", i, want, len(page.Items))
+				}
+				for _, item := range page.Items {
+					if id := item.(map[string]interface{})["id"]; id != next {
+						t.Errorf("Expected id %d, got %v", next, id)
+					}
+					next++
+				}
+				cursor = page.NextCursor
+			}
+			if cursor != "" {
+				t.Errorf("Expected no cursor after the last page, got %q", cursor)
+			}
+		})
+	}
+}
+
+// Test cursors are reproducible from the seed
+func TestGeneratePagesStableCursors(t *testing.T) {
+	schema := []byte(`{"type": "string"}`)
+	a, _ := NewGenerator().SetSeed(42).GeneratePages(schema, 10, 4)
+	b, _ := NewGenerator().SetSeed(42).GeneratePages(schema, 10, 4)
+	for i := range a {
+		if a[i].NextCursor != b[i].NextCursor {
+			t.Errorf("Page %d: cursors differ: %q and %q", i, a[i].NextCursor, b[i].NextCursor)
+		}
+	}
+
+	if _, err := NewGenerator().GeneratePages(schema, 10, 0); err == nil {
+		t.Error("Expected error for page size 0")
+	}
+}
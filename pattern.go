@@ -0,0 +1,271 @@
+package schemagen
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// regexLengths returns the length in characters of the shortest and the
+// longest string a regular expression generates, with -1 for unbounded. The
+// shortest is greater than the longest for expressions that match nothing.
+func regexLengths(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune), len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1, 1
+	case syntax.OpCapture:
+		return regexLengths(re.Sub[0])
+	case syntax.OpConcat:
+		lo, hi := 0, 0
+		for _, sub := range re.Sub {
+			subLo, subHi := regexLengths(sub)
+			lo += subLo
+			hi = addLength(hi, subHi)
+		}
+		return lo, hi
+	case syntax.OpAlternate:
+		lo, hi := -1, 0
+		for _, sub := range re.Sub {
+			subLo, subHi := regexLengths(sub)
+			if lo < 0 || subLo < lo {
+				lo = subLo
+			}
+			if hi >= 0 && (subHi < 0 || subHi > hi) {
+				hi = subHi
+			}
+		}
+		return max(lo, 0), hi
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		countLo, countHi := repeatCounts(re)
+		subLo, subHi := regexLengths(re.Sub[0])
+		if countHi < 0 || subHi < 0 {
+			return countLo * subLo, -1
+		}
+		return countLo * subLo, countHi * subHi
+	case syntax.OpNoMatch:
+		return 1, 0
+	}
+	// Anchors, word boundaries and the empty string take no characters
+	return 0, 0
+}
+
+// addLength adds lengths where -1 is unbounded
+func addLength(a, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
+
+// repeatCounts returns how often a repetition repeats, with -1 for unbounded
+func repeatCounts(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	return re.Min, re.Max
+}
+
+// regexAnchored reports whether a regular expression anchors the start or
+// the end of the string anywhere; word boundaries count as both, since
+// characters next to them change what matches
+func regexAnchored(re *syntax.Regexp) (start, end bool) {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpBeginText:
+		return true, false
+	case syntax.OpEndLine, syntax.OpEndText:
+		return false, true
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true, true
+	}
+	for _, sub := range re.Sub {
+		subStart, subEnd := regexAnchored(sub)
+		start, end = start || subStart, end || subEnd
+	}
+	return start, end
+}
+
+// checkPatternLength fails when no string matching the node's pattern has a
+// length within minLength and maxLength. Patterns that do not anchor both
+// ends also match longer strings that contain a match.
+func checkPatternLength(n *node) error {
+	lo, hi := regexLengths(n.patternSyntax)
+	start, end := regexAnchored(n.patternSyntax)
+	if (start && end && hi >= 0 && hi < n.minLength) || lo > n.maxLength {
+		return fmt.Errorf("pattern %q cannot produce strings of %d to %d characters", n.schema.Pattern, n.minLength, n.maxLength)
+	}
+	return nil
+}
+
+// patternOfLength generates a string matching the node's pattern with a
+// length within minLength and maxLength. Unanchored patterns are padded
+// with letters to reach minLength. It fails when the random choices made
+// on the way lead to a dead end, e.g. an odd length for "^(ab)+$".
+func (g *Generator) patternOfLength(n *node) (string, bool) {
+	re := n.patternSyntax
+	lo, hi := regexLengths(re)
+	start, end := regexAnchored(re)
+
+	// Padding makes up the difference when the pattern is not anchored
+	totalLo, totalHi := max(n.minLength, lo), n.maxLength
+	if start && end && hi >= 0 {
+		totalHi = min(totalHi, hi)
+	}
+	if totalLo > totalHi {
+		return "", false
+	}
+	total := totalLo + g.rand.Intn(totalHi-totalLo+1)
+
+	from, to := lo, total
+	if start && end {
+		from = total
+	}
+	if hi >= 0 {
+		to = min(to, hi)
+	}
+
+	length := from + g.rand.Intn(to-from+1)
+	s, ok := g.regexOfLength(re, length)
+	if !ok {
+		return "", false
+	}
+	padding := g.randomString(total - length)
+	if end {
+		return padding + s, true
+	}
+	return s + padding, true
+}
+
+// regexOfLength generates a string of exactly length characters matching a
+// regular expression
+func (g *Generator) regexOfLength(re *syntax.Regexp, length int) (string, bool) {
+	lo, hi := regexLengths(re)
+	if length < lo || (hi >= 0 && length > hi) {
+		return "", false
+	}
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		return string(re.Rune), true
+	case syntax.OpCharClass:
+		return string(g.classRune(re.Rune)), true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return string(rune('a' + g.rand.Intn(26))), true
+	case syntax.OpCapture:
+		return g.regexOfLength(re.Sub[0], length)
+	case syntax.OpConcat:
+		return g.regexSequence(re.Sub, length)
+	case syntax.OpAlternate:
+		for _, i := range g.rand.Perm(len(re.Sub)) {
+			if s, ok := g.regexOfLength(re.Sub[i], length); ok {
+				return s, true
+			}
+		}
+		return "", false
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		return g.regexRepeat(re, length)
+	}
+	return "", length == 0
+}
+
+// regexRepeat generates a repetition of exactly length characters, picking
+// a number of copies that can add up to it
+func (g *Generator) regexRepeat(re *syntax.Regexp, length int) (string, bool) {
+	countLo, countHi := repeatCounts(re)
+	subLo, subHi := regexLengths(re.Sub[0])
+
+	// Copies of at least subLo and at most subHi characters
+	lo, hi := countLo, max(length, countLo)
+	if subLo > 0 {
+		hi = length / subLo
+	}
+	if subHi > 0 {
+		lo = max(lo, (length+subHi-1)/subHi)
+	}
+	if countHi >= 0 {
+		hi = min(hi, countHi)
+	}
+	if lo > hi {
+		return "", false
+	}
+
+	count := lo + g.rand.Intn(hi-lo+1)
+	copies := make([]*syntax.Regexp, count)
+	for i := range copies {
+		copies[i] = re.Sub[0]
+	}
+	return g.regexSequence(copies, length)
+}
+
+// regexSequence generates strings for a sequence of expressions that add
+// up to exactly length characters
+func (g *Generator) regexSequence(subs []*syntax.Regexp, length int) (string, bool) {
+	// Bounds of what the rest of the sequence can take after each position
+	restLo := make([]int, len(subs)+1)
+	restHi := make([]int, len(subs)+1)
+	for i := len(subs) - 1; i >= 0; i-- {
+		lo, hi := regexLengths(subs[i])
+		restLo[i] = restLo[i+1] + lo
+		restHi[i] = addLength(restHi[i+1], hi)
+	}
+
+	var b strings.Builder
+	remaining := length
+	for i, sub := range subs {
+		lo, hi := regexLengths(sub)
+		from, to := lo, remaining-restLo[i+1]
+		if restHi[i+1] >= 0 {
+			from = max(from, remaining-restHi[i+1])
+		}
+		if hi >= 0 {
+			to = min(to, hi)
+		}
+		if from > to {
+			return "", false
+		}
+
+		part := from + g.rand.Intn(to-from+1)
+		s, ok := g.regexOfLength(sub, part)
+		if !ok {
+			return "", false
+		}
+		b.WriteString(s)
+		remaining -= part
+	}
+	return b.String(), remaining == 0
+}
+
+// classRune picks a rune from a character class given as ranges, preferring
+// printable ASCII
+func (g *Generator) classRune(ranges []rune) rune {
+	printable := make([]rune, 0, len(ranges))
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '), min(ranges[i+1], '~')
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+
+	size := 0
+	for i := 0; i < len(ranges); i += 2 {
+		size += int(ranges[i+1]-ranges[i]) + 1
+	}
+	pick := g.rand.Intn(size)
+	for i := 0; i < len(ranges); i += 2 {
+		width := int(ranges[i+1]-ranges[i]) + 1
+		if pick < width {
+			return ranges[i] + rune(pick)
+		}
+		pick -= width
+	}
+	return ranges[0]
+}
@@ -0,0 +1,101 @@
+package schemagen
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Test pattern strings meet minLength and maxLength by construction, also
+// where repetition has to go beyond the default limit of 10
+func TestPatternOfLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		min, max int
+	}{
+		{"plus", `^[a-z]+$`, 8, 12},
+		{"long plus", `^[a-z]+$`, 50, 60},
+		{"bounded repeat", `^[A-Z]{2,30}$`, 10, 10},
+		{"concat", `^[A-Z]{3}-[0-9]+$`, 8, 9},
+		{"alternation", `^(foo|ba+r)$`, 5, 6},
+		{"unanchored", `[0-9]{3}`, 10, 12},
+		{"start anchored", `^id-`, 6, 8},
+		{"end anchored", `\.json$`, 9, 9},
+		{"fixed beyond default", `^[0-9]{30}$`, 5, -1},
+	}
+
+	gen := NewGenerator().SetSeed(42)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := `{"type": "string", "pattern": ` + strconv.Quote(tt.pattern) + `, "minLength": ` + strconv.Itoa(tt.min)
+			if tt.max >= 0 {
+				schema += `, "maxLength": ` + strconv.Itoa(tt.max)
+			}
+			schema += `}`
+			re := regexp.MustCompile(tt.pattern)
+
+			for i := 0; i < 30; i++ {
+				res, err := gen.GenerateResult([]byte(schema))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				s := res.Value().(string)
+				length := utf8.RuneCountInString(s)
+				if !re.MatchString(s) || length < tt.min || (tt.max >= 0 && length > tt.max) {
+					t.Fatalf("Generated %q (%d characters)", s, length)
+				}
+				if res.Meta().Retries.Retries > 0 {
+					t.Errorf("Expected no retries for %q", s)
+				}
+			}
+		})
+	}
+}
+
+// Test impossible pattern and length combinations fail before generating
+func TestPatternLengthConflict(t *testing.T) {
+	tests := []string{
+		`{"type": "string", "pattern": "^[a-z]{2,4}$", "minLength": 8}`,
+		`{"type": "string", "pattern": "^[0-9]{6}$", "maxLength": 5}`,
+		`{"type": "string", "pattern": "abc[0-9]{5}", "maxLength": 4}`,
+	}
+	for _, schema := range tests {
+		_, err := NewGenerator().SetSeed(42).Generate([]byte(schema))
+		if err == nil || !strings.Contains(err.Error(), "cannot produce strings of") {
+			t.Errorf("Generate(%s) error = %v, want length conflict", schema, err)
+		}
+	}
+
+	// Unanchored patterns match longer strings that contain a match
+	if _, err := NewGenerator().SetSeed(42).Generate([]byte(`{"type": "string", "pattern": "[a-z]{2}", "minLength": 8}`)); err != nil {
+		t.Errorf("Generate() error = %v", err)
+	}
+}
+
+func TestRegexLengths(t *testing.T) {
+	tests := []struct {
+		pattern string
+		lo, hi  int
+	}{
+		{`abc`, 3, 3},
+		{`^a+$`, 1, -1},
+		{`a{2,5}b?`, 2, 6},
+		{`(ab|c)*`, 0, -1},
+		{`x|yyy`, 1, 3},
+		{`(a{2}){3}`, 6, 6},
+		{`\d{4}-\d{2}`, 7, 7},
+	}
+	for _, tt := range tests {
+		re, err := syntax.Parse(tt.pattern, syntax.Perl)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.pattern, err)
+		}
+		if lo, hi := regexLengths(re); lo != tt.lo || hi != tt.hi {
+			t.Errorf("regexLengths(%q) = %d, %d, want %d, %d", tt.pattern, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
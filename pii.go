@@ -0,0 +1,195 @@
+package schemagen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// PIIFinding is a value that likely holds personal data
+type PIIFinding struct {
+	// Path is the JSON Pointer of the value with array indices written as
+	// "*", as Anonymize takes it
+	Path    string   `json:"path"`
+	Reasons []string `json:"reasons"` // e.g. `property name "email"`
+}
+
+// piiNames are normalized property names, or parts of them, that suggest
+// personal data. Short names must match whole.
+var piiNames = []string{
+	"email", "phone", "mobile", "telephone", "fax",
+	"firstname", "lastname", "fullname", "surname", "givenname", "middlename", "maidenname",
+	"birthdate", "dateofbirth", "birthday", "dob",
+	"ssn", "socialsecurity", "nationalid", "passport", "driverslicense", "driverlicense", "taxid",
+	"iban", "creditcard", "cardnumber", "cvv", "cvc",
+	"address", "street", "postcode", "postalcode", "zipcode", "zip",
+	"ipaddress", "ip", "password", "username",
+}
+
+// piiFormats are formats of personal data
+var piiFormats = map[string]bool{"email": true, "idn-email": true, "ipv4": true, "ipv6": true, "phone": true}
+
+// piiValues recognize personal data by value
+var piiValues = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"email addresses", regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[a-zA-Z]{2,}$`)},
+	{"social security numbers", regexp.MustCompile(`^\d{3}-\d{2}-\d{4}$`)},
+	{"IBANs", regexp.MustCompile(`^[A-Z]{2}\d{2} ?([A-Z0-9]{4} ?){2,7}[A-Z0-9]{1,4}$`)},
+	{"IP addresses", regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}$`)},
+	{"phone numbers", regexp.MustCompile(`^(\+\d[\d ().-]{6,}\d|\(\d{3}\) ?\d{3}-\d{4})$`)},
+}
+
+// DetectPII reports the values of a schema that likely hold personal data,
+// judged by property names such as "email" or "lastName", by formats such
+// as "email" and "ipv4", by x-pii, and by the values in sample documents,
+// such as card numbers or phone numbers in a field named "contact". The
+// paths can be passed to Anonymize, or used to mark schemas x-pii or
+// x-volatile. Findings are heuristic and ordered by path.
+func DetectPII(schemaJSON []byte, documents ...[]byte) ([]PIIFinding, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &piiDetector{reasons: make(map[string][]string), visiting: make(map[*node]bool)}
+	d.schema(plan, "")
+	for i, data := range documents {
+		var doc interface{}
+		if err := decodeDocument(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse document %d: %w", i, err)
+		}
+		d.value(doc, "")
+	}
+
+	findings := make([]PIIFinding, 0, len(d.reasons))
+	for path, reasons := range d.reasons {
+		findings = append(findings, PIIFinding{Path: path, Reasons: reasons})
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Path < findings[j].Path })
+	return findings, nil
+}
+
+// PIIPaths returns the paths of findings, e.g. for Anonymize
+func PIIPaths(findings []PIIFinding) []string {
+	paths := make([]string, len(findings))
+	for i, f := range findings {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// piiDetector collects the reasons to suspect personal data by path
+type piiDetector struct {
+	reasons  map[string][]string
+	visiting map[*node]bool
+}
+
+func (d *piiDetector) flag(path, reason string) {
+	for _, r := range d.reasons[path] {
+		if r == reason {
+			return
+		}
+	}
+	d.reasons[path] = append(d.reasons[path], reason)
+}
+
+// schema checks the properties and formats of a plan
+func (d *piiDetector) schema(n *node, path string) {
+	if n == nil || d.visiting[n] {
+		return
+	}
+	d.visiting[n] = true
+	defer delete(d.visiting, n)
+
+	if n.schema.PII {
+		d.flag(path, "marked x-pii")
+	}
+	if piiFormats[n.schema.Format] {
+		d.flag(path, fmt.Sprintf("format %q", n.schema.Format))
+	}
+
+	for _, group := range [][]*node{n.oneOf, n.anyOf, {n.merged}} {
+		for _, alt := range group {
+			d.schema(alt, path)
+		}
+	}
+	for _, prop := range n.properties {
+		child := childPath(path, prop.name)
+		// Flags such as emailVerified are not personal data themselves
+		if name, ok := piiName(prop.name); ok && !slices.Equal(prop.node.types, []string{"boolean"}) {
+			d.flag(child, fmt.Sprintf("property name %q", name))
+		}
+		d.schema(prop.node, child)
+	}
+	d.schema(n.items, path+"/*")
+	d.schema(n.rest, path+"/*")
+	for _, item := range n.tuple {
+		d.schema(item, path+"/*")
+	}
+}
+
+// value checks the values of a sample document
+func (d *piiDetector) value(v interface{}, path string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			d.value(item, childPath(path, key))
+		}
+	case []interface{}:
+		for _, item := range v {
+			d.value(item, path+"/*")
+		}
+	case string:
+		for _, pattern := range piiValues {
+			if pattern.re.MatchString(v) {
+				d.flag(path, "values look like "+pattern.kind)
+				return
+			}
+		}
+		if isCardNumber(v) {
+			d.flag(path, "values look like card numbers")
+		}
+	}
+}
+
+// piiName returns the part of a property name that suggests personal data
+func piiName(name string) (string, bool) {
+	normalized := normalizeName(name)
+	for _, pii := range piiNames {
+		if normalized == pii || (len(pii) >= 5 && strings.Contains(normalized, pii)) {
+			return pii, true
+		}
+	}
+	return "", false
+}
+
+// isCardNumber reports whether s is 13 to 19 digits, optionally grouped by
+// spaces or dashes, that pass the Luhn check
+func isCardNumber(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		n := int(digits[i] - '0')
+		if n < 0 || n > 9 {
+			return false
+		}
+		if (len(digits)-i)%2 == 0 {
+			if n *= 2; n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
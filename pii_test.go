@@ -0,0 +1,64 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectPII(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"customer_email": {"type": "string"},
+			"emailVerified": {"type": "boolean"},
+			"lastName": {"type": "string"},
+			"zip": {"type": "string"},
+			"zipper": {"type": "string"},
+			"contact": {"type": "string"},
+			"login": {"type": "string", "format": "ipv4"},
+			"notes": {"type": "array", "items": {"type": "object", "properties": {"text": {"type": "string"}, "secret": {"type": "string", "x-pii": true}}}},
+			"payment": {"type": "object", "properties": {"card": {"type": "string"}}}
+		}
+	}`)
+	doc := []byte(`{
+		"id": 1, "contact": "+44 20 7946 0000", "zipper": "brass",
+		"notes": [{"text": "call back", "createdAt": "2024-01-01"}, {"text": "078-05-1120"}],
+		"payment": {"card": "4111 1111 1111 1111"}
+	}`)
+
+	findings, err := DetectPII(schema, doc)
+	if err != nil {
+		t.Fatalf("DetectPII() error = %v", err)
+	}
+
+	want := []PIIFinding{
+		{"/contact", []string{"values look like phone numbers"}},
+		{"/customer_email", []string{`property name "email"`}},
+		{"/lastName", []string{`property name "lastname"`}},
+		{"/login", []string{`format "ipv4"`}},
+		{"/notes/*/secret", []string{"marked x-pii"}},
+		{"/notes/*/text", []string{"values look like social security numbers"}},
+		{"/payment/card", []string{"values look like card numbers"}},
+		{"/zip", []string{`property name "zip"`}},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("DetectPII() =\n%v\nwant\n%v", findings, want)
+	}
+
+	// The paths feed Anonymize
+	out, err := NewGenerator().SetSeed(42).Anonymize(doc, schema, PIIPaths(findings)...)
+	if err != nil {
+		t.Fatalf("Anonymize() error = %v", err)
+	}
+	for _, secret := range []string{"7946", "078-05-1120", "4111"} {
+		if strings.Contains(string(out), secret) {
+			t.Errorf("Expected %q to be replaced, got %s", secret, out)
+		}
+	}
+
+	if _, err := DetectPII(schema, []byte(`{`)); err == nil || !strings.Contains(err.Error(), "failed to parse document 0") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
@@ -0,0 +1,547 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp/syntax"
+	"sort"
+
+	"github.com/lucasjones/reggen"
+)
+
+// node is a schema compiled into a generation plan. Everything that only
+// depends on the schema (parsed subschemas, bounds, regex programs, locales)
+// is worked out once by compile, so generating a document is a plain walk
+// over nodes without any JSON round-trips.
+type node struct {
+	schema   *Schema
+	types    []string
+	locale   *Locale       // set by x-locale
+	enum     []interface{} // enum values that also satisfy the rest of the schema
+	maxDepth int           // depth limit auto-tuning chose for the plan rooted here, 0 if none
+	minDepth int           // fewest levels a value needs, set by auto-tuning recursive schemas, 0 if unknown
+	warnings []string      // problems compiling left behind, reported by each generation call
+
+	// Composition
+	oneOf  []*node
+	anyOf  []*node
+	allOf  []*node
+	merged *node // the schema and its allOf subschemas merged into one
+	not    *node
+
+	// String
+	minLength     int
+	maxLength     int
+	pattern       *reggen.Generator
+	patternSyntax *syntax.Regexp // the parsed pattern, for generating strings of a given length
+
+	// Object
+	properties        []property   // sorted by name for deterministic output
+	additional        *node        // additionalProperties given as a schema
+	additionalAllowed bool         // additionalProperties: true
+	dependencies      []dependency // sorted by triggering property
+	propertyNames     *node        // schema for generated property names
+	minProperties     int
+	maxProperties     int // -1 without maxProperties
+
+	// Array
+	items    *node   // single schema for all items
+	tuple    []*node // items given as an array of schemas
+	rest     *node   // schema for items beyond the tuple, from additionalItems or items after prefixItems
+	closed   bool    // no items beyond the tuple
+	minItems int
+	maxItems int
+}
+
+// property is a compiled object property
+type property struct {
+	name     string
+	node     *node
+	required bool
+}
+
+// dependency is a compiled dependentRequired, dependentSchemas or Draft-07
+// dependencies entry, triggered when the named property is present
+type dependency struct {
+	name      string
+	required  []string // properties that must be present as well
+	schema    *node    // schema the whole object must satisfy as well
+	forbidden bool     // boolean schema false: the property must be absent
+}
+
+// compiler turns schemas into nodes. Nodes are memoized per schema so shared
+// subschemas are compiled once.
+type compiler struct {
+	nodes map[*Schema]*node
+
+	// References
+	loader    *refLoader   // loads documents for $ref to other documents, nil if not configured
+	doc       *refDocument // document of the schema being compiled
+	refs      map[string]*refTarget
+	resolving map[string]bool // $refs being followed, to catch cycles of plain references
+	marked    []markedRef     // copies of referenced nodes marked by the schema holding the $ref
+}
+
+// compile builds the generation plan for a parsed schema
+func compile(schema *Schema) (*node, error) {
+	return compileWith(schema, "", nil)
+}
+
+// compileWith builds the generation plan for a parsed schema whose relative
+// references resolve against baseURI, loading other documents with loader
+func compileWith(schema *Schema, baseURI string, loader *refLoader) (*node, error) {
+	c := &compiler{
+		nodes:     make(map[*Schema]*node),
+		loader:    loader,
+		doc:       newRefDocument(schema, baseURI),
+		refs:      make(map[string]*refTarget),
+		resolving: make(map[string]bool),
+	}
+	plan, err := c.compile(schema)
+	if err != nil {
+		return nil, err
+	}
+	c.completeMarked()
+	internPlan(plan)
+	return plan, nil
+}
+
+func (c *compiler) compile(schema *Schema) (*node, error) {
+	if n, ok := c.nodes[schema]; ok {
+		return n, nil
+	}
+	if schema.Ref != "" {
+		return c.compileRef(schema)
+	}
+
+	n := &node{schema: schema, types: schema.Type.GetTypes()}
+	c.nodes[schema] = n
+
+	if schema.Locale != "" {
+		locale, err := resolveLocale(schema.Locale)
+		if err != nil {
+			return nil, err
+		}
+		n.locale = locale
+	}
+
+	var err error
+	if n.oneOf, err = c.compileAll(schema.OneOf); err != nil {
+		return nil, err
+	}
+	if n.anyOf, err = c.compileAll(schema.AnyOf); err != nil {
+		return nil, err
+	}
+	if n.allOf, err = c.compileAll(schema.AllOf); err != nil {
+		return nil, err
+	}
+	if len(schema.AllOf) > 0 {
+		merged, err := c.mergeAllOf(schema, make(map[*Schema]bool))
+		if err != nil {
+			return nil, err
+		}
+		if n.merged, err = c.compile(merged); err != nil {
+			return nil, err
+		}
+	}
+	if schema.Not != nil {
+		if n.not, err = c.compile(schema.Not); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.compileString(n); err != nil {
+		return nil, err
+	}
+	if err := c.compileObject(n); err != nil {
+		return nil, err
+	}
+	if err := c.compileArray(n); err != nil {
+		return nil, err
+	}
+	if err := compileEnum(n); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// compileEnum keeps the enum values that satisfy the rest of the schema, so
+// {"minimum": 4, "enum": [1, 5, 10]} gives only 5 and 10. Values of a type
+// the schema does not allow are left out with a warning.
+func compileEnum(n *node) error {
+	if len(n.schema.Enum) == 0 {
+		return nil
+	}
+	var reason string
+	for _, v := range n.schema.Enum {
+		if len(n.types) > 0 && !matchesAnyType(v, n.types) {
+			n.warnings = append(n.warnings, "left out enum value "+typeMismatch(v, n.types))
+		}
+		errs := validateInstance(n, v, "")
+		if len(errs) == 0 {
+			n.enum = append(n.enum, v)
+		} else if reason == "" {
+			reason = errs[0].Message
+		}
+	}
+	if len(n.enum) == 0 {
+		return fmt.Errorf("no enum value satisfies the schema: %s", reason)
+	}
+	return nil
+}
+
+// compileAll compiles a list of composition subschemas
+func (c *compiler) compileAll(schemas []Schema) ([]*node, error) {
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+
+	nodes := make([]*node, len(schemas))
+	for i := range schemas {
+		n, err := c.compile(&schemas[i])
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = n
+	}
+	return nodes, nil
+}
+
+// compileString pre-computes string length bounds and the pattern generator
+func (c *compiler) compileString(n *node) error {
+	schema := n.schema
+
+	n.minLength = 0
+	n.maxLength = 20 // default max length
+	if schema.MinLength != nil {
+		n.minLength = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		n.maxLength = *schema.MaxLength
+	}
+
+	// Ensure min <= max
+	if n.minLength > n.maxLength {
+		n.maxLength = n.minLength
+	}
+
+	if schema.Pattern != "" {
+		gen, err := reggen.NewGenerator(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regex pattern: %w", err)
+		}
+		n.pattern = gen
+		if n.patternSyntax, err = syntax.Parse(schema.Pattern, syntax.Perl); err != nil {
+			return fmt.Errorf("invalid regex pattern: %w", err)
+		}
+
+		// Without maxLength the pattern decides how long strings get
+		if schema.MaxLength == nil {
+			lo, _ := regexLengths(n.patternSyntax)
+			n.maxLength = max(n.maxLength, lo)
+		}
+		if schema.MinLength != nil || schema.MaxLength != nil {
+			if err := checkPatternLength(n); err != nil {
+				return err
+			}
+		}
+	} else if schema.Format != "" && (schema.MinLength != nil || schema.MaxLength != nil) {
+		if err := checkFormatLength(n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compileObject compiles properties and additionalProperties
+func (c *compiler) compileObject(n *node) error {
+	schema := n.schema
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propNode, err := c.compile(schema.Properties[name])
+		if err != nil {
+			return err
+		}
+		n.properties = append(n.properties, property{name: name, node: propNode, required: required[name]})
+	}
+
+	if err := c.compileDependencies(n); err != nil {
+		return err
+	}
+
+	n.maxProperties = -1
+	if schema.MinProperties != nil {
+		n.minProperties = *schema.MinProperties
+	}
+	if schema.MaxProperties != nil {
+		n.maxProperties = *schema.MaxProperties
+	}
+	if schema.PropertyNames != nil {
+		// Names are always strings, whether or not the schema says so
+		names := *schema.PropertyNames
+		if names.Type.IsEmpty() {
+			names.Type = StringOrArray{Single: "string"}
+		}
+		var err error
+		if n.propertyNames, err = c.compile(&names); err != nil {
+			return err
+		}
+	}
+
+	switch ap := schema.AdditionalProperties.(type) {
+	case bool:
+		n.additionalAllowed = ap
+	case map[string]interface{}:
+		apSchema, err := parseSubschema(ap)
+		if err != nil {
+			return fmt.Errorf("failed to parse additionalProperties schema: %w", err)
+		}
+		if n.additional, err = c.compile(apSchema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compileDependencies maps dependentRequired, dependentSchemas and the legacy
+// Draft-07 dependencies keyword onto the same compiled form
+func (c *compiler) compileDependencies(n *node) error {
+	schema := n.schema
+	deps := make(map[string]*dependency)
+	get := func(name string) *dependency {
+		if deps[name] == nil {
+			deps[name] = &dependency{name: name}
+		}
+		return deps[name]
+	}
+
+	for name, required := range schema.DependentRequired {
+		dep := get(name)
+		dep.required = append(dep.required, required...)
+	}
+
+	for name, depSchema := range schema.DependentSchemas {
+		depNode, err := c.compile(depSchema)
+		if err != nil {
+			return err
+		}
+		get(name).schema = depNode
+	}
+
+	for name, value := range schema.Dependencies {
+		switch value := value.(type) {
+		case []interface{}:
+			dep := get(name)
+			for _, item := range value {
+				if required, ok := item.(string); ok {
+					dep.required = append(dep.required, required)
+				}
+			}
+		case bool:
+			get(name).forbidden = !value
+		case map[string]interface{}:
+			depSchema, err := parseSubschema(value)
+			if err != nil {
+				return fmt.Errorf("failed to parse dependencies schema for %s: %w", name, err)
+			}
+			depNode, err := c.compile(depSchema)
+			if err != nil {
+				return err
+			}
+			get(name).schema = depNode
+		}
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n.dependencies = append(n.dependencies, *deps[name])
+	}
+
+	return nil
+}
+
+// forbids reports whether a dependency forbids the property from being present
+func (n *node) forbids(name string) bool {
+	for _, dep := range n.dependencies {
+		if dep.name == name && dep.forbidden {
+			return true
+		}
+	}
+	return false
+}
+
+// property returns the compiled schema for a named property, if any
+func (n *node) property(name string) *node {
+	for _, prop := range n.properties {
+		if prop.name == name {
+			return prop.node
+		}
+	}
+	return nil
+}
+
+// compileArray compiles items and pre-computes item count bounds
+func (c *compiler) compileArray(n *node) error {
+	schema := n.schema
+
+	n.minItems = 0
+	n.maxItems = 5 // default
+	if schema.MinItems != nil {
+		n.minItems = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		n.maxItems = *schema.MaxItems
+	}
+
+	// Ensure min <= max
+	if n.minItems > n.maxItems {
+		n.maxItems = n.minItems
+	}
+
+	// Draft 2020-12 gives a tuple in prefixItems and the items beyond it in
+	// items; Draft-07 gives it in items and the rest in additionalItems
+	items, rest := schema.Items, schema.AdditionalItems
+	if schema.PrefixItems != nil {
+		items, rest = schema.PrefixItems, schema.Items
+		if _, isList := rest.([]interface{}); isList {
+			return fmt.Errorf("items must be a schema when prefixItems is used")
+		}
+	}
+
+	// Items can be a single schema or an array of schemas
+	switch items := items.(type) {
+	case nil:
+	case map[string]interface{}:
+		itemSchema, err := parseSubschema(items)
+		if err != nil {
+			return fmt.Errorf("failed to parse items schema: %w", err)
+		}
+		if n.items, err = c.compile(itemSchema); err != nil {
+			return err
+		}
+	case []interface{}:
+		n.tuple = make([]*node, len(items))
+		for i, item := range items {
+			itemSchema, err := parseSubschema(item)
+			if err != nil {
+				return fmt.Errorf("failed to parse items schema at index %d: %w", i, err)
+			}
+			if n.tuple[i], err = c.compile(itemSchema); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported items type: %T", items)
+	}
+
+	// The rest only applies to tuples
+	if n.tuple != nil {
+		switch rest := rest.(type) {
+		case bool:
+			if n.closed = !rest; n.closed {
+				n.maxItems = max(min(n.maxItems, len(n.tuple)), n.minItems)
+			}
+		case map[string]interface{}:
+			restSchema, err := parseSubschema(rest)
+			if err != nil {
+				return fmt.Errorf("failed to parse schema for items beyond the tuple: %w", err)
+			}
+			if n.rest, err = c.compile(restSchema); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Unique items from a finite set of values cannot outnumber the set
+	if schema.UniqueItems && n.items != nil {
+		if size, ok := n.items.domainSize(); ok && size < n.maxItems {
+			n.maxItems = max(size, n.minItems)
+		}
+	}
+
+	return nil
+}
+
+// domainSize returns the number of distinct values a schema allows, when
+// that number is small and known up front
+func (n *node) domainSize() (int, bool) {
+	switch {
+	case n.schema.Const != nil:
+		return 1, true
+	case len(n.enum) > 0:
+		return len(n.enum), true
+	case len(n.types) == 1 && n.types[0] == "boolean":
+		return 2, true
+	case len(n.types) == 1 && n.types[0] == "null":
+		return 1, true
+	}
+	return 0, false
+}
+
+// parseSubschema converts a subschema decoded as a generic value (as stored
+// in the polymorphic Items and AdditionalProperties fields) into a Schema
+func parseSubschema(v interface{}) (*Schema, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var schema Schema
+	if err := unmarshalSchema(data, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// planNodes returns every node reachable from a plan once, parents before
+// children
+func planNodes(plan *node) []*node {
+	var nodes []*node
+	seen := make(map[*node]bool)
+
+	var walk func(n *node)
+	walk = func(n *node) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+		nodes = append(nodes, n)
+
+		for _, prop := range n.properties {
+			walk(prop.node)
+		}
+		for _, dep := range n.dependencies {
+			walk(dep.schema)
+		}
+		for _, group := range [][]*node{n.oneOf, n.anyOf, n.allOf, n.tuple} {
+			for _, c := range group {
+				walk(c)
+			}
+		}
+		walk(n.merged)
+		walk(n.rest)
+		walk(n.propertyNames)
+		walk(n.additional)
+		walk(n.items)
+	}
+	walk(plan)
+
+	return nodes
+}
@@ -0,0 +1,122 @@
+package schemagen
+
+import (
+	"testing"
+)
+
+func TestCompileReusesSharedSubschemas(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{
+		"type": "array",
+		"items": {
+			"type": "object",
+			"properties": {
+				"b": {"type": "string"},
+				"a": {"type": "integer"},
+				"c": {"type": "string", "pattern": "^[a-z]{3}$"}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseSchema() error = %v", err)
+	}
+
+	plan, err := compile(schema)
+	if err != nil {
+		t.Fatalf("compile() error = %v", err)
+	}
+
+	if plan.items == nil {
+		t.Fatal("Expected items schema to be compiled")
+	}
+
+	var names []string
+	for _, prop := range plan.items.properties {
+		names = append(names, prop.name)
+	}
+	if len(names) != 3 || names[0] != "a" || names[1] != "b" || names[2] != "c" {
+		t.Errorf("Expected properties sorted by name, got %v", names)
+	}
+
+	if plan.items.properties[2].node.pattern == nil {
+		t.Error("Expected pattern to be compiled")
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"invalid items type", `{"type": "array", "items": 123}`},
+		{"invalid tuple item", `{"type": "array", "items": [{"type": "string"}, 1]}`},
+		{"invalid pattern", `{"type": "string", "pattern": "[a-"}`},
+		{"invalid additionalProperties", `{"type": "object", "additionalProperties": {"minLength": "x"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseSchema([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("ParseSchema() error = %v", err)
+			}
+			if _, err := compile(schema); err == nil {
+				t.Error("Expected compile error")
+			}
+		})
+	}
+}
+
+// Test that output is reproducible for schemas with several optional
+// properties and patterns, which depend on property order and regex seeding
+func TestDeterministicGenerationWithPatterns(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"zip": {"type": "string", "pattern": "^[0-9]{5}$"},
+			"code": {"type": "string", "pattern": "^[A-Z]{3}-[0-9]+$"},
+			"name": {"type": "string"},
+			"count": {"type": "integer"},
+			"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}}
+		}
+	}`
+
+	first, err := NewGenerator().SetSeed(7).SetGenerateAllFields(true).GenerateBytes([]byte(schema))
+	if err != nil {
+		t.Fatalf("GenerateBytes() error = %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		again, err := NewGenerator().SetSeed(7).SetGenerateAllFields(true).GenerateBytes([]byte(schema))
+		if err != nil {
+			t.Fatalf("GenerateBytes() error = %v", err)
+		}
+		if string(first) != string(again) {
+			t.Fatalf("Results with same seed should be identical.\nGot:\n%s\n%s", first, again)
+		}
+	}
+}
+
+func BenchmarkGenerateArrayOfObjects(b *testing.B) {
+	schema := []byte(`{
+		"type": "array",
+		"minItems": 100,
+		"maxItems": 100,
+		"items": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"name": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3}
+			},
+			"required": ["id", "name", "tags"]
+		}
+	}`)
+
+	gen := NewGenerator().SetSeed(1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := gen.Generate(schema); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
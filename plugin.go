@@ -0,0 +1,171 @@
+package schemagen
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// PluginProtocol is the version of the plugin protocol, see StartPlugin
+const PluginProtocol = 1
+
+// FormatRequest asks a provider for a string of a format
+type FormatRequest struct {
+	Format string `json:"format"`
+	Path   string `json:"path"` // JSON Pointer of the value in the document
+	Seed   int64  `json:"seed"` // drawn from the value seed, for reproducible values
+}
+
+// FormatProvider generates strings for a custom format. Strings that break
+// minLength or maxLength are asked for again with a new seed.
+type FormatProvider interface {
+	GenerateFormat(req FormatRequest) (string, error)
+}
+
+// FormatProviderFunc adapts a function to a FormatProvider
+type FormatProviderFunc func(req FormatRequest) (string, error)
+
+// GenerateFormat calls f(req)
+func (f FormatProviderFunc) GenerateFormat(req FormatRequest) (string, error) {
+	return f(req)
+}
+
+// SetFormatProvider sets the provider for a format, replacing the built-in
+// generator if there is one; nil removes it
+func (g *Generator) SetFormatProvider(format string, provider FormatProvider) *Generator {
+	if provider == nil {
+		delete(g.FormatProviders, format)
+		return g
+	}
+	if g.FormatProviders == nil {
+		g.FormatProviders = make(map[string]FormatProvider)
+	}
+	g.FormatProviders[format] = provider
+	return g
+}
+
+// UsePlugin sets a started plugin as the provider of every format it
+// announced
+func (g *Generator) UsePlugin(p *Plugin) *Generator {
+	for _, format := range p.formats {
+		g.SetFormatProvider(format, p)
+	}
+	return g
+}
+
+// Plugin is a format provider running as a subprocess, so providers can be
+// written in any language. Generator and plugin exchange one JSON object per
+// line over the plugin's stdin and stdout:
+//
+//	plugin:    {"protocol": 1, "formats": ["sku", "iban"]}
+//	generator: {"format": "sku", "path": "/items/0/sku", "seed": 42}
+//	plugin:    {"value": "SKU-0042"}
+//
+// The plugin announces its formats once on start, then answers each request
+// in order, with {"error": "..."} when it cannot. The plugin's stderr is
+// passed through. A Plugin may be used by several Generators; requests are
+// sent one at a time.
+type Plugin struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Scanner
+	formats []string
+
+	mu sync.Mutex
+}
+
+// pluginHello is the first message of a plugin
+type pluginHello struct {
+	Protocol int      `json:"protocol"`
+	Formats  []string `json:"formats"`
+}
+
+// pluginResponse answers a FormatRequest
+type pluginResponse struct {
+	Value string `json:"value"`
+	Error string `json:"error,omitempty"`
+}
+
+// StartPlugin runs a plugin program and reads the formats it provides
+func StartPlugin(name string, args ...string) (*Plugin, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", name, err)
+	}
+
+	p := &Plugin{cmd: cmd, stdin: stdin, stdout: bufio.NewScanner(stdout)}
+	p.stdout.Buffer(nil, 1<<20)
+	var hello pluginHello
+	if err := p.read(&hello); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if hello.Protocol != PluginProtocol {
+		p.Close()
+		return nil, fmt.Errorf("plugin %s speaks protocol %d, want %d", name, hello.Protocol, PluginProtocol)
+	}
+	p.formats = hello.Formats
+	return p, nil
+}
+
+// Formats returns the formats the plugin provides
+func (p *Plugin) Formats() []string {
+	return append([]string(nil), p.formats...)
+}
+
+// GenerateFormat asks the plugin for a string of a format
+func (p *Plugin) GenerateFormat(req FormatRequest) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		return "", fmt.Errorf("plugin request failed: %w", err)
+	}
+	var resp pluginResponse
+	if err := p.read(&resp); err != nil {
+		return "", err
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("plugin failed on format %q: %s", req.Format, resp.Error)
+	}
+	return resp.Value, nil
+}
+
+// Close stops the plugin by closing its stdin and waits for it to exit
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stdin.Close()
+	return p.cmd.Wait()
+}
+
+// read decodes the next line the plugin writes
+func (p *Plugin) read(v interface{}) error {
+	if !p.stdout.Scan() {
+		if err := p.stdout.Err(); err != nil {
+			return fmt.Errorf("plugin response failed: %w", err)
+		}
+		return fmt.Errorf("plugin exited")
+	}
+	if err := json.Unmarshal(p.stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("invalid plugin response: %w", err)
+	}
+	return nil
+}
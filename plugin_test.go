@@ -0,0 +1,98 @@
+package schemagen
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Test plugin: when started by the tests below, the test binary acts as a
+// plugin providing the "sku" format
+func TestPluginHelperProcess(t *testing.T) {
+	if os.Getenv("SCHEMAGEN_TEST_PLUGIN") != "1" {
+		t.Skip("only run as a plugin")
+	}
+	fmt.Println(`{"protocol": 1, "formats": ["sku"]}`)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req FormatRequest
+		json.Unmarshal(scanner.Bytes(), &req)
+		if req.Format != "sku" {
+			fmt.Println(`{"error": "unknown format"}`)
+			continue
+		}
+		fmt.Printf("{\"value\": \"SKU-%04d\"}\n", req.Seed%10000)
+	}
+	os.Exit(0)
+}
+
+// startTestPlugin starts the test binary as a plugin
+func startTestPlugin(t *testing.T) *Plugin {
+	t.Setenv("SCHEMAGEN_TEST_PLUGIN", "1")
+	p, err := StartPlugin(os.Args[0], "-test.run=^TestPluginHelperProcess$")
+	if err != nil {
+		t.Fatalf("StartPlugin() error = %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+// Test formats generated by a plugin
+func TestPlugin(t *testing.T) {
+	p := startTestPlugin(t)
+	if got := p.Formats(); len(got) != 1 || got[0] != "sku" {
+		t.Fatalf("Formats() = %v, want [sku]", got)
+	}
+
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"skus": {"type": "array", "minItems": 3, "items": {"type": "string", "format": "sku"}}
+		},
+		"required": ["skus"]
+	}`)
+	generate := func() []interface{} {
+		result, err := NewGenerator().SetSeed(42).UsePlugin(p).GenerateResult(schema)
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		if len(result.Warnings()) > 0 {
+			t.Errorf("unexpected warnings: %v", result.Warnings())
+		}
+		return result.Value().(map[string]interface{})["skus"].([]interface{})
+	}
+
+	first := generate()
+	for _, sku := range first {
+		if s, _ := sku.(string); !strings.HasPrefix(s, "SKU-") || len(s) != 8 {
+			t.Errorf("expected a plugin SKU, got %v", sku)
+		}
+	}
+	if second := generate(); fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Errorf("plugin values differ for the same seed: %v and %v", first, second)
+	}
+}
+
+// Test errors reported by providers
+func TestFormatProviderError(t *testing.T) {
+	p := startTestPlugin(t)
+	gen := NewGenerator().SetSeed(42).SetFormatProvider("iban", p)
+	_, err := gen.Generate([]byte(`{"type": "string", "format": "iban"}`))
+	if err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("expected the plugin's error, got %v", err)
+	}
+
+	gen.SetFormatProvider("iban", FormatProviderFunc(func(req FormatRequest) (string, error) {
+		return "DE" + req.Path, nil
+	}))
+	result, err := gen.Generate([]byte(`{"type": "object", "properties": {"a": {"type": "string", "format": "iban"}}, "required": ["a"]}`))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if got := result.(map[string]interface{})["a"]; got != "DE/a" {
+		t.Errorf("expected the provider's value, got %v", got)
+	}
+}
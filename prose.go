@@ -0,0 +1,106 @@
+package schemagen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// proseFields are property names whose strings are free text
+var proseFields = map[string]bool{
+	"description": true, "comment": true, "comments": true, "bio": true, "summary": true,
+	"notes": true, "note": true, "body": true, "text": true, "message": true,
+	"content": true, "review": true, "about": true, "details": true, "abstract": true,
+}
+
+// proseMinLength is the length strings longer than which are free text
+const proseMinLength = 100
+
+// SetProseText controls whether free-form strings of description-like
+// properties (description, comment, bio, notes, ...) and strings allowed to
+// be longer than 100 characters are generated as sentences with
+// capitalization and punctuation, split into paragraphs when long, instead
+// of run-together words. Properties matched by name without maxLength get
+// a few sentences.
+func (g *Generator) SetProseText(prose bool) *Generator {
+	g.ProseText = prose
+	return g
+}
+
+// isProse reports whether the free-form string of a node at path is prose
+func isProse(n *node, path string) bool {
+	if n.schema.MaxLength != nil && *n.schema.MaxLength > proseMinLength {
+		return true
+	}
+	tokens := pointerTokens(path)
+	return len(tokens) > 0 && proseFields[strings.ToLower(tokens[len(tokens)-1])]
+}
+
+// proseLengths returns the lengths free text of a node may have: minLength
+// to maxLength, or 40 to 200 characters without maxLength
+func proseLengths(n *node) (lo, hi int) {
+	lo, hi = n.minLength, n.maxLength
+	if n.schema.MaxLength == nil {
+		lo, hi = max(lo, 40), max(lo, 200)
+	}
+	return lo, hi
+}
+
+// proseText generates sentences with a length within proseLengths
+func (g *Generator) proseText(locale *Locale, n *node) string {
+	lo, hi := proseLengths(n)
+	target := lo + g.rand.Intn(hi-lo+1)
+	if target == 0 {
+		return ""
+	}
+
+	word := g.faker.Word
+	if locale != nil && len(locale.Words) > 0 {
+		word = func() string { return locale.Words[g.rand.Intn(len(locale.Words))] }
+	}
+
+	var text []rune
+	for sentences := 0; len(text) < target; sentences++ {
+		sentence := g.sentence(word)
+		switch {
+		case sentences == 0:
+		case sentences%4 == 0 && hi > 300:
+			sentence = append([]rune("\n\n"), sentence...)
+		default:
+			sentence = append([]rune(" "), sentence...)
+		}
+		if len(text)+len(sentence) > hi {
+			if len(text) >= lo {
+				break
+			}
+			// Cut the last sentence short and end it with a period
+			text = append(text, sentence[:hi-len(text)]...)
+			for i := len(text) - 2; i >= 0 && !unicode.IsLetter(text[i]); i-- {
+				text[i] = 'a'
+			}
+			text[len(text)-1] = '.'
+			break
+		}
+		text = append(text, sentence...)
+	}
+	return string(text)
+}
+
+// sentence generates a capitalized sentence of 4 to 12 words with an
+// occasional comma, ending in a period
+func (g *Generator) sentence(word func() string) []rune {
+	count := 4 + g.rand.Intn(9)
+	var sentence []rune
+	for i := 0; i < count; i++ {
+		w := []rune(word())
+		if i == 0 && len(w) > 0 {
+			w[0] = unicode.ToUpper(w[0])
+		} else {
+			sentence = append(sentence, ' ')
+		}
+		sentence = append(sentence, w...)
+		if i > 0 && i < count-2 && g.rand.Intn(8) == 0 {
+			sentence = append(sentence, ',')
+		}
+	}
+	return append(sentence, '.')
+}
@@ -0,0 +1,46 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Test description-like and long strings are sentences within their bounds
+func TestProseText(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"description": {"type": "string"},
+			"story": {"type": "string", "minLength": 150, "maxLength": 400},
+			"tight": {"type": "string", "minLength": 101, "maxLength": 101},
+			"name": {"type": "string", "maxLength": 30}
+		},
+		"required": ["description", "story", "tight", "name"]
+	}`)
+
+	gen := NewGenerator().SetSeed(42).SetProseText(true)
+	for i := 0; i < 20; i++ {
+		result, err := gen.GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		for _, tt := range []struct {
+			key    string
+			lo, hi int
+		}{{"description", 40, 200}, {"story", 150, 400}, {"tight", 101, 101}} {
+			text := result[tt.key].(string)
+			if n := utf8.RuneCountInString(text); n < tt.lo || n > tt.hi {
+				t.Errorf("%s: length %d outside [%d, %d]: %q", tt.key, n, tt.lo, tt.hi, text)
+			}
+			first, _ := utf8.DecodeRuneInString(text)
+			if !unicode.IsUpper(first) || !strings.HasSuffix(text, ".") || !strings.Contains(text, " ") {
+				t.Errorf("%s: expected sentences, got %q", tt.key, text)
+			}
+		}
+		if name := result["name"].(string); strings.Contains(name, " ") {
+			t.Errorf("name: expected a plain string, got %q", name)
+		}
+	}
+}
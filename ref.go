@@ -0,0 +1,309 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// errNoRefResolver is the cause of failures to resolve references to other
+// documents without a RefResolver
+var errNoRefResolver = errors.New("no RefResolver configured, see SetRefResolver")
+
+// RefResolver loads the documents that $ref points to when they are not
+// part of the schema being generated from
+type RefResolver interface {
+	// Load returns the JSON document at a URI without fragment. URIs are
+	// relative when neither the schema's $id nor the base URI is absolute.
+	Load(uri string) ([]byte, error)
+}
+
+// RefResolverFunc adapts a function to a RefResolver
+type RefResolverFunc func(uri string) ([]byte, error)
+
+// Load calls f(uri)
+func (f RefResolverFunc) Load(uri string) ([]byte, error) {
+	return f(uri)
+}
+
+// URLResolver loads documents from file:// URIs and plain paths on the file
+// system, and from http:// and https:// URIs
+type URLResolver struct {
+	Dir    string       // directory for relative paths, the working directory if empty
+	Client *http.Client // client for HTTP URIs, http.DefaultClient if nil
+}
+
+// Load reads the document at uri
+func (r URLResolver) Load(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		client := r.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		resp, err := client.Get(uri)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", uri, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	case "file":
+		return os.ReadFile(filepath.FromSlash(u.Path))
+	case "":
+		name := filepath.FromSlash(u.Path)
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(r.Dir, name)
+		}
+		return os.ReadFile(name)
+	}
+	return nil, fmt.Errorf("unsupported URI scheme %q", u.Scheme)
+}
+
+// SetRefResolver sets the resolver for $ref to other documents, such as
+// OpenAPI component schemas split across files. Loaded documents are cached
+// by the Generator until the resolver is replaced. Without a resolver only
+// references within the schema are followed.
+func (g *Generator) SetRefResolver(resolver RefResolver) *Generator {
+	g.RefResolver = resolver
+	g.refDocs = nil
+	return g
+}
+
+// SetBaseURI sets the URI that relative $ref in the root schema resolve
+// against, e.g. "file:///src/api/schemas/" or the URL the schema was
+// downloaded from. A $id in the root schema takes precedence.
+func (g *Generator) SetBaseURI(uri string) *Generator {
+	g.BaseURI = uri
+	return g
+}
+
+// refLoader caches the documents loaded through a RefResolver
+type refLoader struct {
+	resolver RefResolver
+	docs     map[string]*refDocument
+}
+
+// refLoader returns the document cache for the configured resolver
+func (g *Generator) refLoader() *refLoader {
+	if g.RefResolver == nil {
+		return nil
+	}
+	if g.refDocs == nil {
+		g.refDocs = &refLoader{resolver: g.RefResolver, docs: make(map[string]*refDocument)}
+	}
+	return g.refDocs
+}
+
+// load returns the document at uri, loading it on first use
+func (l *refLoader) load(uri string) (*refDocument, error) {
+	if doc, ok := l.docs[uri]; ok {
+		return doc, nil
+	}
+	data, err := l.resolver.Load(uri)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ParseSchema(data)
+	if err != nil {
+		return nil, err
+	}
+	doc := newRefDocument(schema, uri)
+	l.docs[uri] = doc
+	return doc, nil
+}
+
+// refDocument is a schema document that references are resolved in
+type refDocument struct {
+	uri    string  // base URI without fragment, "" when unknown
+	schema *Schema // the document's root schema
+}
+
+func newRefDocument(schema *Schema, baseURI string) *refDocument {
+	uri := baseURI
+	if schema.ID != "" {
+		uri = resolveURI(baseURI, schema.ID)
+	}
+	uri, _, _ = strings.Cut(uri, "#")
+	return &refDocument{uri: uri, schema: schema}
+}
+
+// lookup returns the encoded value at a JSON Pointer in the document
+func (d *refDocument) lookup(pointer string) (json.RawMessage, bool) {
+	s := d.schema
+	first, rest, _ := strings.Cut(strings.TrimPrefix(pointer, "/"), "/")
+	if raw, ok := s.deferred[strings.ReplaceAll(strings.ReplaceAll(first, "~1", "/"), "~0", "~")]; ok {
+		if rest != "" {
+			rest = "/" + rest
+		}
+		return rawValue(raw, rest)
+	}
+	if s.source == nil {
+		// Schemas built in code have no source; their encoding stands in
+		s.source, _ = json.Marshal(s)
+	}
+	return rawValue(s.source, pointer)
+}
+
+// refTarget is a resolved $ref: a schema and the document it belongs to
+type refTarget struct {
+	schema *Schema
+	doc    *refDocument
+}
+
+// compileRef compiles a schema with $ref to the node of the referenced
+// schema. Other keywords next to $ref are ignored, as in Draft-07, except
+// x-volatile and x-pii, which mark the referenced values.
+func (c *compiler) compileRef(schema *Schema) (*node, error) {
+	ref := schema.Ref
+	target, err := c.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recursive schemas refer back to a node that is already being compiled
+	if n, ok := c.nodes[target.schema]; ok {
+		n = c.mark(schema, n)
+		c.nodes[schema] = n
+		return n, nil
+	}
+	key := resolveURI(c.doc.uri, ref)
+	if c.resolving[key] {
+		return nil, fmt.Errorf("circular $ref %q", ref)
+	}
+
+	// References inside the target resolve against its own document
+	c.resolving[key] = true
+	doc := c.doc
+	c.doc = target.doc
+	n, err := c.compile(target.schema)
+	c.doc = doc
+	delete(c.resolving, key)
+	if err != nil {
+		return nil, err
+	}
+	n = c.mark(schema, n)
+	c.nodes[schema] = n
+	return n, nil
+}
+
+// markedRef is a copy of a referenced node carrying the x-volatile and
+// x-pii of the schema holding the $ref
+type markedRef struct {
+	node   *node
+	target *node
+	schema *Schema
+}
+
+// mark returns the node a $ref schema compiles to: n, or a copy of n when
+// the schema marks the values x-volatile or x-pii and n does not
+func (c *compiler) mark(schema *Schema, n *node) *node {
+	if (!schema.Volatile || n.schema.Volatile) && (!schema.PII || n.schema.PII) {
+		return n
+	}
+	marked := *n.schema
+	marked.Volatile = marked.Volatile || schema.Volatile
+	marked.PII = marked.PII || schema.PII
+	ref := markedRef{node: &node{}, target: n, schema: &marked}
+	ref.complete()
+	c.marked = append(c.marked, ref)
+	return ref.node
+}
+
+// complete copies the target into the marked node
+func (r markedRef) complete() {
+	*r.node = *r.target
+	r.node.schema = r.schema
+}
+
+// completeMarked copies the referenced nodes into their marked copies again
+// once compilation is done, since a recursive $ref can be marked while its
+// target is still being compiled
+func (c *compiler) completeMarked() {
+	for _, ref := range c.marked {
+		ref.complete()
+	}
+}
+
+// resolve returns the schema a $ref points to, loading other documents
+// through the configured resolver
+func (c *compiler) resolve(ref string) (*refTarget, error) {
+	absolute := resolveURI(c.doc.uri, ref)
+	if target, ok := c.refs[absolute]; ok {
+		return target, nil
+	}
+
+	docURI, fragment, _ := strings.Cut(absolute, "#")
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil || (pointer != "" && !strings.HasPrefix(pointer, "/")) {
+		return nil, fmt.Errorf("cannot resolve $ref %q: not a JSON Pointer", ref)
+	}
+
+	doc := c.doc
+	if docURI != doc.uri {
+		if c.loader == nil {
+			return nil, fmt.Errorf("cannot resolve $ref %q: %w", ref, errNoRefResolver)
+		}
+		if doc, err = c.loader.load(docURI); err != nil {
+			return nil, fmt.Errorf("failed to load $ref %q: %w", ref, err)
+		}
+	}
+
+	target := &refTarget{schema: doc.schema, doc: doc}
+	if pointer != "" {
+		raw, ok := doc.lookup(pointer)
+		if !ok {
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+		target.schema = &Schema{}
+		if err := unmarshalSchema(raw, target.schema); err != nil {
+			return nil, fmt.Errorf("failed to parse $ref %q: %w", ref, err)
+		}
+	}
+
+	c.refs[absolute] = target
+	return target, nil
+}
+
+// resolveURI resolves a reference against a base URI. Unlike
+// url.URL.ResolveReference it keeps relative bases relative, so schemas
+// loaded by relative path can refer to their neighbours.
+func resolveURI(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	if b.IsAbs() || r.IsAbs() {
+		return b.ResolveReference(r).String()
+	}
+
+	baseDoc, _, _ := strings.Cut(base, "#")
+	if strings.HasPrefix(ref, "#") {
+		return baseDoc + ref
+	}
+	if strings.HasPrefix(ref, "/") {
+		return ref
+	}
+	return path.Join(path.Dir(baseDoc), ref)
+}
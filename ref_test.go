@@ -0,0 +1,211 @@
+package schemagen
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateWithRef(t *testing.T) {
+	schema := []byte(`{
+		"$defs": {
+			"address": {
+				"type": "object",
+				"properties": {
+					"street": {"type": "string", "minLength": 1},
+					"zip": {"$ref": "#/$defs/zip"}
+				},
+				"required": ["street", "zip"],
+				"additionalProperties": false
+			},
+			"zip": {"type": "string", "pattern": "^[0-9]{5}$"}
+		},
+		"definitions": {
+			"id": {"type": "integer", "minimum": 1}
+		},
+		"type": "object",
+		"properties": {
+			"id": {"$ref": "#/definitions/id"},
+			"home": {"$ref": "#/$defs/address"},
+			"work": {"$ref": "#/$defs/address"},
+			"previous": {"type": "array", "minItems": 1, "items": {"$ref": "#/$defs/address"}}
+		},
+		"required": ["id", "home", "work", "previous"]
+	}`)
+
+	parsed, _ := ParseSchema(schema)
+	plan, err := compile(parsed)
+	if err != nil {
+		t.Fatalf("compile() error = %v", err)
+	}
+	if plan.property("home") != plan.property("work") {
+		t.Error("Expected sibling refs to share one compiled node")
+	}
+
+	gen := NewGenerator().SetSeed(42)
+	for i := 0; i < 10; i++ {
+		doc, err := gen.GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		if errs := validateInstance(plan, doc, ""); len(errs) > 0 {
+			t.Fatalf("Generated document is invalid: %v\n%v", errs, doc)
+		}
+		if _, ok := doc["home"].(map[string]interface{})["zip"].(string); !ok {
+			t.Errorf("Expected nested ref to produce a zip string, got %v", doc["home"])
+		}
+	}
+}
+
+// Test recursive schemas generate and are reported as recursive
+func TestGenerateRecursiveRef(t *testing.T) {
+	schema := []byte(`{
+		"$defs": {
+			"node": {
+				"type": "object",
+				"properties": {
+					"value": {"type": "integer"},
+					"children": {"type": "array", "maxItems": 2, "items": {"$ref": "#/$defs/node"}}
+				},
+				"required": ["value"]
+			}
+		},
+		"$ref": "#/$defs/node"
+	}`)
+
+	doc, err := NewGenerator().SetSeed(42).GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if doc["value"] == nil {
+		t.Errorf("Expected a node, got %v", doc)
+	}
+
+	analysis, err := Analyze(schema)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if !analysis.Recursive {
+		t.Error("Expected schema to be reported as recursive")
+	}
+}
+
+func TestRefErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"missing target", `{"properties": {"a": {"$ref": "#/$defs/missing"}}}`, "not found"},
+		{"circular", `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`, "circular"},
+		{"remote", `{"$ref": "https://example.com/schema.json"}`, "no RefResolver configured"},
+		{"anchor", `{"$ref": "#address"}`, "not a JSON Pointer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator().Generate([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// Test references across files, including relative references inside the
+// loaded documents
+func TestRefResolverFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"order.json":           `{"type": "object", "properties": {"customer": {"$ref": "common/customer.json"}}, "required": ["customer"]}`,
+		"common/customer.json": `{"type": "object", "properties": {"zip": {"$ref": "types.json#/$defs/zip"}, "vip": {"$ref": "#/$defs/flag"}}, "required": ["zip", "vip"], "$defs": {"flag": {"const": true}}}`,
+		"common/types.json":    `{"$defs": {"zip": {"type": "string", "pattern": "^[0-9]{5}$"}}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		gen    *Generator
+		schema string
+	}{
+		{"relative to resolver dir", NewGenerator().SetRefResolver(URLResolver{Dir: dir}), files["order.json"]},
+		{"file base URI", NewGenerator().SetRefResolver(URLResolver{}).SetBaseURI("file://" + filepath.ToSlash(dir) + "/"), files["order.json"]},
+		{"ref to a file root", NewGenerator().SetRefResolver(URLResolver{Dir: dir}), `{"$ref": "order.json"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := tt.gen.SetSeed(42).GenerateMap([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("GenerateMap() error = %v", err)
+			}
+			customer := doc["customer"].(map[string]interface{})
+			if zip, _ := customer["zip"].(string); len(zip) != 5 || customer["vip"] != true {
+				t.Errorf("Unexpected customer %v", customer)
+			}
+		})
+	}
+}
+
+// Test documents are fetched over HTTP once and then cached
+func TestRefResolverHTTPCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/schemas/id.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"type": "integer", "minimum": 1, "maximum": 9}`)
+	}))
+	defer server.Close()
+
+	schema := []byte(`{"$id": "` + server.URL + `/schemas/user.json", "type": "object", "properties": {"id": {"$ref": "id.json"}}, "required": ["id"]}`)
+	gen := NewGenerator().SetSeed(42).SetRefResolver(URLResolver{Client: server.Client()})
+	for i := 0; i < 3; i++ {
+		doc, err := gen.GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		if id := doc["id"].(int64); id < 1 || id > 9 {
+			t.Errorf("Expected id in [1, 9], got %d", id)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+
+	missing := []byte(`{"$id": "` + server.URL + `/schemas/user.json", "$ref": "missing.json"}`)
+	if _, err := gen.Generate(missing); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected load error, got %v", err)
+	}
+}
+
+func TestResolveURI(t *testing.T) {
+	tests := []struct {
+		base, ref, want string
+	}{
+		{"", "#/a", "#/a"},
+		{"order.json", "#/a", "order.json#/a"},
+		{"order.json", "common/customer.json", "common/customer.json"},
+		{"common/customer.json", "types.json#/x", "common/types.json#/x"},
+		{"common/customer.json", "../order.json", "order.json"},
+		{"https://example.com/a/b.json", "c.json#/x", "https://example.com/a/c.json#/x"},
+		{"order.json", "https://example.com/x.json", "https://example.com/x.json"},
+	}
+	for _, tt := range tests {
+		if got := resolveURI(tt.base, tt.ref); got != tt.want {
+			t.Errorf("resolveURI(%q, %q) = %q, want %q", tt.base, tt.ref, got, tt.want)
+		}
+	}
+}
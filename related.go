@@ -0,0 +1,175 @@
+package schemagen
+
+import (
+	"context"
+	"fmt"
+)
+
+// relation holds the values shared by all documents generated in one call:
+// x-sequence counters, x-unique registries, x-pool entity pools and the
+// accumulators behind x-nullable-rate and x-missing-rate
+type relation struct {
+	sequences map[*node]int64
+	seen      map[*node]map[string]bool
+	pools     map[string][]interface{}
+	rates     map[rateKey]float64
+}
+
+// rateKey identifies the accumulator of one rate extension on one node
+type rateKey struct {
+	node    *node
+	keyword string
+}
+
+func newRelation() *relation {
+	return &relation{
+		sequences: make(map[*node]int64),
+		seen:      make(map[*node]map[string]bool),
+		pools:     make(map[string][]interface{}),
+		rates:     make(map[rateKey]float64),
+	}
+}
+
+// related reports whether a node uses keywords that depend on the other
+// values generated in the same call
+func (n *node) related() bool {
+	s := n.schema
+	return s.Sequence || s.Unique || s.Pool != "" || s.PoolRef != ""
+}
+
+// GenerateRelatedN generates n documents that share sequences, entity pools
+// and unique-value registries, so the batch is coherent:
+//
+//   - x-sequence integers count up across all n documents
+//   - x-unique values never repeat across all n documents
+//   - x-pool-ref values are picked from values generated for x-pool fields of
+//     the same name, e.g. a managerId referring to an earlier user's id
+//   - with SetDuplicates, a share of the documents repeat earlier ones
+//
+// A plain Generate call behaves like a batch of one.
+func (g *Generator) GenerateRelatedN(schemaJSON []byte, n int) ([]interface{}, error) {
+	plan, err := g.prepare(schemaJSON, "")
+	if err != nil {
+		return nil, err
+	}
+
+	st := newGenState(context.Background())
+	docs := make([]interface{}, max(n, 0))
+	var originals []interface{}
+	for i := range docs {
+		if doc, ok := g.duplicate(st, plan, originals); ok {
+			docs[i] = doc
+			continue
+		}
+
+		doc, err := g.generateDocument(st, plan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate document %d: %w", i, err)
+		}
+		docs[i] = doc
+		originals = append(originals, doc)
+	}
+
+	return docs, nil
+}
+
+// generateRelated generates a value for a node using x-sequence, x-unique,
+// x-pool or x-pool-ref
+func (g *Generator) generateRelated(st *genState, n *node, path string, depth int) (interface{}, error) {
+	s := n.schema
+	rel := st.related
+
+	// Reuse a pooled value when one is available
+	if s.PoolRef != "" {
+		if pool := rel.pools[s.PoolRef]; len(pool) > 0 {
+			return pool[g.rand.Intn(len(pool))], nil
+		}
+	}
+
+	var value interface{}
+	var err error
+	switch {
+	case s.Sequence:
+		value, err = rel.next(n)
+	case s.Unique:
+		value, err = g.generateUnique(st, n, path, depth)
+	default:
+		value, err = g.generateValue(st, n, path, depth)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Pool != "" {
+		rel.pools[s.Pool] = append(rel.pools[s.Pool], value)
+	}
+	return value, nil
+}
+
+// atRate reports whether the next value of a node is affected by a rate
+// extension. The share of affected values within a call stays within one of
+// rate times the number of values, starting at a random phase, so a batch
+// of 100 documents with a rate of 0.1 has exactly 10 affected values.
+func (g *Generator) atRate(st *genState, n *node, keyword string, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	key := rateKey{node: n, keyword: keyword}
+	acc, ok := st.related.rates[key]
+	if !ok {
+		acc = g.shape.Float64()
+	}
+
+	acc += rate
+	hit := acc >= 1
+	if hit {
+		acc--
+	}
+	st.related.rates[key] = acc
+	return hit
+}
+
+// next returns the next value of a node's x-sequence. Sequences start at the
+// schema's minimum (or 1) and fail once they pass its maximum.
+func (rel *relation) next(n *node) (interface{}, error) {
+	s := n.schema
+
+	lo, hi, ok := s.integerRange()
+	start := int64(1)
+	if s.Minimum != nil || s.ExclusiveMinimum != nil {
+		start = lo
+	}
+
+	value := start + rel.sequences[n]
+	if !ok || value > hi || value < start {
+		return nil, keywordErrorf("x-sequence", "x-sequence exhausted its range at %d", value)
+	}
+
+	rel.sequences[n]++
+	return value, nil
+}
+
+// generateUnique generates a value for a node that was not generated for it
+// before in this call
+func (g *Generator) generateUnique(st *genState, n *node, path string, depth int) (interface{}, error) {
+	seen := st.related.seen[n]
+	if seen == nil {
+		seen = make(map[string]bool)
+		st.related.seen[n] = seen
+	}
+
+	value, err := g.retry("x-unique", path, func() (interface{}, error) {
+		return g.generateValue(st, n, path, depth)
+	}, func(v interface{}) bool {
+		key, err := uniqueKey(v)
+		return err == nil && !seen[key]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key, _ := uniqueKey(value)
+	seen[key] = true
+	return value, nil
+}
@@ -0,0 +1,202 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+const relatedUserSchema = `{
+	"type": "object",
+	"properties": {
+		"id": {"type": "integer", "minimum": 100, "x-sequence": true, "x-pool": "users"},
+		"email": {"type": "string", "enum": ["a@x.io", "b@x.io", "c@x.io", "d@x.io", "e@x.io"], "x-unique": true},
+		"managerId": {"type": "integer", "minimum": 1, "maximum": 50, "x-pool-ref": "users"}
+	},
+	"required": ["id", "email", "managerId"]
+}`
+
+func TestGenerateRelatedN(t *testing.T) {
+	gen := NewGenerator().SetSeed(42)
+	docs, err := gen.GenerateRelatedN([]byte(relatedUserSchema), 5)
+	if err != nil {
+		t.Fatalf("GenerateRelatedN() error = %v", err)
+	}
+	if len(docs) != 5 {
+		t.Fatalf("Expected 5 documents, got %d", len(docs))
+	}
+
+	ids := make(map[int64]bool)
+	emails := make(map[string]bool)
+	for i, doc := range docs {
+		obj := doc.(map[string]interface{})
+
+		// Test sequence counts up across documents
+		id := obj["id"].(int64)
+		if id != int64(100+i) {
+			t.Errorf("Document %d: expected id %d, got %d", i, 100+i, id)
+		}
+		ids[id] = true
+
+		// Test unique values never repeat
+		email := obj["email"].(string)
+		if emails[email] {
+			t.Errorf("Document %d: email %q repeated", i, email)
+		}
+		emails[email] = true
+	}
+
+	// Test pool references point at generated ids
+	for i, doc := range docs {
+		managerID := doc.(map[string]interface{})["managerId"].(int64)
+		if !ids[managerID] {
+			t.Errorf("Document %d: managerId %d is not a generated id", i, managerID)
+		}
+	}
+}
+
+// Test a negative count gives no documents
+func TestGenerateRelatedNNegative(t *testing.T) {
+	docs, err := NewGenerator().SetSeed(42).GenerateRelatedN([]byte(`{"type": "string"}`), -1)
+	if err != nil {
+		t.Fatalf("GenerateRelatedN() error = %v", err)
+	}
+	if len(docs) != 0 {
+		t.Errorf("Expected no documents, got %d", len(docs))
+	}
+}
+
+func TestGenerateRelatedNErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		n       int
+		wantErr string
+	}{
+		{
+			name:    "sequence on string",
+			schema:  `{"type": "string", "x-sequence": true}`,
+			n:       1,
+			wantErr: "x-sequence",
+		},
+		{
+			name:    "sequence past maximum",
+			schema:  `{"type": "integer", "minimum": 1, "maximum": 3, "x-sequence": true}`,
+			n:       4,
+			wantErr: "exhausted",
+		},
+		{
+			name:    "unique values exhausted",
+			schema:  `{"type": "boolean", "x-unique": true}`,
+			n:       3,
+			wantErr: "unique",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator().SetSeed(42)
+			_, err := gen.GenerateRelatedN([]byte(tt.schema), tt.n)
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// Test x-nullable-rate and x-missing-rate hit their share of a batch
+func TestDataQualityRates(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"email": {"type": "string", "format": "email", "x-nullable-rate": 0.1},
+			"phone": {"type": "string", "x-missing-rate": 0.25},
+			"name": {"type": "string"}
+		},
+		"required": ["email", "phone", "name"]
+	}`
+
+	gen := NewGenerator().SetSeed(42)
+	docs, err := gen.GenerateRelatedN([]byte(schema), 100)
+	if err != nil {
+		t.Fatalf("GenerateRelatedN() error = %v", err)
+	}
+
+	nulls, missing := 0, 0
+	for _, doc := range docs {
+		obj := doc.(map[string]interface{})
+		if obj["email"] == nil {
+			nulls++
+		}
+		if _, ok := obj["phone"]; !ok {
+			missing++
+		}
+		if obj["name"] == nil {
+			t.Fatal("Expected name to be unaffected")
+		}
+	}
+
+	if nulls != 10 {
+		t.Errorf("Expected 10 null emails, got %d", nulls)
+	}
+	if missing != 25 {
+		t.Errorf("Expected 25 missing phones, got %d", missing)
+	}
+}
+
+// Test x-include-rate includes optional properties in their share of a batch
+func TestIncludeRate(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"properties": {
+			"fax": {"type": "string", "x-include-rate": 0.1},
+			"nickname": {"type": "string"},
+			"name": {"type": "string", "x-include-rate": 0.5}
+		},
+		"required": ["name"]
+	}`
+
+	for _, all := range []bool{false, true} {
+		gen := NewGenerator().SetSeed(42).SetGenerateAllFields(all)
+		docs, err := gen.GenerateRelatedN([]byte(schema), 100)
+		if err != nil {
+			t.Fatalf("GenerateRelatedN() error = %v", err)
+		}
+
+		faxes, nicknames := 0, 0
+		for _, doc := range docs {
+			obj := doc.(map[string]interface{})
+			if _, ok := obj["fax"]; ok {
+				faxes++
+			}
+			if _, ok := obj["nickname"]; ok {
+				nicknames++
+			}
+			if _, ok := obj["name"]; !ok {
+				t.Fatal("Expected required name regardless of its rate")
+			}
+		}
+
+		if faxes != 10 {
+			t.Errorf("all fields %v: expected 10 faxes, got %d", all, faxes)
+		}
+		if want := map[bool]int{false: 0, true: 100}[all]; nicknames != want {
+			t.Errorf("all fields %v: expected %d nicknames, got %d", all, want, nicknames)
+		}
+	}
+}
+
+// Test rates outside [0, 1] are rejected
+func TestDataQualityRatesInvalid(t *testing.T) {
+	for _, schema := range []string{
+		`{"type": "string", "x-nullable-rate": 1.5}`,
+		`{"type": "string", "x-missing-rate": -0.1}`,
+		`{"type": "string", "x-include-rate": 2}`,
+	} {
+		if _, err := NewGenerator().Generate([]byte(schema)); err == nil {
+			t.Errorf("Expected error for %s", schema)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"time"
+)
+
+// Result wraps a generated value with typed accessors, warnings and metadata
+type Result struct {
+	value    interface{}
+	warnings []string
+	meta     Meta
+
+	shuffleKeys bool
+	keySeed     int64 // seeds the key order when shuffleKeys is set
+}
+
+// Meta describes how a Result was generated
+type Meta struct {
+	Seed          int64         `json:"seed"`
+	StructureSeed int64         `json:"structureSeed"`
+	Duration      time.Duration `json:"duration"`
+	Retries       RetryStats    `json:"retries"`
+	Version       string        `json:"version"` // schemagen version, see Version
+}
+
+// Version returns the version of schemagen the program was built with, or
+// "(devel)" when it is not known, e.g. in schemagen's own tests
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "(devel)"
+}
+
+// modulePath is the module schemagen is released as
+const modulePath = "github.com/sarathsp06/schemagen"
+
+// SetSeedProperty makes Generate, GenerateMap and GenerateBytes add a
+// property of the given name to object documents, holding the seeds, the
+// number of the call since the seeds were set and the schemagen version,
+// e.g.
+//
+//	"_generated": {"seed": 42, "structureSeed": 42, "call": 3, "version": "v1.2.0"}
+//
+// A fixture found in a bug report can then be regenerated by a Generator
+// with the same seeds: its call-th call of Generate, GenerateMap,
+// GenerateBytes or a compiled schema's Generate returns the fixture, as long
+// as the calls before it were the same. The property replaces one of the
+// same name. It is left out, with a warning, where the schema does not allow
+// it, such as with additionalProperties false or maxProperties reached. An
+// empty name turns it off.
+func (g *Generator) SetSeedProperty(name string) *Generator {
+	g.SeedProperty = name
+	return g
+}
+
+// stampSeed counts a generation call and adds the seed property to the
+// object document it generated from plan
+func (g *Generator) stampSeed(plan *node, doc interface{}) {
+	g.calls++
+	obj, ok := doc.(map[string]interface{})
+	if !ok || g.SeedProperty == "" {
+		return
+	}
+
+	// The stamped document has to be as valid as the generated one
+	before := len(validateInstance(plan, obj, ""))
+	previous, replaced := obj[g.SeedProperty]
+	obj[g.SeedProperty] = map[string]interface{}{
+		"seed":          g.Seed,
+		"structureSeed": g.StructureSeed,
+		"call":          g.calls,
+		"version":       Version(),
+	}
+	if len(validateInstance(plan, obj, "")) > before {
+		if replaced {
+			obj[g.SeedProperty] = previous
+		} else {
+			delete(obj, g.SeedProperty)
+		}
+		g.warnf("seed property %s left out, the schema does not allow it", g.SeedProperty)
+	}
+}
+
+// GenerateResult generates random JSON data and wraps it in a Result
+func (g *Generator) GenerateResult(schemaJSON []byte) (*Result, error) {
+	start := time.Now()
+	value, err := g.Generate(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		value:    value,
+		warnings: g.warnings,
+		meta: Meta{
+			Seed:          g.Seed,
+			StructureSeed: g.StructureSeed,
+			Duration:      time.Since(start),
+			Retries:       g.retries,
+			Version:       Version(),
+		},
+		shuffleKeys: g.ShuffleKeys,
+	}
+	if g.ShuffleKeys {
+		result.keySeed = g.shape.Int63()
+	}
+	return result, nil
+}
+
+// Value returns the raw generated value
+func (r *Result) Value() interface{} {
+	return r.value
+}
+
+// AsString returns the generated value as a string
+func (r *Result) AsString() (string, error) {
+	s, ok := r.value.(string)
+	if !ok {
+		return "", fmt.Errorf("expected string, got %T", r.value)
+	}
+	return s, nil
+}
+
+// AsInt returns the generated value as an int64. Floats without a
+// fractional part (e.g. from const or enum) are accepted.
+func (r *Result) AsInt() (int64, error) {
+	switch v := r.value.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("expected integer, got %v", v)
+		}
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("expected integer, got %T", r.value)
+	}
+}
+
+// AsObject returns the generated value as an object
+func (r *Result) AsObject() (map[string]interface{}, error) {
+	obj, ok := r.value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected object, got %T", r.value)
+	}
+	return obj, nil
+}
+
+// AsArray returns the generated value as an array
+func (r *Result) AsArray() ([]interface{}, error) {
+	arr, ok := r.value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected array, got %T", r.value)
+	}
+	return arr, nil
+}
+
+// Bytes returns the generated value as JSON, optionally indented
+func (r *Result) Bytes(pretty bool) ([]byte, error) {
+	if r.shuffleKeys {
+		return marshalShuffled(r.value, r.keySeed, pretty)
+	}
+	if pretty {
+		return json.MarshalIndent(r.value, "", "  ")
+	}
+	return json.Marshal(r.value)
+}
+
+// Warnings returns non-fatal issues encountered during generation, such as
+// unsupported formats that fell back to generic values
+func (r *Result) Warnings() []string {
+	return r.warnings
+}
+
+// Meta returns metadata about the generation call
+func (r *Result) Meta() Meta {
+	return r.meta
+}
@@ -0,0 +1,208 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateResultAccessors(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		res, err := NewGenerator().SetSeed(42).GenerateResult([]byte(`{"type": "string", "minLength": 3}`))
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		str, err := res.AsString()
+		if err != nil {
+			t.Fatalf("AsString() error = %v", err)
+		}
+		if len(str) < 3 {
+			t.Errorf("String length %d is less than minLength 3", len(str))
+		}
+		if _, err := res.AsInt(); err == nil {
+			t.Error("Expected AsInt() to fail for a string result")
+		}
+	})
+
+	t.Run("integer", func(t *testing.T) {
+		res, err := NewGenerator().SetSeed(42).GenerateResult([]byte(`{"type": "integer", "minimum": 5, "maximum": 5}`))
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		n, err := res.AsInt()
+		if err != nil {
+			t.Fatalf("AsInt() error = %v", err)
+		}
+		if n != 5 {
+			t.Errorf("Expected 5, got %d", n)
+		}
+	})
+
+	t.Run("integral const", func(t *testing.T) {
+		res, err := NewGenerator().GenerateResult([]byte(`{"const": 7}`))
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		n, err := res.AsInt()
+		if err != nil {
+			t.Fatalf("AsInt() error = %v", err)
+		}
+		if n != 7 {
+			t.Errorf("Expected 7, got %d", n)
+		}
+	})
+
+	t.Run("object", func(t *testing.T) {
+		schema := `{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`
+		res, err := NewGenerator().SetSeed(42).GenerateResult([]byte(schema))
+		if err != nil {
+			t.Fatalf("GenerateResult() error = %v", err)
+		}
+		obj, err := res.AsObject()
+		if err != nil {
+			t.Fatalf("AsObject() error = %v", err)
+		}
+		if _, exists := obj["id"]; !exists {
+			t.Error("Required field 'id' is missing")
+		}
+		if _, err := res.AsArray(); err == nil {
+			t.Error("Expected AsArray() to fail for an object result")
+		}
+	})
+}
+
+func TestResultBytes(t *testing.T) {
+	schema := `{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`
+	res, err := NewGenerator().SetSeed(42).GenerateResult([]byte(schema))
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+
+	compact, err := res.Bytes(false)
+	if err != nil {
+		t.Fatalf("Bytes(false) error = %v", err)
+	}
+	pretty, err := res.Bytes(true)
+	if err != nil {
+		t.Fatalf("Bytes(true) error = %v", err)
+	}
+
+	if strings.Contains(string(compact), "\n") {
+		t.Errorf("Expected compact JSON, got %s", compact)
+	}
+	if !strings.Contains(string(pretty), "\n  ") {
+		t.Errorf("Expected indented JSON, got %s", pretty)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(pretty, &obj); err != nil {
+		t.Fatalf("Failed to unmarshal generated JSON: %v", err)
+	}
+}
+
+func TestResultWarningsAndMeta(t *testing.T) {
+	gen := NewGenerator().SetSeed(42)
+	res, err := gen.GenerateResult([]byte(`{"type": "string", "format": "unknown-format"}`))
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+
+	if len(res.Warnings()) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", res.Warnings())
+	}
+	if !strings.Contains(res.Warnings()[0], "unknown-format") {
+		t.Errorf("Expected warning to mention the format, got %q", res.Warnings()[0])
+	}
+	if res.Meta().Seed != 42 {
+		t.Errorf("Expected seed 42, got %d", res.Meta().Seed)
+	}
+	if res.Meta().Version != Version() {
+		t.Errorf("Expected version %q, got %q", Version(), res.Meta().Version)
+	}
+
+	// Warnings are reset between calls
+	res, err = gen.GenerateResult([]byte(`{"type": "string"}`))
+	if err != nil {
+		t.Fatalf("GenerateResult() error = %v", err)
+	}
+	if len(res.Warnings()) != 0 {
+		t.Errorf("Expected no warnings, got %v", res.Warnings())
+	}
+}
+
+// Test the seed property lets a document be regenerated from its own content
+func TestSeedProperty(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}, "required": ["id", "name"]}`)
+
+	doc, err := NewGenerator().SetSeed(7).SetStructureSeed(9).SetSeedProperty("_generated").GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	stamp, ok := doc["_generated"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected _generated object, got %v", doc)
+	}
+	if stamp["seed"] != int64(7) || stamp["structureSeed"] != int64(9) || stamp["call"] != 1 || stamp["version"] != Version() {
+		t.Errorf("Unexpected stamp %v", stamp)
+	}
+
+	again, err := NewGenerator().SetSeed(stamp["seed"].(int64)).SetStructureSeed(stamp["structureSeed"].(int64)).Generate(schema)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	delete(doc, "_generated")
+	if !jsonEqual(doc, again) {
+		t.Errorf("Expected %v to be regenerated, got %v", doc, again)
+	}
+
+	// A document of a later call is regenerated by repeating the calls
+	gen := NewGenerator().SetSeed(7).SetSeedProperty("_generated")
+	var later map[string]interface{}
+	for i := 0; i < 3; i++ {
+		if later, err = gen.GenerateMap(schema); err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+	}
+	stamp = later["_generated"].(map[string]interface{})
+	if stamp["call"] != 3 {
+		t.Fatalf("Expected call 3, got %v", stamp["call"])
+	}
+	replay := NewGenerator().SetSeed(stamp["seed"].(int64)).SetStructureSeed(stamp["structureSeed"].(int64))
+	for i := 0; i < stamp["call"].(int); i++ {
+		if again, err = replay.Generate(schema); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	delete(later, "_generated")
+	if !jsonEqual(later, again) {
+		t.Errorf("Expected %v to be regenerated, got %v", later, again)
+	}
+
+	// Objects the stamp would make invalid are left as they are
+	closed := []string{
+		`{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"], "additionalProperties": false}`,
+		`{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"], "additionalProperties": {"type": "integer"}}`,
+		`{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"], "maxProperties": 1}`,
+		`{"allOf": [{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"], "additionalProperties": false}]}`,
+	}
+	for _, schema := range closed {
+		for _, validate := range []bool{false, true} {
+			res, err := NewGenerator().SetSeed(7).SetSeedProperty("_generated").SetValidateOutput(validate).GenerateResult([]byte(schema))
+			if err != nil {
+				t.Fatalf("GenerateResult() error = %v", err)
+			}
+			if obj, _ := res.AsObject(); len(obj) != 1 || len(res.Warnings()) != 1 {
+				t.Errorf("%s: expected an unstamped document and a warning, got %v and %v", schema, obj, res.Warnings())
+			}
+		}
+	}
+
+	// Only object documents are stamped
+	value, err := NewGenerator().SetSeed(7).SetSeedProperty("_generated").Generate([]byte(`{"type": "string"}`))
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, ok := value.(string); !ok {
+		t.Errorf("Expected string, got %v", value)
+	}
+}
@@ -0,0 +1,149 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// DefaultMaxAttempts is the default attempt budget for constraints that are
+// satisfied by generating a value and checking it, such as not, uniqueItems
+// and patterns combined with length limits
+const DefaultMaxAttempts = 100
+
+// RetryStats counts the values thrown away during one generation call
+// because they did not satisfy a constraint. A high count points at a schema
+// that is expensive or close to unsatisfiable.
+type RetryStats struct {
+	Retries   int            `json:"retries"`
+	ByKeyword map[string]int `json:"byKeyword,omitempty"`
+	ByPath    map[string]int `json:"byPath,omitempty"` // keyed by JSON Pointer
+}
+
+// record counts one retry of a keyword at a path
+func (s *RetryStats) record(keyword, path string) {
+	if s.ByKeyword == nil {
+		s.ByKeyword = make(map[string]int)
+		s.ByPath = make(map[string]int)
+	}
+	s.Retries++
+	s.ByKeyword[keyword]++
+	s.ByPath[path]++
+}
+
+// SetMaxAttempts sets how many values are generated for a constrained value
+// before giving up with an error
+func (g *Generator) SetMaxAttempts(attempts int) *Generator {
+	g.MaxAttempts = attempts
+	return g
+}
+
+// retry is the generate-check loop behind constraints that cannot be met by
+// construction. It calls gen until accept returns true, recording every
+// rejected value, and fails once the attempt budget is spent.
+func (g *Generator) retry(keyword, path string, gen func() (interface{}, error), accept func(interface{}) bool) (interface{}, error) {
+	attempts := g.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		value, err := gen()
+		if err != nil {
+			return nil, err
+		}
+		if accept(value) {
+			return value, nil
+		}
+		if attempt >= attempts {
+			return nil, keywordErrorf(keyword, "could not satisfy %s after %d attempts", keyword, attempts)
+		}
+		g.retries.record(keyword, path)
+	}
+}
+
+// SetValidateOutput controls whether every generated document is checked
+// against the schema with the built-in validator. Documents that do not
+// conform are generated again within the attempt budget, then reported as
+// an error naming the first violation. It costs a validation pass per
+// document and guards contract tests against generator bugs.
+func (g *Generator) SetValidateOutput(validate bool) *Generator {
+	g.ValidateOutput = validate
+	return g
+}
+
+// generateDocument generates a whole document for a plan, checking it
+// against the schema when SetValidateOutput is on
+func (g *Generator) generateDocument(st *genState, plan *node) (interface{}, error) {
+	if !g.ValidateOutput {
+		return g.generate(st, plan, "", 0)
+	}
+
+	var violations []ValidationError
+	value, err := g.retry("output", "", func() (interface{}, error) {
+		violations = nil
+		return g.generate(st, plan, "", 0)
+	}, func(v interface{}) bool {
+		violations = validateInstance(plan, v, "")
+		return len(violations) == 0
+	})
+	if err != nil && len(violations) > 0 {
+		return nil, fmt.Errorf("generated document does not conform to the schema after %d attempts: %w", max(g.MaxAttempts, 1), violations[0])
+	}
+	return value, err
+}
+
+// satisfyNot regenerates a value until it does not match the not subschema
+func (g *Generator) satisfyNot(n *node, path string, gen func() (interface{}, error)) (interface{}, error) {
+	if n.not == nil {
+		return gen()
+	}
+	return g.retry("not", path, gen, func(v interface{}) bool {
+		return len(validateInstance(n.not, v, path)) > 0
+	})
+}
+
+// satisfyLength regenerates a pattern string until its length is within
+// minLength and maxLength, which reggen does not take into account
+func (g *Generator) satisfyLength(n *node, path string, gen func() (interface{}, error)) (interface{}, error) {
+	schema := n.schema
+	if schema.MinLength == nil && schema.MaxLength == nil {
+		return gen()
+	}
+	return g.retry("pattern+length", path, gen, func(v interface{}) bool {
+		length := utf8.RuneCountInString(v.(string))
+		return length >= n.minLength && length <= n.maxLength
+	})
+}
+
+// satisfyObject regenerates an object until it validates against its own
+// schema, for cross-field constraints such as dependentSchemas whose merged
+// subschemas can contradict the properties generated first
+func (g *Generator) satisfyObject(n *node, path string, gen func() (interface{}, error)) (interface{}, error) {
+	if !n.crossField() {
+		return gen()
+	}
+	return g.retry("dependencies", path, gen, func(v interface{}) bool {
+		return len(validateInstance(n, v, path)) == 0
+	})
+}
+
+// crossField reports whether an object has constraints spanning properties
+// that generation does not guarantee by construction
+func (n *node) crossField() bool {
+	for _, dep := range n.dependencies {
+		if dep.schema != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueKey returns a key that is equal for equal JSON values
+func uniqueKey(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
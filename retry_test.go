@@ -0,0 +1,175 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Test constraints satisfied by the generate-check loop
+func TestRetryConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		keyword string
+	}{
+		{
+			name:    "not",
+			schema:  `{"type": "integer", "minimum": 0, "maximum": 10, "not": {"enum": [3, 4, 5, 6, 7]}}`,
+			keyword: "not",
+		},
+		{
+			name:    "uniqueItems",
+			schema:  `{"type": "array", "minItems": 8, "maxItems": 8, "uniqueItems": true, "items": {"type": "integer", "minimum": 0, "maximum": 9}}`,
+			keyword: "uniqueItems",
+		},
+		{
+			name:    "pattern with length",
+			schema:  `{"type": "string", "pattern": "^(ab)+$", "minLength": 3, "maxLength": 6}`,
+			keyword: "pattern+length",
+		},
+		{
+			name: "dependentSchemas",
+			schema: `{
+				"type": "object",
+				"properties": {
+					"a": {"type": "string"},
+					"b": {"type": "integer", "minimum": 0, "maximum": 10}
+				},
+				"required": ["a", "b"],
+				"dependentSchemas": {"a": {"properties": {"b": {"maximum": 3}}}}
+			}`,
+			keyword: "dependencies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseSchema([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("ParseSchema() error = %v", err)
+			}
+			plan, err := compile(schema)
+			if err != nil {
+				t.Fatalf("compile() error = %v", err)
+			}
+
+			gen := NewGenerator().SetSeed(42)
+			retried := false
+			for i := 0; i < 20; i++ {
+				res, err := gen.GenerateResult([]byte(tt.schema))
+				if err != nil {
+					t.Fatalf("GenerateResult() error = %v", err)
+				}
+				if errs := validateInstance(plan, res.Value(), ""); len(errs) > 0 {
+					t.Fatalf("Generated invalid value %v: %v", res.Value(), errs)
+				}
+
+				stats := res.Meta().Retries
+				if stats.ByKeyword[tt.keyword] > 0 {
+					retried = true
+				}
+				if stats.Retries != sumCounts(stats.ByPath) {
+					t.Errorf("Expected per-path counts to add up to %d, got %v", stats.Retries, stats.ByPath)
+				}
+			}
+			if !retried {
+				t.Errorf("Expected some retries for %s", tt.keyword)
+			}
+		})
+	}
+}
+
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// Test the attempt budget is honored
+func TestRetryExhausted(t *testing.T) {
+	schema := `{"type": "integer", "minimum": 1, "maximum": 1, "not": {"const": 1}}`
+
+	gen := NewGenerator().SetSeed(42).SetMaxAttempts(3)
+	_, err := gen.Generate([]byte(schema))
+	if err == nil {
+		t.Fatal("Expected error for unsatisfiable not")
+	}
+	if !strings.Contains(err.Error(), "not") || !strings.Contains(err.Error(), "3 attempts") {
+		t.Errorf("Expected error naming keyword and attempts, got %v", err)
+	}
+	if gen.retries.Retries != 2 {
+		t.Errorf("Expected 2 recorded retries, got %d", gen.retries.Retries)
+	}
+}
+
+// Test uniqueItems over a small enum caps the array length instead of failing
+func TestUniqueItemsSmallDomain(t *testing.T) {
+	schema := `{"type": "array", "uniqueItems": true, "minItems": 1, "maxItems": 10, "items": {"type": "boolean"}}`
+
+	gen := NewGenerator().SetSeed(42)
+	for i := 0; i < 20; i++ {
+		result, err := gen.GenerateSlice([]byte(schema))
+		if err != nil {
+			t.Fatalf("GenerateSlice() error = %v", err)
+		}
+		if len(result) > 2 {
+			t.Fatalf("Expected at most 2 unique booleans, got %v", result)
+		}
+	}
+}
+
+// Test pattern strings respect length limits
+func TestPatternWithLength(t *testing.T) {
+	gen := NewGenerator().SetSeed(7)
+	for i := 0; i < 20; i++ {
+		result, err := gen.Generate([]byte(`{"type": "string", "pattern": "^[0-9]+$", "maxLength": 4}`))
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if n := utf8.RuneCountInString(result.(string)); n < 1 || n > 4 {
+			t.Fatalf("Expected 1-4 digits, got %q", result)
+		}
+	}
+}
+
+// Test output validation regenerating documents that break the schema
+func TestValidateOutput(t *testing.T) {
+	// The generator picks a oneOf branch without checking the others, so
+	// values from 5 to 10 match both branches
+	schema := []byte(`{"oneOf": [{"type": "integer", "minimum": 0, "maximum": 10}, {"type": "integer", "minimum": 5, "maximum": 10}]}`)
+
+	overlapping := 0
+	gen := NewGenerator().SetSeed(42)
+	for i := 0; i < 30; i++ {
+		result, err := gen.Generate(schema)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if result.(int64) >= 5 {
+			overlapping++
+		}
+	}
+	if overlapping == 0 {
+		t.Fatal("Expected some documents matching both branches without output validation")
+	}
+
+	gen = NewGenerator().SetSeed(42).SetValidateOutput(true)
+	for i := 0; i < 30; i++ {
+		result, err := gen.Generate(schema)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if v := result.(int64); v >= 5 {
+			t.Errorf("Expected only values matching one branch, got %d", v)
+		}
+	}
+
+	same := []byte(`{"oneOf": [{"type": "integer", "minimum": 0, "maximum": 3}, {"type": "integer", "minimum": 0, "maximum": 3}]}`)
+	_, err := NewGenerator().SetSeed(42).SetValidateOutput(true).SetMaxAttempts(5).Generate(same)
+	if err == nil || !strings.Contains(err.Error(), "does not conform to the schema after 5 attempts") {
+		t.Errorf("Expected a conformance error, got %v", err)
+	}
+}
@@ -3,38 +3,56 @@ package schemagen
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"slices"
 )
 
 // ValidationError represents a schema validation error with context
 type ValidationError struct {
-	Path    string `json:"path"`
-	Message string `json:"message"`
+	Path    string      `json:"path"`
+	Message string      `json:"message"`
 	Value   interface{} `json:"value,omitempty"`
+
+	// Position of the offending schema in the source when known, or of the
+	// offending value for ValidateInstance
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+
+	pointer string // JSON Pointer of the offending schema in the source
 }
 
 func (ve ValidationError) Error() string {
+	msg := ve.Message
 	if ve.Path != "" {
-		return fmt.Sprintf("validation error at %s: %s", ve.Path, ve.Message)
+		msg = fmt.Sprintf("validation error at %s: %s", ve.Path, ve.Message)
+	}
+	if ve.Line > 0 {
+		msg += fmt.Sprintf(" (line %d, column %d)", ve.Line, ve.Column)
 	}
-	return ve.Message
+	return msg
 }
 
 // Schema represents a JSON Schema with support for Draft 2020-12 and Draft-07
 type Schema struct {
 	// Meta
-	Type  StringOrArray `json:"type,omitempty"`
-	Title string        `json:"title,omitempty"`
+	Type        StringOrArray `json:"type,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"`
+	Example     interface{}   `json:"example,omitempty"` // OpenAPI 3.0
+	Deprecated  bool          `json:"deprecated,omitempty"`
 
 	// Generic
 	Enum  []interface{} `json:"enum,omitempty"`
 	Const interface{}   `json:"const,omitempty"`
 
 	// String
-	MinLength *int   `json:"minLength,omitempty"`
-	MaxLength *int   `json:"maxLength,omitempty"`
-	Pattern   string `json:"pattern,omitempty"`
-	Format    string `json:"format,omitempty"`
+	MinLength        *int   `json:"minLength,omitempty"`
+	MaxLength        *int   `json:"maxLength,omitempty"`
+	Pattern          string `json:"pattern,omitempty"`
+	Format           string `json:"format,omitempty"`
+	ContentMediaType string `json:"contentMediaType,omitempty"` // media type of string content, e.g. "image/png"
 
 	// Number
 	Minimum          *float64 `json:"minimum,omitempty"`
@@ -44,24 +62,59 @@ type Schema struct {
 	MultipleOf       *float64 `json:"multipleOf,omitempty"`
 
 	// Object
-	Properties           map[string]*Schema `json:"properties,omitempty"`
-	Required             []string           `json:"required,omitempty"`
-	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // bool or Schema
+	Properties           map[string]*Schema     `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"` // bool or Schema
+	DependentRequired    map[string][]string    `json:"dependentRequired,omitempty"`
+	DependentSchemas     map[string]*Schema     `json:"dependentSchemas,omitempty"`
+	Dependencies         map[string]interface{} `json:"dependencies,omitempty"` // Draft-07: property list or Schema
+	MinProperties        *int                   `json:"minProperties,omitempty"`
+	MaxProperties        *int                   `json:"maxProperties,omitempty"`
+	PropertyNames        *Schema                `json:"propertyNames,omitempty"` // schema for the names of properties
 
 	// Array
-	Items    interface{} `json:"items,omitempty"` // Schema or array of Schemas
-	MinItems *int        `json:"minItems,omitempty"`
-	MaxItems *int        `json:"maxItems,omitempty"`
+	Items       interface{} `json:"items,omitempty"` // Schema or array of Schemas
+	MinItems    *int        `json:"minItems,omitempty"`
+	MaxItems    *int        `json:"maxItems,omitempty"`
+	UniqueItems bool        `json:"uniqueItems,omitempty"`
+
+	AdditionalItems interface{}   `json:"additionalItems,omitempty"` // Draft-07: bool or Schema for items beyond a tuple
+	PrefixItems     []interface{} `json:"prefixItems,omitempty"`     // Draft 2020-12 tuple; items then holds the rest
 
 	// Composition
 	OneOf []Schema `json:"oneOf,omitempty"`
 	AnyOf []Schema `json:"anyOf,omitempty"`
 	AllOf []Schema `json:"allOf,omitempty"`
+	Not   *Schema  `json:"not,omitempty"`
 
-	// References (for future support)
-	Ref         string             `json:"$ref,omitempty"`
+	// References
+	ID          string             `json:"$id,omitempty"`  // base URI for references in the document
+	Ref         string             `json:"$ref,omitempty"` // e.g. "#/$defs/address" or "common.json#/$defs/address"
 	Definitions map[string]*Schema `json:"definitions,omitempty"`
 	Defs        map[string]*Schema `json:"$defs,omitempty"` // Draft 2020-12
+
+	// Extensions
+	Locale   string `json:"x-locale,omitempty"`   // locale for strings in this subtree, e.g. "ja_JP"
+	Sequence bool   `json:"x-sequence,omitempty"` // integers counting up across the documents of a call
+	Unique   bool   `json:"x-unique,omitempty"`   // values never repeat within a call
+	Volatile bool   `json:"x-volatile,omitempty"` // values differ between runs by design, e.g. ids and timestamps
+	PII      bool   `json:"x-pii,omitempty"`      // personal data that Anonymize replaces
+	Corpus   string `json:"x-corpus,omitempty"`   // text corpus free-form strings are generated from, see SetTextCorpus
+
+	Rectangular bool   `json:"x-rectangular,omitempty"` // nested arrays of each dimension have equal lengths
+	Pool        string `json:"x-pool,omitempty"`        // record generated values in the named pool
+	PoolRef     string `json:"x-pool-ref,omitempty"`    // reuse a value recorded in the named pool
+
+	NullableRate float64 `json:"x-nullable-rate,omitempty"` // share of values replaced by null
+	MissingRate  float64 `json:"x-missing-rate,omitempty"`  // share of objects omitting this property
+	IncludeRate  float64 `json:"x-include-rate,omitempty"`  // share of objects including this optional property
+
+	EnumVarNames   []string `json:"x-enum-varnames,omitempty"`    // display label of each enum value
+	EnumLabelField string   `json:"x-enum-label-field,omitempty"` // sibling property receiving the label
+
+	source   []byte                     // the encoded schema, kept by ParseSchema for $ref
+	deferred map[string]json.RawMessage // root members left encoded by ParseSchemaReader
+	ints     *integerBounds             // exact integer bounds beyond 2^53
 }
 
 // StringOrArray handles the polymorphic nature of the "type" field
@@ -128,15 +181,52 @@ func (s *StringOrArray) IsEmpty() bool {
 	return s.Single == ""
 }
 
-// ParseSchema parses a JSON Schema from bytes
+// ParseSchema parses a JSON Schema from bytes. Syntax and type errors report
+// the line and column in the source.
 func ParseSchema(schemaJSON []byte) (*Schema, error) {
 	var schema Schema
-	if err := json.Unmarshal(schemaJSON, &schema); err != nil {
+	if err := unmarshalSchema(schemaJSON, &schema); err != nil {
+		if offset, ok := errorOffset(schemaJSON, err); ok {
+			line, column := sourcePosition(schemaJSON, offset)
+			return nil, fmt.Errorf("failed to parse schema at line %d, column %d: %w", line, column, err)
+		}
 		return nil, fmt.Errorf("failed to parse schema: %w", err)
 	}
+	// Keep the source for resolving $ref pointers; targets are decoded from
+	// it when a reference is compiled
+	schema.source = schemaJSON
 	return &schema, nil
 }
 
+// ValidateSource validates a schema parsed from schemaJSON like
+// ValidateWithDetails, and sets the line and column of every error
+func (s *Schema) ValidateSource(schemaJSON []byte) []ValidationError {
+	errors := s.ValidateWithDetails("")
+	if len(schemaJSON) == 0 {
+		return errors
+	}
+	for i := range errors {
+		if offset, ok := sourceOffset(schemaJSON, errors[i].pointer); ok {
+			errors[i].Line, errors[i].Column = sourcePosition(schemaJSON, offset)
+		}
+	}
+	return errors
+}
+
+// typeMismatch describes a value that none of the declared types allow,
+// suggesting the unquoted value when a string holds one of them
+func typeMismatch(v interface{}, types []string) string {
+	data, _ := json.Marshal(v)
+	msg := fmt.Sprintf("%s is of type %s, but the schema allows %v", data, jsonTypeOf(v), types)
+	if text, ok := v.(string); ok {
+		var unquoted interface{}
+		if json.Unmarshal([]byte(text), &unquoted) == nil && matchesAnyType(unquoted, types) {
+			msg += fmt.Sprintf(" (did you mean %s without quotes?)", text)
+		}
+	}
+	return msg
+}
+
 // Validate performs comprehensive validation on the schema constraints
 func (s *Schema) Validate() error {
 	errors := s.ValidateWithDetails("")
@@ -149,6 +239,11 @@ func (s *Schema) Validate() error {
 
 // ValidateWithDetails performs comprehensive validation and returns all errors with path context
 func (s *Schema) ValidateWithDetails(basePath string) []ValidationError {
+	return s.validate(basePath, "")
+}
+
+// validate validates a schema found at pointer in the source document
+func (s *Schema) validate(basePath, pointer string) []ValidationError {
 	var errors []ValidationError
 
 	// Check for impossible number constraints
@@ -190,6 +285,134 @@ func (s *Schema) ValidateWithDetails(basePath string) []ValidationError {
 		}
 	}
 
+	for name, dep := range s.Dependencies {
+		switch dep := dep.(type) {
+		case map[string]interface{}, bool:
+		case []interface{}:
+			for _, item := range dep {
+				if _, ok := item.(string); !ok {
+					errors = append(errors, ValidationError{
+						Path:    basePath,
+						Message: fmt.Sprintf("dependencies of %s must list property names", name),
+						Value:   dep,
+					})
+					break
+				}
+			}
+		default:
+			errors = append(errors, ValidationError{
+				Path:    basePath,
+				Message: fmt.Sprintf("dependencies of %s must be an array of property names or a schema", name),
+				Value:   dep,
+			})
+		}
+	}
+
+	if s.Sequence && !s.Type.IsEmpty() && !s.Type.Contains("integer") {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: "x-sequence requires type integer",
+		})
+	}
+
+	if s.NullableRate < 0 || s.NullableRate > 1 {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: "x-nullable-rate must be between 0 and 1",
+			Value:   s.NullableRate,
+		})
+	}
+
+	if s.MissingRate < 0 || s.MissingRate > 1 {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: "x-missing-rate must be between 0 and 1",
+			Value:   s.MissingRate,
+		})
+	}
+
+	if s.IncludeRate < 0 || s.IncludeRate > 1 {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: "x-include-rate must be between 0 and 1",
+			Value:   s.IncludeRate,
+		})
+	}
+
+	// Check for impossible property counts
+	if s.MinProperties != nil && s.MaxProperties != nil && *s.MinProperties > *s.MaxProperties {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: fmt.Sprintf("minProperties (%d) cannot be greater than maxProperties (%d)", *s.MinProperties, *s.MaxProperties),
+		})
+	}
+	if s.MaxProperties != nil && len(s.Required) > *s.MaxProperties {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: fmt.Sprintf("maxProperties (%d) is less than the %d required properties", *s.MaxProperties, len(s.Required)),
+		})
+	}
+
+	// A closed tuple cannot reach minItems
+	tuple, rest, restKeyword := s.Items, s.AdditionalItems, "additionalItems"
+	if s.PrefixItems != nil {
+		tuple, rest, restKeyword = s.PrefixItems, s.Items, "items"
+	}
+	if tuple, ok := tuple.([]interface{}); ok && rest == false && s.MinItems != nil && *s.MinItems > len(tuple) {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: fmt.Sprintf("minItems (%d) cannot be reached by %d tuple items with %s false", *s.MinItems, len(tuple), restKeyword),
+		})
+	}
+
+	// const and enum values must be of a declared type
+	if types := s.Type.GetTypes(); len(types) > 0 {
+		if s.Const != nil && !matchesAnyType(s.Const, types) {
+			errors = append(errors, ValidationError{
+				Path:    basePath,
+				Message: "const " + typeMismatch(s.Const, types),
+				Value:   s.Const,
+			})
+		}
+		// Values of other types are left out when compiling, as long as
+		// one is left
+		if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(v interface{}) bool { return matchesAnyType(v, types) }) {
+			errors = append(errors, ValidationError{
+				Path:    basePath,
+				Message: "no enum value is of a declared type, enum value 0 " + typeMismatch(s.Enum[0], types),
+				Value:   s.Enum,
+			})
+		}
+	}
+
+	if len(s.EnumVarNames) > 0 && len(s.EnumVarNames) != len(s.Enum) {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: fmt.Sprintf("x-enum-varnames has %d labels for %d enum values", len(s.EnumVarNames), len(s.Enum)),
+		})
+	}
+
+	if s.EnumLabelField != "" && len(s.EnumVarNames) == 0 {
+		errors = append(errors, ValidationError{
+			Path:    basePath,
+			Message: "x-enum-label-field requires x-enum-varnames",
+		})
+	}
+
+	if s.Locale != "" {
+		if _, err := resolveLocale(s.Locale); err != nil {
+			errors = append(errors, ValidationError{
+				Path:    basePath,
+				Message: err.Error(),
+				Value:   s.Locale,
+			})
+		}
+	}
+
+	for i := range errors {
+		errors[i].pointer = pointer
+	}
+
 	// Validate nested schemas
 	for propName, propSchema := range s.Properties {
 		propPath := basePath
@@ -198,24 +421,86 @@ func (s *Schema) ValidateWithDetails(basePath string) []ValidationError {
 		} else {
 			propPath = propPath + "." + propName
 		}
-		errors = append(errors, propSchema.ValidateWithDetails(propPath)...)
+		errors = append(errors, propSchema.validate(propPath, childPath(childPath(pointer, "properties"), propName))...)
 	}
 
 	// Validate composition schemas
 	for i, schema := range s.OneOf {
 		schemaPath := fmt.Sprintf("%s.oneOf[%d]", basePath, i)
-		errors = append(errors, schema.ValidateWithDetails(schemaPath)...)
+		errors = append(errors, schema.validate(schemaPath, fmt.Sprintf("%s/oneOf/%d", pointer, i))...)
 	}
 
 	for i, schema := range s.AnyOf {
 		schemaPath := fmt.Sprintf("%s.anyOf[%d]", basePath, i)
-		errors = append(errors, schema.ValidateWithDetails(schemaPath)...)
+		errors = append(errors, schema.validate(schemaPath, fmt.Sprintf("%s/anyOf/%d", pointer, i))...)
 	}
 
 	for i, schema := range s.AllOf {
 		schemaPath := fmt.Sprintf("%s.allOf[%d]", basePath, i)
-		errors = append(errors, schema.ValidateWithDetails(schemaPath)...)
+		errors = append(errors, schema.validate(schemaPath, fmt.Sprintf("%s/allOf/%d", pointer, i))...)
+	}
+
+	if s.Not != nil {
+		errors = append(errors, s.Not.validate(basePath+".not", pointer+"/not")...)
+	}
+
+	// Validate the remaining subschemas, including $ref targets in $defs
+	errors = append(errors, validateSubschema(s.Items, basePath+".items", pointer+"/items")...)
+	errors = append(errors, validateSubschema(s.PrefixItems, basePath+".prefixItems", pointer+"/prefixItems")...)
+	errors = append(errors, validateSubschema(s.AdditionalItems, basePath+".additionalItems", pointer+"/additionalItems")...)
+	errors = append(errors, validateSubschema(s.AdditionalProperties, basePath+".additionalProperties", pointer+"/additionalProperties")...)
+	if s.PropertyNames != nil {
+		errors = append(errors, s.PropertyNames.validate(basePath+".propertyNames", pointer+"/propertyNames")...)
+	}
+	for _, name := range sortedKeys(s.Dependencies) {
+		errors = append(errors, validateSubschema(s.Dependencies[name], basePath+".dependencies."+name, childPath(pointer+"/dependencies", name))...)
+	}
+	for _, keyword := range []struct {
+		name    string
+		schemas map[string]*Schema
+	}{
+		{"dependentSchemas", s.DependentSchemas},
+		{"definitions", s.Definitions},
+		{"$defs", s.Defs},
+	} {
+		for _, name := range slices.Sorted(maps.Keys(keyword.schemas)) {
+			errors = append(errors, keyword.schemas[name].validate(basePath+"."+keyword.name+"."+name, childPath(pointer+"/"+keyword.name, name))...)
+		}
+	}
+	// Definitions left encoded by ParseSchemaReader are checked like the rest
+	for _, keyword := range slices.Sorted(maps.Keys(s.deferred)) {
+		var defs map[string]json.RawMessage
+		if json.Unmarshal(s.deferred[keyword], &defs) != nil {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(defs)) {
+			var schema Schema
+			if unmarshalSchema(defs[name], &schema) != nil {
+				continue
+			}
+			errors = append(errors, schema.validate(basePath+"."+keyword+"."+name, childPath(pointer+"/"+keyword, name))...)
+		}
 	}
 
 	return errors
 }
+
+// validateSubschema validates a subschema kept in its decoded form, as a
+// schema object or a list of them; booleans and other values are skipped
+func validateSubschema(v interface{}, basePath, pointer string) []ValidationError {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		schema, err := parseSubschema(v)
+		if err != nil {
+			return nil
+		}
+		return schema.validate(basePath, pointer)
+	case []interface{}:
+		var errors []ValidationError
+		for i, item := range v {
+			errors = append(errors, validateSubschema(item, fmt.Sprintf("%s[%d]", basePath, i), fmt.Sprintf("%s/%d", pointer, i))...)
+		}
+		return errors
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package schemagen
+
+import (
+	"maps"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// defaultSmartNames maps property names, normalized to lower-case letters
+// and digits, to the kind of value they hold
+var defaultSmartNames = map[string]string{
+	"firstname": "first-name", "givenname": "first-name", "lastname": "last-name", "surname": "last-name",
+	"familyname": "last-name", "name": "full-name", "fullname": "full-name", "displayname": "full-name",
+	"username": "username", "login": "username", "email": "email", "phone": "phone", "phonenumber": "phone",
+	"mobile": "phone", "telephone": "phone", "city": "city", "country": "country", "countrycode": "country-code",
+	"state": "state", "street": "street", "address": "street", "zip": "postcode", "zipcode": "postcode",
+	"postcode": "postcode", "postalcode": "postcode", "company": "company", "companyname": "company",
+	"jobtitle": "job-title", "currency": "currency", "color": "color", "colour": "color", "url": "uri",
+	"website": "uri", "homepage": "uri", "product": "product", "productname": "product",
+
+	"price": "price", "amount": "price", "cost": "price", "total": "price", "subtotal": "price",
+	"balance": "price", "age": "age", "quantity": "quantity", "qty": "quantity", "rating": "rating",
+	"percent": "percent", "percentage": "percent", "year": "year",
+	"latitude": "latitude", "lat": "latitude", "longitude": "longitude", "lng": "longitude", "lon": "longitude",
+}
+
+// smartStrings generate the string kinds of smart names; other kinds are
+// formats
+var smartStrings = map[string]func(f *gofakeit.Faker) string{
+	"first-name":   (*gofakeit.Faker).FirstName,
+	"last-name":    (*gofakeit.Faker).LastName,
+	"full-name":    (*gofakeit.Faker).Name,
+	"username":     (*gofakeit.Faker).Username,
+	"phone":        (*gofakeit.Faker).PhoneFormatted,
+	"city":         (*gofakeit.Faker).City,
+	"country":      (*gofakeit.Faker).Country,
+	"country-code": (*gofakeit.Faker).CountryAbr,
+	"state":        (*gofakeit.Faker).State,
+	"street":       (*gofakeit.Faker).Street,
+	"postcode":     (*gofakeit.Faker).Zip,
+	"company":      (*gofakeit.Faker).Company,
+	"job-title":    (*gofakeit.Faker).JobTitle,
+	"currency":     (*gofakeit.Faker).CurrencyShort,
+	"color":        (*gofakeit.Faker).SafeColor,
+	"product":      (*gofakeit.Faker).ProductName,
+}
+
+// smartNumbers are the ranges and decimal places of the number kinds of
+// smart names
+var smartNumbers = map[string]struct {
+	lo, hi   float64
+	decimals int
+}{
+	"price":     {0.99, 999.99, 2},
+	"age":       {18, 90, 0},
+	"quantity":  {1, 20, 0},
+	"rating":    {1, 5, 0},
+	"percent":   {0, 100, 2},
+	"year":      {1950, 2030, 0},
+	"latitude":  {-90, 90, 6},
+	"longitude": {-180, 180, 6},
+}
+
+// SetSmartNames controls whether strings and numbers without a format or
+// pattern get realistic values inferred from their property name: first
+// names for "firstName", phone numbers for "phone", cities for
+// "billing_city", prices with two decimals for "price", and so on. Names
+// match whole, or as the last words of camelCase or snake_case names.
+// Values that break the schema's bounds are replaced by random ones as
+// usual. See SetSmartName to change the mapping.
+func (g *Generator) SetSmartNames(smart bool) *Generator {
+	g.SmartNames = smart
+	return g
+}
+
+// SetSmartName maps a property name to the kind of value it holds, adding
+// to or replacing the default mapping; an empty kind removes the name.
+// Names are matched ignoring case and separators. Kinds are the string
+// kinds first-name, last-name, full-name, username, phone, city, country,
+// country-code, state, street, postcode, company, job-title, currency,
+// color and product; the number kinds price, age, quantity, rating,
+// percent, year, latitude and longitude; or a format, including those of
+// format providers.
+func (g *Generator) SetSmartName(name, kind string) *Generator {
+	if g.smartNames == nil {
+		g.smartNames = maps.Clone(defaultSmartNames)
+	}
+	name = normalizeName(name)
+	if kind == "" {
+		delete(g.smartNames, name)
+	} else {
+		g.smartNames[name] = kind
+	}
+	return g
+}
+
+// normalizeName lower-cases a name and drops everything but letters and
+// digits
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// smartKind returns the kind of value of the property at path, matching its
+// whole name first, then ever shorter runs of its last words
+func (g *Generator) smartKind(path string) (string, bool) {
+	tokens := pointerTokens(path)
+	if !g.SmartNames || len(tokens) == 0 {
+		return "", false
+	}
+	names := g.smartNames
+	if names == nil {
+		names = defaultSmartNames
+	}
+	words := nameWords(tokens[len(tokens)-1])
+	for i := range words {
+		if kind, ok := names[strings.Join(words[i:], "")]; ok {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// nameWords splits a camelCase, snake_case or kebab-case name into lower
+// case words
+func nameWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words, word = append(words, string(word)), nil
+			}
+			continue
+		}
+		// A capital starts a word unless it continues an acronym
+		if unicode.IsUpper(r) && len(word) > 0 && (!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words, word = append(words, string(word)), nil
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// smartString generates a string for the property at path from its name,
+// when the name is known and the value fits the node's length bounds.
+// Names, addresses and phone numbers come from the locale when it has them.
+func (g *Generator) smartString(locale *Locale, n *node, path string) (string, bool) {
+	kind, ok := g.smartKind(path)
+	if !ok || n.schema.Corpus != "" {
+		return "", false
+	}
+	var s string
+	var err error
+	if _, provided := g.FormatProviders[kind]; provided {
+		s, err = g.generateStringFromFormat(kind, path)
+	} else if v, ok := g.localized(locale, kind); ok {
+		s = v
+	} else if fake := smartStrings[kind]; fake != nil {
+		s = fake(g.faker)
+	} else if _, known := formatLengths[kind]; known {
+		s, err = g.generateStringFromFormat(kind, path)
+	} else {
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+	if len(validateString(n.schema, s, path)) > 0 {
+		return "", false
+	}
+	return s, true
+}
+
+// smartNumber generates a number for the property at path from its name,
+// within the range of its kind and the schema's bounds
+func (g *Generator) smartNumber(schema *Schema, path string, isInteger bool) (interface{}, bool) {
+	kind, ok := g.smartKind(path)
+	hint, known := smartNumbers[kind]
+	if !ok || !known {
+		return nil, false
+	}
+	lo, hi := hint.lo, hint.hi
+	if schema.Minimum != nil {
+		lo = max(lo, *schema.Minimum)
+	}
+	if schema.Maximum != nil {
+		hi = min(hi, *schema.Maximum)
+	}
+	if lo > hi {
+		return nil, false
+	}
+
+	scale := math.Pow(10, float64(hint.decimals))
+	v := math.Round((lo+g.rand.Float64()*(hi-lo))*scale) / scale
+	var value interface{} = v
+	if isInteger {
+		v = math.Round(v)
+		value = int64(v)
+	}
+	if len(validateNumber(schema, v, value, path)) > 0 {
+		return nil, false
+	}
+	return value, true
+}
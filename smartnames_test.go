@@ -0,0 +1,91 @@
+package schemagen
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Test names are split into words
+func TestNameWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"firstName", []string{"first", "name"}},
+		{"billing_city", []string{"billing", "city"}},
+		{"shipping-post-code", []string{"shipping", "post", "code"}},
+		{"userURL", []string{"user", "url"}},
+		{"HTTPStatus", []string{"http", "status"}},
+		{"capacity", []string{"capacity"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nameWords(tt.name); !slices.Equal(got, tt.want) {
+				t.Errorf("nameWords(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test values inferred from property names
+func TestSetSmartNames(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"firstName": {"type": "string"},
+			"customer_email": {"type": "string"},
+			"billingCity": {"type": "string"},
+			"capacity": {"type": "string", "minLength": 30, "maxLength": 30},
+			"price": {"type": "number", "minimum": 10, "maximum": 20},
+			"age": {"type": "integer"},
+			"zip": {"type": "string", "maxLength": 2},
+			"sku": {"type": "string"}
+		},
+		"required": ["firstName", "customer_email", "billingCity", "capacity", "price", "age", "zip", "sku"]
+	}`)
+
+	plain, err := NewGenerator().SetSeed(42).GenerateMap(schema)
+	if err != nil {
+		t.Fatalf("GenerateMap() error = %v", err)
+	}
+	if strings.Contains(plain["customer_email"].(string), "@") {
+		t.Errorf("expected names to be ignored by default, got %v", plain["customer_email"])
+	}
+
+	gen := NewGenerator().SetSeed(42).SetSmartNames(true).
+		SetSmartName("sku", "sku").
+		SetFormatProvider("sku", FormatProviderFunc(func(FormatRequest) (string, error) { return "SKU-1", nil }))
+	for i := 0; i < 20; i++ {
+		result, err := gen.GenerateMap(schema)
+		if err != nil {
+			t.Fatalf("GenerateMap() error = %v", err)
+		}
+		if !regexp.MustCompile(`^[A-Z][a-z]+`).MatchString(result["firstName"].(string)) {
+			t.Errorf("expected a first name, got %v", result["firstName"])
+		}
+		if !strings.Contains(result["customer_email"].(string), "@") {
+			t.Errorf("expected an email, got %v", result["customer_email"])
+		}
+		if len(result["capacity"].(string)) != 30 {
+			t.Errorf("expected capacity not to match city, got %v", result["capacity"])
+		}
+		price := result["price"].(float64)
+		rounded, _ := strconv.ParseFloat(strconv.FormatFloat(price, 'f', 2, 64), 64)
+		if price < 10 || price > 20 || rounded != price {
+			t.Errorf("expected a price in [10, 20] with two decimals, got %v", price)
+		}
+		if age := result["age"].(int64); age < 18 || age > 90 {
+			t.Errorf("expected an adult age, got %v", age)
+		}
+		if zip := result["zip"].(string); len(zip) > 2 {
+			t.Errorf("expected maxLength to win over the name, got %v", zip)
+		}
+		if result["sku"] != "SKU-1" {
+			t.Errorf("expected the mapped provider format, got %v", result["sku"])
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// errorOffset returns the byte offset of a JSON decoding error in data
+func errorOffset(data []byte, err error) (int64, bool) {
+	// Syntax errors are reported after the offending byte
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return max(syntaxErr.Offset-1, 0), true
+	}
+	// Type errors are reported after the offending value
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return scalarStart(data, typeErr.Offset), true
+	}
+	return 0, false
+}
+
+// scalarStart moves back from the end of a scalar value to its start
+func scalarStart(data []byte, end int64) int64 {
+	if end <= 0 || end > int64(len(data)) {
+		return end
+	}
+	start := end - 1
+	if data[start] == '"' {
+		start--
+		for start > 0 && (data[start] != '"' || data[start-1] == '\\') {
+			start--
+		}
+		return start
+	}
+	for start > 0 && strings.IndexByte(" \t\r\n:,[", data[start-1]) < 0 {
+		start--
+	}
+	return start
+}
+
+// sourcePosition converts a byte offset into a 1-based line and column,
+// counting columns in characters
+func sourcePosition(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return bytes.Count(before, []byte{'\n'}) + 1, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+// pointerTokens splits a JSON Pointer into unescaped reference tokens
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// sourceOffset returns the byte offset where the value at a JSON Pointer
+// starts in a JSON document
+func sourceOffset(data []byte, pointer string) (int64, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for _, token := range pointerTokens(pointer) {
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, false
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			found := false
+			for dec.More() && !found {
+				key, err := dec.Token()
+				if err != nil {
+					return 0, false
+				}
+				if found = key == token; !found && skipValue(dec) != nil {
+					return 0, false
+				}
+			}
+			if !found {
+				return 0, false
+			}
+		case json.Delim('['):
+			index, err := strconv.Atoi(token)
+			if err != nil {
+				return 0, false
+			}
+			for i := 0; i < index; i++ {
+				if !dec.More() || skipValue(dec) != nil {
+					return 0, false
+				}
+			}
+			if !dec.More() {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+	}
+
+	// The decoder stops after the previous token; skip to the value itself
+	return valueStart(data, dec.InputOffset()), true
+}
+
+// valueStart skips the whitespace and separators before the value that
+// follows offset
+func valueStart(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n:,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// rawValue returns the encoding of the value at a JSON Pointer in a JSON
+// document without decoding the rest of it
+func rawValue(data []byte, pointer string) (json.RawMessage, bool) {
+	offset, ok := sourceOffset(data, pointer)
+	if !ok {
+		return nil, false
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data[offset:])).Decode(&raw); err != nil {
+		return nil, false
+	}
+	return raw, true
+}
+
+// skipValue reads one complete value from a decoder
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
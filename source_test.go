@@ -0,0 +1,86 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSchemaErrorPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{"syntax error", "{\n  \"type\": \"object\",\n  \"properties\": {,}\n}", "line 3, column 18"},
+		{"type error", "{\n  \"type\": \"string\",\n  \"minLength\": \"3\"\n}", "line 3, column 16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchema([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error at %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateSource(t *testing.T) {
+	source := []byte(`{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string"},
+    "a/b": {
+      "oneOf": [
+        {"type": "integer"},
+        {"type": "integer", "minimum": 5, "maximum": 1}
+      ]
+    }
+  }
+}`)
+
+	schema, err := ParseSchema(source)
+	if err != nil {
+		t.Fatalf("ParseSchema() error = %v", err)
+	}
+	errs := schema.ValidateSource(source)
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+	if errs[0].Line != 8 || errs[0].Column != 9 {
+		t.Errorf("Expected line 8, column 9, got line %d, column %d", errs[0].Line, errs[0].Column)
+	}
+	if !strings.Contains(errs[0].Error(), "(line 8, column 9)") {
+		t.Errorf("Expected position in message, got %q", errs[0].Error())
+	}
+
+	if _, err := NewGenerator().Generate(source); err == nil || !strings.Contains(err.Error(), "line 8") {
+		t.Errorf("Expected generation error with position, got %v", err)
+	}
+}
+
+func TestSourceOffset(t *testing.T) {
+	data := []byte(`{"a": [1, {"b": "x"}], "c~d": 2}`)
+
+	tests := []struct {
+		pointer string
+		want    string
+		ok      bool
+	}{
+		{"", `{"a"`, true},
+		{"/a/1/b", `"x"`, true},
+		{"/c~0d", `2`, true},
+		{"/a/2", "", false},
+		{"/missing", "", false},
+	}
+	for _, tt := range tests {
+		offset, ok := sourceOffset(data, tt.pointer)
+		if ok != tt.ok {
+			t.Errorf("%q: expected found %v, got %v", tt.pointer, tt.ok, ok)
+			continue
+		}
+		if ok && !strings.HasPrefix(string(data[offset:]), tt.want) {
+			t.Errorf("%q: expected value %s, found %s", tt.pointer, tt.want, data[offset:])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package schemagen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// ParseSchemaReader parses a JSON Schema from a stream, one member of the
+// root object at a time, without building a decoded copy of the source.
+//
+// The definitions and $defs of the root schema stay encoded and are only
+// compiled when a $ref points into them, so machine-generated schemas with
+// thousands of definitions cost little more than the definitions they use.
+// Every definition is still checked, when parsing and when the schema is
+// validated, so a schema is accepted here exactly when ParseSchema accepts
+// it. Definitions and Defs of the returned schema are therefore nil.
+func ParseSchemaReader(r io.Reader) (*Schema, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	root := []byte{'{'}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, streamError(dec, err)
+		}
+		key := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, streamError(dec, err)
+		}
+		if key == "definitions" || key == "$defs" {
+			if err := checkDeferred(raw); err != nil {
+				return nil, fmt.Errorf("failed to parse schema member %q: %w", key, err)
+			}
+			if schema.deferred == nil {
+				schema.deferred = make(map[string]json.RawMessage)
+			}
+			schema.deferred[key] = raw
+			continue
+		}
+
+		// Decoding a one-member object reports errors by member; the
+		// members are decoded together below, as exact integer bounds are
+		// set for the whole object at once
+		name, _ := json.Marshal(key)
+		member := append(append(append([]byte{'{'}, name...), ':'), raw...)
+		if err := unmarshalSchema(append(member, '}'), &Schema{}); err != nil {
+			return nil, fmt.Errorf("failed to parse schema member %q: %w", key, err)
+		}
+		if len(root) > 1 {
+			root = append(root, ',')
+		}
+		root = append(root, member[1:]...)
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	if err := unmarshalSchema(append(root, '}'), &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// checkDeferred reports whether every definition in raw decodes as a schema
+func checkDeferred(raw json.RawMessage) error {
+	var defs map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &defs); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(defs)) {
+		if err := unmarshalSchema(defs[name], &Schema{}); err != nil {
+			return fmt.Errorf("definition %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// GenerateReader generates random JSON data from a schema parsed with
+// ParseSchemaReader. Errors in the schema are reported by path rather than
+// line and column, as the source is not kept.
+func (g *Generator) GenerateReader(r io.Reader) (interface{}, error) {
+	schema, err := ParseSchemaReader(r)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := g.prepareSchema(schema, "")
+	if err != nil {
+		return nil, err
+	}
+	return g.generate(newGenState(context.Background()), plan, "", 0)
+}
+
+// expectDelim reads a delimiter token from a decoder
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return streamError(dec, err)
+	}
+	if tok != delim {
+		return fmt.Errorf("failed to parse schema at offset %d: expected %v, got %v", dec.InputOffset(), delim, tok)
+	}
+	return nil
+}
+
+// streamError wraps an error from a decoder with the offset it stopped at
+func streamError(dec *json.Decoder, err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if syntax, ok := err.(*json.SyntaxError); ok {
+		return fmt.Errorf("failed to parse schema at offset %d: %w", syntax.Offset, err)
+	}
+	return fmt.Errorf("failed to parse schema at offset %d: %w", dec.InputOffset(), err)
+}
@@ -0,0 +1,121 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test definitions are parsed only when a $ref points into them
+func TestParseSchemaReader(t *testing.T) {
+	source := `{
+		"type": "object",
+		"properties": {
+			"home": {"$ref": "#/$defs/address"},
+			"city": {"$ref": "#/$defs/address/properties/city"}
+		},
+		"required": ["home", "city"],
+		"$defs": {
+			"address": {"type": "object", "properties": {"city": {"const": "Oslo"}}, "required": ["city"]},
+			"unused": {"type": "integer"}
+		}
+	}`
+
+	schema, err := ParseSchemaReader(strings.NewReader(source))
+	if err != nil {
+		t.Fatalf("ParseSchemaReader() error = %v", err)
+	}
+	if schema.Defs != nil {
+		t.Error("Expected $defs to stay encoded")
+	}
+	if len(schema.Properties) != 2 || len(schema.Required) != 2 {
+		t.Errorf("Unexpected schema %+v", schema)
+	}
+
+	result, err := NewGenerator().SetSeed(42).GenerateReader(strings.NewReader(source))
+	if err != nil {
+		t.Fatalf("GenerateReader() error = %v", err)
+	}
+	obj := result.(map[string]interface{})
+	home, _ := obj["home"].(map[string]interface{})
+	if home["city"] != "Oslo" || obj["city"] != "Oslo" {
+		t.Errorf("Unexpected result %v", result)
+	}
+}
+
+func TestParseSchemaReaderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"syntax", `{"type": "string",}`, "at offset"},
+		{"not an object", `["string"]`, "expected {"},
+		{"truncated", `{"type": "string"`, "unexpected end"},
+		{"wrong type", `{"minLength": "1"}`, `member "minLength"`},
+		{"missing ref", `{"$ref": "#/$defs/missing", "$defs": {}}`, "not found"},
+		{"unused definition", `{"type": "string", "$defs": {"unused": {"type": 5}}}`, `definition "unused"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator().GenerateReader(strings.NewReader(tt.source))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// Test a schema parsed from a reader is checked like one parsed from bytes
+func TestParseSchemaReaderAgrees(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		valid  bool
+	}{
+		{"invalid definition", `{"type": "string", "$defs": {"name": {"type": "string", "minLength": 5, "maxLength": 2}}}`, false},
+		{"invalid nested definition", `{"definitions": {"a": {"type": "object", "properties": {"n": {"minimum": 3, "maximum": 1}}}}}`, false},
+		{"valid definition", `{"$ref": "#/$defs/n", "$defs": {"n": {"type": "integer"}}}`, true},
+		{"exact bounds", `{"type": "integer", "minimum": 9007199254740993, "maximum": 9007199254740995}`, true},
+		{"exact bounds split by members", `{"minimum": 9007199254740993, "type": "integer", "maximum": 9007199254740995, "description": "id"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fromBytes, err := ParseSchema([]byte(tt.source))
+			if err != nil {
+				t.Fatalf("ParseSchema() error = %v", err)
+			}
+			fromReader, err := ParseSchemaReader(strings.NewReader(tt.source))
+			if err != nil {
+				t.Fatalf("ParseSchemaReader() error = %v", err)
+			}
+			bytesErrs, readerErrs := fromBytes.ValidateWithDetails(""), fromReader.ValidateWithDetails("")
+			if len(bytesErrs) != len(readerErrs) || (len(bytesErrs) == 0) != tt.valid {
+				t.Fatalf("Expected the same errors, got %v and %v", bytesErrs, readerErrs)
+			}
+			for i := range bytesErrs {
+				if bytesErrs[i].Path != readerErrs[i].Path || bytesErrs[i].Message != readerErrs[i].Message {
+					t.Errorf("Expected %v, got %v", bytesErrs[i], readerErrs[i])
+				}
+			}
+			if !tt.valid {
+				return
+			}
+
+			for seed := int64(0); seed < 10; seed++ {
+				want, err := NewGenerator().SetSeed(seed).Generate([]byte(tt.source))
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				got, err := NewGenerator().SetSeed(seed).GenerateReader(strings.NewReader(tt.source))
+				if err != nil {
+					t.Fatalf("GenerateReader() error = %v", err)
+				}
+				if !jsonEqual(want, got) {
+					t.Errorf("Seed %d: expected %v, got %v", seed, want, got)
+				}
+			}
+		})
+	}
+}
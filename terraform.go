@@ -0,0 +1,255 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// tfProviderSchemas is the output of `terraform providers schema -json`
+type tfProviderSchemas struct {
+	ProviderSchemas map[string]struct {
+		ResourceSchemas   map[string]tfSchema `json:"resource_schemas"`
+		DataSourceSchemas map[string]tfSchema `json:"data_source_schemas"`
+	} `json:"provider_schemas"`
+}
+
+type tfSchema struct {
+	Block tfBlock `json:"block"`
+}
+
+type tfBlock struct {
+	Attributes map[string]tfAttribute `json:"attributes"`
+	BlockTypes map[string]tfBlockType `json:"block_types"`
+}
+
+type tfAttribute struct {
+	Type       json.RawMessage `json:"type"`
+	NestedType *tfNestedType   `json:"nested_type"`
+	Required   bool            `json:"required"`
+	Optional   bool            `json:"optional"`
+	Computed   bool            `json:"computed"`
+}
+
+type tfNestedType struct {
+	Attributes  map[string]tfAttribute `json:"attributes"`
+	NestingMode string                 `json:"nesting_mode"`
+}
+
+type tfBlockType struct {
+	NestingMode string  `json:"nesting_mode"`
+	Block       tfBlock `json:"block"`
+	MinItems    int     `json:"min_items"`
+	MaxItems    int     `json:"max_items"`
+}
+
+// TerraformSchema converts the schema of one resource or data source type
+// from `terraform providers schema -json` output into a JSON Schema for its
+// configuration. Computed-only attributes are left out, since they cannot be
+// configured.
+func TerraformSchema(providerSchemaJSON []byte, typeName string) ([]byte, error) {
+	block, _, err := findTerraformBlock(providerSchemaJSON, typeName)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := tfBlockSchema(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", typeName, err)
+	}
+	return json.Marshal(schema)
+}
+
+// GenerateTerraformResource generates a configuration block for a resource
+// or data source type in Terraform's JSON syntax, e.g.
+// {"resource": {"aws_instance": {"example": {...}}}}
+func (g *Generator) GenerateTerraformResource(providerSchemaJSON []byte, typeName, name string) ([]byte, error) {
+	schema, err := TerraformSchema(providerSchemaJSON, typeName)
+	if err != nil {
+		return nil, err
+	}
+	_, kind, _ := findTerraformBlock(providerSchemaJSON, typeName)
+
+	config, err := g.GenerateMap(schema)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]interface{}{
+		kind: map[string]interface{}{typeName: map[string]interface{}{name: config}},
+	})
+}
+
+// findTerraformBlock looks up a type among the resources and data sources of
+// all providers, returning its block and "resource" or "data"
+func findTerraformBlock(providerSchemaJSON []byte, typeName string) (tfBlock, string, error) {
+	var schemas tfProviderSchemas
+	if err := json.Unmarshal(providerSchemaJSON, &schemas); err != nil {
+		return tfBlock{}, "", fmt.Errorf("failed to parse provider schemas: %w", err)
+	}
+
+	for _, provider := range schemas.ProviderSchemas {
+		if s, ok := provider.ResourceSchemas[typeName]; ok {
+			return s.Block, "resource", nil
+		}
+	}
+	for _, provider := range schemas.ProviderSchemas {
+		if s, ok := provider.DataSourceSchemas[typeName]; ok {
+			return s.Block, "data", nil
+		}
+	}
+	return tfBlock{}, "", fmt.Errorf("type %s not found in provider schemas", typeName)
+}
+
+// tfBlockSchema converts a block into an object schema
+func tfBlockSchema(block tfBlock) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for name, attr := range block.Attributes {
+		if attr.Computed && !attr.Optional && !attr.Required {
+			continue
+		}
+		var schema map[string]interface{}
+		var err error
+		if attr.NestedType != nil {
+			schema, err = tfNestedSchema(attr.NestedType)
+		} else {
+			schema, err = tfTypeSchema(attr.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %w", name, err)
+		}
+		properties[name] = schema
+		if attr.Required {
+			required = append(required, name)
+		}
+	}
+
+	for name, blockType := range block.BlockTypes {
+		nested, err := tfBlockSchema(blockType.Block)
+		if err != nil {
+			return nil, fmt.Errorf("block %s: %w", name, err)
+		}
+		schema := tfNesting(blockType.NestingMode, nested)
+		if schema["type"] == "array" {
+			if blockType.MinItems > 0 {
+				schema["minItems"] = blockType.MinItems
+			}
+			if blockType.MaxItems > 0 {
+				schema["maxItems"] = blockType.MaxItems
+			}
+		}
+		properties[name] = schema
+		if blockType.MinItems > 0 {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// tfNestedSchema converts the nested type of an attribute
+func tfNestedSchema(nested *tfNestedType) (map[string]interface{}, error) {
+	object, err := tfBlockSchema(tfBlock{Attributes: nested.Attributes})
+	if err != nil {
+		return nil, err
+	}
+	return tfNesting(nested.NestingMode, object), nil
+}
+
+// tfNesting wraps an object schema according to a nesting mode
+func tfNesting(mode string, object map[string]interface{}) map[string]interface{} {
+	switch mode {
+	case "list":
+		return map[string]interface{}{"type": "array", "items": object}
+	case "set":
+		return map[string]interface{}{"type": "array", "items": object, "uniqueItems": true}
+	case "map":
+		return map[string]interface{}{"type": "object", "additionalProperties": object}
+	default: // single, group
+		return object
+	}
+}
+
+// tfTypeSchema converts a type constraint, e.g. "string" or ["list", "number"]
+func tfTypeSchema(raw json.RawMessage) (map[string]interface{}, error) {
+	var primitive string
+	if err := json.Unmarshal(raw, &primitive); err == nil {
+		switch primitive {
+		case "string":
+			return map[string]interface{}{"type": "string"}, nil
+		case "number":
+			return map[string]interface{}{"type": "number"}, nil
+		case "bool":
+			return map[string]interface{}{"type": "boolean"}, nil
+		case "dynamic":
+			return map[string]interface{}{}, nil
+		}
+		return nil, fmt.Errorf("unsupported type %q", primitive)
+	}
+
+	var complex []json.RawMessage
+	if err := json.Unmarshal(raw, &complex); err != nil || len(complex) != 2 {
+		return nil, fmt.Errorf("invalid type %s", raw)
+	}
+	var kind string
+	if err := json.Unmarshal(complex[0], &kind); err != nil {
+		return nil, fmt.Errorf("invalid type %s", raw)
+	}
+
+	switch kind {
+	case "list", "set", "map":
+		element, err := tfTypeSchema(complex[1])
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "list":
+			return map[string]interface{}{"type": "array", "items": element}, nil
+		case "set":
+			return map[string]interface{}{"type": "array", "items": element, "uniqueItems": true}, nil
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": element}, nil
+	case "object":
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(complex[1], &attrs); err != nil {
+			return nil, fmt.Errorf("invalid object type %s", raw)
+		}
+		// Object types have no optional attributes, so all are required
+		properties := make(map[string]interface{})
+		required := make([]string, 0, len(attrs))
+		for name, attrType := range attrs {
+			schema, err := tfTypeSchema(attrType)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			properties[name] = schema
+			required = append(required, name)
+		}
+		sort.Strings(required)
+		return map[string]interface{}{"type": "object", "properties": properties, "required": required, "additionalProperties": false}, nil
+	case "tuple":
+		var elems []json.RawMessage
+		if err := json.Unmarshal(complex[1], &elems); err != nil {
+			return nil, fmt.Errorf("invalid tuple type %s", raw)
+		}
+		items := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			schema, err := tfTypeSchema(elem)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = schema
+		}
+		return map[string]interface{}{"type": "array", "items": items, "minItems": len(items), "maxItems": len(items)}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %q", kind)
+}
@@ -0,0 +1,114 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var terraformProviderSchema = []byte(`{
+	"format_version": "1.0",
+	"provider_schemas": {
+		"registry.terraform.io/hashicorp/example": {
+			"resource_schemas": {
+				"example_server": {
+					"version": 0,
+					"block": {
+						"attributes": {
+							"id": {"type": "string", "computed": true},
+							"name": {"type": "string", "required": true},
+							"size": {"type": "number", "optional": true},
+							"tags": {"type": ["map", "string"], "optional": true},
+							"ports": {"type": ["set", "number"], "required": true},
+							"owner": {"type": ["object", {"email": "string", "admin": "bool"}], "required": true},
+							"disks": {"nested_type": {"nesting_mode": "list", "attributes": {"gb": {"type": "number", "required": true}}}, "required": true}
+						},
+						"block_types": {
+							"network": {
+								"nesting_mode": "list",
+								"min_items": 1,
+								"max_items": 2,
+								"block": {"attributes": {"subnet": {"type": "string", "required": true}}}
+							},
+							"timeouts": {
+								"nesting_mode": "single",
+								"block": {"attributes": {"create": {"type": "string", "optional": true}}}
+							}
+						}
+					}
+				}
+			},
+			"data_source_schemas": {
+				"example_image": {"version": 0, "block": {"attributes": {"family": {"type": "string", "required": true}}}}
+			}
+		}
+	}
+}`)
+
+func TestTerraformSchema(t *testing.T) {
+	data, err := TerraformSchema(terraformProviderSchema, "example_server")
+	if err != nil {
+		t.Fatalf("TerraformSchema() error = %v", err)
+	}
+	schema, err := ParseSchema(data)
+	if err != nil {
+		t.Fatalf("Converted schema does not parse: %v", err)
+	}
+
+	if _, ok := schema.Properties["id"]; ok {
+		t.Error("Expected computed-only attribute to be left out")
+	}
+	want := []string{"disks", "name", "network", "owner", "ports"}
+	if len(schema.Required) != len(want) {
+		t.Fatalf("Expected required %v, got %v", want, schema.Required)
+	}
+	for i, name := range want {
+		if schema.Required[i] != name {
+			t.Errorf("Expected required %v, got %v", want, schema.Required)
+		}
+	}
+	if ports := schema.Properties["ports"]; !ports.Type.Contains("array") || !ports.UniqueItems {
+		t.Errorf("Expected set to become a unique array, got %+v", ports)
+	}
+	if network := schema.Properties["network"]; *network.MinItems != 1 || *network.MaxItems != 2 {
+		t.Errorf("Unexpected block limits %+v", network)
+	}
+
+	if _, err := TerraformSchema(terraformProviderSchema, "example_missing"); err == nil {
+		t.Error("Expected error for unknown type")
+	}
+}
+
+func TestGenerateTerraformResource(t *testing.T) {
+	tests := []struct {
+		typeName string
+		kind     string
+	}{
+		{"example_server", "resource"},
+		{"example_image", "data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			data, err := NewGenerator().SetSeed(42).SetGenerateAllFields(true).GenerateTerraformResource(terraformProviderSchema, tt.typeName, "test")
+			if err != nil {
+				t.Fatalf("GenerateTerraformResource() error = %v", err)
+			}
+
+			var config map[string]map[string]map[string]interface{}
+			if err := json.Unmarshal(data, &config); err != nil {
+				t.Fatalf("Invalid configuration %s: %v", data, err)
+			}
+			block, ok := config[tt.kind][tt.typeName]["test"]
+			if !ok {
+				t.Fatalf("Expected %s.%s.test in %s", tt.kind, tt.typeName, data)
+			}
+
+			schemaJSON, _ := TerraformSchema(terraformProviderSchema, tt.typeName)
+			parsed, _ := ParseSchema(schemaJSON)
+			plan, _ := compile(parsed)
+			if errs := validateInstance(plan, block, ""); len(errs) > 0 {
+				t.Errorf("Generated block is invalid: %v\n%s", errs, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package schemagen
+
+import (
+	"strings"
+)
+
+// TextMode selects what free-form strings may contain, for testing how
+// consumers escape them
+type TextMode int
+
+const (
+	// TextPlain leaves free-form strings as generated. This is the default.
+	TextPlain TextMode = iota
+
+	// TextHTMLSafe replaces the characters HTML gives a meaning to, < > & "
+	// and ', so strings can be embedded in HTML without escaping
+	TextHTMLSafe
+
+	// TextMetacharacters fills strings with HTML, Markdown, SQL and template
+	// metacharacters and injection payloads, such as <script> tags and
+	// ' OR '1'='1, to test escaping and injection handling
+	TextMetacharacters
+
+	// TextTrickyUnicode mixes in code points that trip up Unicode handling:
+	// zero-width joiners and spaces, bidirectional overrides, combining
+	// marks, code points next to the surrogate range and at the ends of the
+	// planes, and strings that change under normalization
+	TextTrickyUnicode
+)
+
+// htmlUnsafe holds the characters TextHTMLSafe replaces
+const htmlUnsafe = `<>&"'`
+
+// injectionPayloads are inserted into strings by TextMetacharacters when
+// they fit
+var injectionPayloads = []string{
+	`<script>alert(1)</script>`,
+	`"><img src=x onerror=alert(1)>`,
+	`' OR '1'='1`,
+	`'; DROP TABLE users;--`,
+	`[click](javascript:alert(1))`,
+	`**bold** _em_ # heading`,
+	`{{7*7}}`,
+	`${7*7}`,
+	`&amp;&lt;&#x27;`,
+	"`rm -rf /`",
+	`\' \" \\`,
+	`<!--`,
+}
+
+// trickyUnicode holds the sequences TextTrickyUnicode mixes in. Lengths
+// count code points, so combining sequences and joined emoji count as
+// several.
+var trickyUnicode = []string{
+	"\u200d", "\u200c", "\u200b", "\u2060", "\ufeff", // zero-width joiner, non-joiner, space, word joiner, BOM
+	"\u202e", "\u202d", "\u2066", "\u2069", "\u200f", // right-to-left override, left-to-right override, isolate, pop isolate, RTL mark
+	"e\u0301", "A\u030a", "Z\u0324\u0354\u0367\u0311", // combining marks
+	"\ud7ff", "\ue000", "\ufffd", "\uffff", "\U00010000", "\U0010ffff", // around the surrogate range and plane ends
+	"\u212b", "\u212a", "\ufb01", "\u00e9", "\u1e9b\u0323", // Angstrom and Kelvin signs, ligature, precomposed letters
+	"\U0001f468\u200d\U0001f469\u200d\U0001f467", "\U0001f1fa\U0001f1f8", // family emoji, flag
+}
+
+// metacharacters fill strings too short for a payload
+const metacharacters = "<>&\"'`*_[]()#;-{}$\\%"
+
+// SetTextMode sets what free-form strings may contain, see TextMode.
+// Strings from formats and patterns keep their shape; lengths still meet
+// minLength and maxLength.
+func (g *Generator) SetTextMode(mode TextMode) *Generator {
+	g.TextMode = mode
+	return g
+}
+
+// applyTextMode changes a free-form string for the text mode, keeping its
+// length in code points
+func (g *Generator) applyTextMode(s string) string {
+	switch g.TextMode {
+	case TextHTMLSafe:
+		return strings.Map(func(r rune) rune {
+			if strings.ContainsRune(htmlUnsafe, r) {
+				return 'x'
+			}
+			return r
+		}, s)
+	case TextMetacharacters:
+		return g.injectMetacharacters(s)
+	case TextTrickyUnicode:
+		return g.mixTrickyUnicode(s)
+	}
+	return s
+}
+
+// mixTrickyUnicode overwrites about a third of a string with tricky
+// sequences that fit
+func (g *Generator) mixTrickyUnicode(s string) string {
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if g.rand.Intn(3) == 0 {
+			seq := []rune(trickyUnicode[g.rand.Intn(len(trickyUnicode))])
+			if i+len(seq) <= len(runes) {
+				copy(runes[i:], seq)
+				i += len(seq)
+				continue
+			}
+		}
+		i++
+	}
+	return string(runes)
+}
+
+// injectMetacharacters overwrites part of a string with an injection
+// payload that fits, or with metacharacters
+func (g *Generator) injectMetacharacters(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+
+	var fitting []string
+	for _, p := range injectionPayloads {
+		if len(p) <= len(runes) {
+			fitting = append(fitting, p)
+		}
+	}
+	if len(fitting) == 0 {
+		for i := range runes {
+			runes[i] = rune(metacharacters[g.rand.Intn(len(metacharacters))])
+		}
+		return string(runes)
+	}
+
+	payload := fitting[g.rand.Intn(len(fitting))]
+	at := g.rand.Intn(len(runes) - len(payload) + 1)
+	copy(runes[at:], []rune(payload))
+	return string(runes)
+}
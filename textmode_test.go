@@ -0,0 +1,93 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Test text modes keep strings within their lengths
+func TestTextMode(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"comment": {"type": "string", "minLength": 30, "maxLength": 60},
+			"tag": {"type": "string", "minLength": 3, "maxLength": 3},
+			"id": {"type": "string", "format": "uuid"}
+		},
+		"required": ["comment", "tag", "id"]
+	}`)
+
+	t.Run("html safe", func(t *testing.T) {
+		gen := NewGenerator().SetSeed(42).SetUnicodeText(true).SetTextMode(TextHTMLSafe).
+			SetProseText(true).SetTextCorpus("", `Tom & Jerry's <b>"best"</b> episode. It's <i>great</i> & funny.`)
+		for i := 0; i < 50; i++ {
+			result, err := gen.GenerateMap(schema)
+			if err != nil {
+				t.Fatalf("GenerateMap() error = %v", err)
+			}
+			for _, key := range []string{"comment", "tag"} {
+				if s := result[key].(string); strings.ContainsAny(s, htmlUnsafe) {
+					t.Errorf("%s: expected no HTML metacharacters, got %q", key, s)
+				}
+			}
+		}
+	})
+
+	t.Run("metacharacters", func(t *testing.T) {
+		gen := NewGenerator().SetSeed(42).SetTextMode(TextMetacharacters)
+		for i := 0; i < 50; i++ {
+			result, err := gen.GenerateMap(schema)
+			if err != nil {
+				t.Fatalf("GenerateMap() error = %v", err)
+			}
+			comment, tag := result["comment"].(string), result["tag"].(string)
+			if n := utf8.RuneCountInString(comment); n < 30 || n > 60 {
+				t.Errorf("comment: length %d outside [30, 60]: %q", n, comment)
+			}
+			if !strings.ContainsAny(comment, metacharacters) || utf8.RuneCountInString(tag) != 3 || strings.Trim(tag, metacharacters) != "" {
+				t.Errorf("expected metacharacters, got %q and %q", comment, tag)
+			}
+			if id := result["id"].(string); !uuidPattern.MatchString(id) {
+				t.Errorf("id: expected a plain uuid, got %q", id)
+			}
+		}
+	})
+	t.Run("tricky unicode", func(t *testing.T) {
+		tricky := make(map[rune]bool)
+		for _, seq := range trickyUnicode {
+			for _, r := range seq {
+				tricky[r] = true
+			}
+		}
+		gen := NewGenerator().SetSeed(42).SetTextMode(TextTrickyUnicode)
+		found := false
+		for i := 0; i < 50; i++ {
+			data, err := gen.GenerateBytes(schema)
+			if err != nil {
+				t.Fatalf("GenerateBytes() error = %v", err)
+			}
+			var result map[string]interface{}
+			if err := json.Unmarshal(data, &result); err != nil {
+				t.Fatalf("generated invalid JSON: %v", err)
+			}
+			comment := result["comment"].(string)
+			if n := utf8.RuneCountInString(comment); n < 30 || n > 60 {
+				t.Errorf("comment: length %d outside [30, 60]: %q", n, comment)
+			}
+			if n := utf8.RuneCountInString(result["tag"].(string)); n != 3 {
+				t.Errorf("tag: expected 3 code points, got %d", n)
+			}
+			for _, r := range comment {
+				found = found || tricky[r]
+			}
+		}
+		if !found {
+			t.Error("expected tricky code points in comments")
+		}
+	})
+}
@@ -0,0 +1,430 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// patternCache holds compiled regular expressions used by the instance validator
+var patternCache sync.Map // map[string]*regexp.Regexp
+
+// compilePattern compiles a pattern once and caches it
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
+
+// ValidateInstance validates a JSON document against a schema and returns
+// every violation, with JSON Pointer paths into the document and the line
+// and column of the offending value. Annotation keywords such as format are
+// not asserted, and $ref to other documents is not followed. The error is
+// set when the schema or the document cannot be parsed.
+func ValidateInstance(schemaJSON, instanceJSON []byte) ([]ValidationError, error) {
+	schema, err := ParseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := compile(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance interface{}
+	if err := decodeDocument(instanceJSON, &instance); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	errors := validateInstance(plan, instance, "")
+	for i := range errors {
+		if offset, ok := sourceOffset(instanceJSON, errors[i].Path); ok {
+			errors[i].Line, errors[i].Column = sourcePosition(instanceJSON, offset)
+		}
+	}
+	return errors, nil
+}
+
+// validateInstance checks a decoded JSON value against a compiled schema and
+// returns every violation found, with JSON Pointer paths into the instance.
+// Annotation keywords such as format are not asserted.
+func validateInstance(n *node, instance interface{}, path string) []ValidationError {
+	var errors []ValidationError
+	schema := n.schema
+
+	fail := func(format string, args ...interface{}) {
+		errors = append(errors, ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf(format, args...),
+			Value:   instance,
+		})
+	}
+
+	if len(n.types) > 0 && !matchesAnyType(instance, n.types) {
+		fail("expected type %v, got %s", n.types, jsonTypeOf(instance))
+		return errors
+	}
+
+	if schema.Const != nil && !jsonEqual(schema.Const, instance) {
+		fail("value does not match const %v", schema.Const)
+	}
+
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, candidate := range schema.Enum {
+			if jsonEqual(candidate, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value is not one of the enum values %v", schema.Enum)
+		}
+	}
+
+	switch v := instance.(type) {
+	case string:
+		errors = append(errors, validateString(schema, v, path)...)
+	case map[string]interface{}:
+		errors = append(errors, validateObject(n, v, path)...)
+	case []interface{}:
+		errors = append(errors, validateArray(n, v, path)...)
+	default:
+		if num, ok := toFloat(instance); ok {
+			errors = append(errors, validateNumber(schema, num, instance, path)...)
+		}
+	}
+
+	// Composition
+	for i, sub := range n.allOf {
+		for _, err := range validateInstance(sub, instance, path) {
+			err.Message = fmt.Sprintf("allOf[%d]: %s", i, err.Message)
+			errors = append(errors, err)
+		}
+	}
+
+	if len(n.anyOf) > 0 {
+		matched := false
+		for _, sub := range n.anyOf {
+			if len(validateInstance(sub, instance, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("value does not match any anyOf subschema")
+		}
+	}
+
+	if len(n.oneOf) > 0 {
+		matches := 0
+		for _, sub := range n.oneOf {
+			if len(validateInstance(sub, instance, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail("value matches %d oneOf subschemas, expected exactly 1", matches)
+		}
+	}
+
+	if n.not != nil && len(validateInstance(n.not, instance, path)) == 0 {
+		fail("value must not match the not subschema")
+	}
+
+	return errors
+}
+
+// validateString checks string keywords. Lengths are counted in code points.
+func validateString(schema *Schema, v string, path string) []ValidationError {
+	var errors []ValidationError
+	length := utf8.RuneCountInString(v)
+
+	if schema.MinLength != nil && length < *schema.MinLength {
+		errors = append(errors, ValidationError{Path: path, Value: v,
+			Message: fmt.Sprintf("length %d is less than minLength %d", length, *schema.MinLength)})
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		errors = append(errors, ValidationError{Path: path, Value: v,
+			Message: fmt.Sprintf("length %d is greater than maxLength %d", length, *schema.MaxLength)})
+	}
+	if schema.Pattern != "" {
+		re, err := compilePattern(schema.Pattern)
+		if err != nil {
+			errors = append(errors, ValidationError{Path: path, Value: v,
+				Message: fmt.Sprintf("invalid regex pattern: %v", err)})
+		} else if !re.MatchString(v) {
+			errors = append(errors, ValidationError{Path: path, Value: v,
+				Message: fmt.Sprintf("value does not match pattern %s", schema.Pattern)})
+		}
+	}
+
+	return errors
+}
+
+// validateNumber checks numeric keywords
+func validateNumber(schema *Schema, v float64, instance interface{}, path string) []ValidationError {
+	var errors []ValidationError
+	fail := func(format string, args ...interface{}) {
+		errors = append(errors, ValidationError{Path: path, Value: instance, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.Minimum != nil && v < *schema.Minimum {
+		fail("%v is less than minimum %v", v, *schema.Minimum)
+	}
+	if schema.Maximum != nil && v > *schema.Maximum {
+		fail("%v is greater than maximum %v", v, *schema.Maximum)
+	}
+	if schema.ExclusiveMinimum != nil && v <= *schema.ExclusiveMinimum {
+		fail("%v is not greater than exclusiveMinimum %v", v, *schema.ExclusiveMinimum)
+	}
+	if schema.ExclusiveMaximum != nil && v >= *schema.ExclusiveMaximum {
+		fail("%v is not less than exclusiveMaximum %v", v, *schema.ExclusiveMaximum)
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 && !isMultipleOf(v, *schema.MultipleOf) {
+		fail("%v is not a multiple of %v", v, *schema.MultipleOf)
+	}
+
+	return errors
+}
+
+// validateObject checks object keywords and validates property values
+func validateObject(n *node, v map[string]interface{}, path string) []ValidationError {
+	var errors []ValidationError
+
+	for _, name := range n.schema.Required {
+		if _, ok := v[name]; !ok {
+			errors = append(errors, ValidationError{Path: path,
+				Message: fmt.Sprintf("missing required property %s", name)})
+		}
+	}
+
+	if len(v) < n.minProperties {
+		errors = append(errors, ValidationError{Path: path,
+			Message: fmt.Sprintf("object has %d properties, less than minProperties %d", len(v), n.minProperties)})
+	}
+	if n.maxProperties >= 0 && len(v) > n.maxProperties {
+		errors = append(errors, ValidationError{Path: path,
+			Message: fmt.Sprintf("object has %d properties, more than maxProperties %d", len(v), n.maxProperties)})
+	}
+	if n.propertyNames != nil {
+		for _, name := range sortedKeys(v) {
+			for _, err := range validateInstance(n.propertyNames, name, childPath(path, name)) {
+				err.Message = "property name: " + err.Message
+				errors = append(errors, err)
+			}
+		}
+	}
+
+	known := make(map[string]bool, len(n.properties))
+	for _, prop := range n.properties {
+		known[prop.name] = true
+		if value, ok := v[prop.name]; ok {
+			errors = append(errors, validateInstance(prop.node, value, childPath(path, prop.name))...)
+		}
+	}
+
+	for _, dep := range n.dependencies {
+		if _, present := v[dep.name]; !present {
+			continue
+		}
+		if dep.forbidden {
+			errors = append(errors, ValidationError{Path: childPath(path, dep.name), Value: v[dep.name],
+				Message: fmt.Sprintf("property %s is not allowed by dependencies", dep.name)})
+		}
+		for _, name := range dep.required {
+			if _, ok := v[name]; !ok {
+				errors = append(errors, ValidationError{Path: path,
+					Message: fmt.Sprintf("missing property %s required by %s", name, dep.name)})
+			}
+		}
+		if dep.schema != nil {
+			errors = append(errors, validateInstance(dep.schema, v, path)...)
+		}
+	}
+
+	for _, name := range sortedKeys(v) {
+		if known[name] {
+			continue
+		}
+		switch {
+		case n.additional != nil:
+			errors = append(errors, validateInstance(n.additional, v[name], childPath(path, name))...)
+		case n.schema.AdditionalProperties == false:
+			errors = append(errors, ValidationError{Path: childPath(path, name), Value: v[name],
+				Message: "additional property is not allowed"})
+		}
+	}
+
+	return errors
+}
+
+// validateArray checks array keywords and validates items
+func validateArray(n *node, v []interface{}, path string) []ValidationError {
+	var errors []ValidationError
+	schema := n.schema
+
+	if schema.MinItems != nil && len(v) < *schema.MinItems {
+		errors = append(errors, ValidationError{Path: path,
+			Message: fmt.Sprintf("array has %d items, less than minItems %d", len(v), *schema.MinItems)})
+	}
+	if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+		errors = append(errors, ValidationError{Path: path,
+			Message: fmt.Sprintf("array has %d items, more than maxItems %d", len(v), *schema.MaxItems)})
+	}
+
+	if schema.UniqueItems {
+		for i := range v {
+			for j := 0; j < i; j++ {
+				if jsonEqual(v[i], v[j]) {
+					errors = append(errors, ValidationError{Path: childPath(path, strconv.Itoa(i)), Value: v[i],
+						Message: fmt.Sprintf("item %d duplicates item %d", i, j)})
+					break
+				}
+			}
+		}
+	}
+
+	if n.closed && len(v) > len(n.tuple) {
+		errors = append(errors, ValidationError{Path: path,
+			Message: fmt.Sprintf("array has %d items, more than the %d tuple items allowed", len(v), len(n.tuple))})
+	}
+
+	for i, item := range v {
+		itemNode := n.items
+		if n.tuple != nil {
+			itemNode = n.rest
+			if i < len(n.tuple) {
+				itemNode = n.tuple[i]
+			}
+		}
+		if itemNode != nil {
+			errors = append(errors, validateInstance(itemNode, item, childPath(path, strconv.Itoa(i)))...)
+		}
+	}
+
+	return errors
+}
+
+// matchesAnyType reports whether a value is of one of the JSON types
+func matchesAnyType(v interface{}, types []string) bool {
+	actual := jsonTypeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON Schema type name of a decoded value. Numbers
+// without a fractional part are reported as "integer".
+func jsonTypeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		if f, ok := toFloat(v); ok {
+			if f == math.Trunc(f) && !math.IsInf(f, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// toFloat converts any numeric value produced by decoding or generation to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// isMultipleOf reports whether v is a multiple of m, tolerating float rounding
+func isMultipleOf(v, m float64) bool {
+	q := v / m
+	if math.IsInf(q, 0) || math.IsNaN(q) {
+		return false
+	}
+	return math.Abs(q-math.Round(q)) < 1e-9*math.Max(1, math.Abs(q))
+}
+
+// jsonEqual compares two decoded JSON values, treating numbers by value
+func jsonEqual(a, b interface{}) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, va := range a {
+			vb, ok := b[k]
+			if !ok || !jsonEqual(va, vb) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// sortedKeys returns the keys of an object in sorted order
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
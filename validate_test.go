@@ -0,0 +1,137 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test validating documents given as JSON
+func TestValidateInstanceJSON(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["id", "name"]
+	}`)
+	doc := []byte(`{
+  "id": 0,
+  "tags": ["a", 2]
+}`)
+
+	errs, err := ValidateInstance(schema, doc)
+	if err != nil {
+		t.Fatalf("ValidateInstance() error = %v", err)
+	}
+	got := make([]string, len(errs))
+	for i, e := range errs {
+		got[i] = e.Error()
+	}
+	want := []string{
+		`missing required property name (line 1, column 1)`,
+		`validation error at /id: 0 is less than minimum 1 (line 2, column 9)`,
+		`validation error at /tags/1: expected type [string], got integer (line 3, column 17)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateInstance() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if errs, err := ValidateInstance(schema, []byte(`{"id": 3, "name": "x"}`)); err != nil || len(errs) != 0 {
+		t.Errorf("Expected a valid document, got %v, %v", errs, err)
+	}
+	if _, err := ValidateInstance(schema, []byte(`{"id": `)); err == nil {
+		t.Error("Expected an error for a malformed document")
+	}
+}
+
+// Test the instance validator against the expectations in the suite fixtures
+func TestValidateInstanceSuite(t *testing.T) {
+	files, err := filepath.Glob("testdata/suite/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var groups []suiteGroup
+		if err := json.Unmarshal(data, &groups); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		for _, group := range groups {
+			if reason, supported := NewGenerator().checkSuiteSchema(group.Schema, 1); !supported {
+				t.Logf("skipping %s: %s", group.Description, reason)
+				continue
+			}
+
+			schema, err := ParseSchema(group.Schema)
+			if err != nil {
+				t.Fatalf("%s: %v", group.Description, err)
+			}
+			plan, err := compile(schema)
+			if err != nil {
+				t.Fatalf("%s: %v", group.Description, err)
+			}
+
+			for _, test := range group.Tests {
+				errs := validateInstance(plan, test.Data, "")
+				if valid := len(errs) == 0; valid != test.Valid {
+					t.Errorf("%s / %s: expected valid=%v, got errors %v", group.Description, test.Description, test.Valid, errs)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateInstancePaths(t *testing.T) {
+	schema, _ := ParseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"a/b": {
+				"type": "array",
+				"items": {"type": "string", "maxLength": 2}
+			}
+		}
+	}`))
+	plan, err := compile(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	instance := map[string]interface{}{"a/b": []interface{}{"ok", "too long"}}
+	errs := validateInstance(plan, instance, "")
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 error, got %v", errs)
+	}
+	if errs[0].Path != "/a~1b/1" {
+		t.Errorf("Expected path /a~1b/1, got %s", errs[0].Path)
+	}
+}
+
+func TestJSONEqual(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{int64(1), 1.0, true},
+		{"a", "a", true},
+		{"1", 1.0, false},
+		{[]interface{}{1.0}, []interface{}{int64(1)}, true},
+		{map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": 2.0}, false},
+		{nil, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := jsonEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("jsonEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
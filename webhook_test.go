@@ -0,0 +1,55 @@
+package schemagen
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestGenerateWebhook(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"event": {"const": "order.paid"}, "amount": {"type": "integer"}}, "required": ["event", "amount"]}`)
+
+	tests := []struct {
+		name       string
+		signer     *WebhookSigner
+		header     string
+		wantPrefix string
+	}{
+		{"unsigned", nil, DefaultSignatureHeader, ""},
+		{"default header", &WebhookSigner{Secret: "s3cret"}, DefaultSignatureHeader, "sha256="},
+		{"custom header", &WebhookSigner{Secret: "s3cret", Header: "X-Hub-Signature", Prefix: "v1="}, "X-Hub-Signature", "v1="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := NewGenerator().SetSeed(42).GenerateWebhook(schema, tt.signer)
+			if err != nil {
+				t.Fatalf("GenerateWebhook() error = %v", err)
+			}
+			var payload map[string]interface{}
+			if err := json.Unmarshal(hook.Body, &payload); err != nil || payload["event"] != "order.paid" {
+				t.Errorf("Unexpected body %s: %v", hook.Body, err)
+			}
+			if got := hook.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Unexpected content type %q", got)
+			}
+
+			signature := hook.Header.Get(tt.header)
+			if tt.signer == nil {
+				if signature != "" {
+					t.Errorf("Expected no signature, got %q", signature)
+				}
+				return
+			}
+
+			// Verify the way a receiver would
+			mac := hmac.New(sha256.New, []byte(tt.signer.Secret))
+			mac.Write(hook.Body)
+			if want := tt.wantPrefix + hex.EncodeToString(mac.Sum(nil)); signature != want {
+				t.Errorf("Expected signature %q, got %q", want, signature)
+			}
+		})
+	}
+}